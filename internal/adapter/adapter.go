@@ -21,6 +21,12 @@ type Adapter interface {
 	Describe(s *skill.Skill, targetPath string, opts InstallOpts) string
 }
 
+// Renderer is implemented by adapters that generate file content. Render
+// returns exactly the text Install would write for the skill.
+type Renderer interface {
+	Render(s *skill.Skill, opts InstallOpts) (string, error)
+}
+
 // ForClient returns the appropriate adapter for the given client ID.
 func ForClient(id client.ClientID) (Adapter, error) {
 	switch id {
@@ -2,10 +2,10 @@ package adapter
 
 import (
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 
+	"github.com/yorch/aisk/internal/fsutil"
 	"github.com/yorch/aisk/internal/skill"
 )
 
@@ -20,23 +20,30 @@ func (a *ClaudeAdapter) Install(s *skill.Skill, targetPath string, opts InstallO
 		return fmt.Errorf("creating target dir: %w", err)
 	}
 
-	// Remove existing installation if present
-	if err := os.RemoveAll(dest); err != nil {
-		return fmt.Errorf("removing existing: %w", err)
+	// Stage the new installation next to dest, then swap it into place so an
+	// interrupted install never leaves a missing or half-copied skill.
+	staged, err := fsutil.TempSibling(dest)
+	if err != nil {
+		return fmt.Errorf("staging install: %w", err)
 	}
 
 	if s.Source == skill.SourceLocal {
 		// Symlink for local skills
-		if err := os.Symlink(s.Path, dest); err != nil {
+		if err := os.Symlink(s.Path, staged); err != nil {
 			return fmt.Errorf("creating symlink: %w", err)
 		}
 	} else {
 		// Copy for remote skills
-		if err := copyDir(s.Path, dest); err != nil {
+		if err := fsutil.CopyTree(s.Path, staged); err != nil {
+			os.RemoveAll(staged)
 			return fmt.Errorf("copying skill: %w", err)
 		}
 	}
 
+	if err := fsutil.Swap(staged, dest); err != nil {
+		os.RemoveAll(staged)
+		return fmt.Errorf("replacing existing: %w", err)
+	}
 	return nil
 }
 
@@ -52,28 +59,3 @@ func (a *ClaudeAdapter) Describe(s *skill.Skill, targetPath string, opts Install
 	}
 	return fmt.Sprintf("copy %s -> %s", s.Path, dest)
 }
-
-// copyDir recursively copies a directory tree.
-func copyDir(src, dst string) error {
-	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		rel, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-		target := filepath.Join(dst, rel)
-
-		if d.IsDir() {
-			return os.MkdirAll(target, 0o755)
-		}
-
-		data, err := os.ReadFile(path)
-		if err != nil {
-			return err
-		}
-		return os.WriteFile(target, data, 0o644)
-	})
-}
@@ -90,3 +90,36 @@ func TestClaudeAdapter_Uninstall(t *testing.T) {
 		t.Error("skill directory should be removed after uninstall")
 	}
 }
+
+func TestClaudeAdapter_Install_ReplacesExisting(t *testing.T) {
+	srcDir := t.TempDir()
+	os.WriteFile(filepath.Join(srcDir, "SKILL.md"), []byte("# Local"), 0o644)
+
+	targetDir := t.TempDir()
+	dest := filepath.Join(targetDir, "test-skill")
+	os.MkdirAll(dest, 0o755)
+	os.WriteFile(filepath.Join(dest, "SKILL.md"), []byte("# Old copy"), 0o644)
+
+	s := &skill.Skill{
+		Frontmatter: skill.Frontmatter{Name: "test-skill"},
+		DirName:     "test-skill",
+		Path:        srcDir,
+		Source:      skill.SourceLocal,
+	}
+	adapter := &ClaudeAdapter{}
+	if err := adapter.Install(s, targetDir, InstallOpts{}); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if target, err := os.Readlink(dest); err != nil || target != srcDir {
+		t.Fatalf("expected symlink to %s, got %q (%v)", srcDir, target, err)
+	}
+
+	// Reinstalling over the symlink must also succeed and leave no staging dirs.
+	if err := adapter.Install(s, targetDir, InstallOpts{}); err != nil {
+		t.Fatalf("reinstall failed: %v", err)
+	}
+	entries, _ := os.ReadDir(targetDir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the installed skill in target dir, got %d entries", len(entries))
+	}
+}
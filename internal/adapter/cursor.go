@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/yorch/aisk/internal/fsutil"
 	"github.com/yorch/aisk/internal/skill"
 )
 
@@ -24,7 +25,7 @@ func (a *CursorAdapter) Install(s *skill.Skill, targetPath string, opts InstallO
 	}
 
 	dest := filepath.Join(targetPath, s.DirName+".mdc")
-	return os.WriteFile(dest, []byte(content), 0o644)
+	return fsutil.WriteFile(dest, []byte(content), 0o644)
 }
 
 func (a *CursorAdapter) Uninstall(s *skill.Skill, targetPath string) error {
@@ -36,6 +37,11 @@ func (a *CursorAdapter) Uninstall(s *skill.Skill, targetPath string) error {
 	return err
 }
 
+// Render returns the .mdc file content.
+func (a *CursorAdapter) Render(s *skill.Skill, opts InstallOpts) (string, error) {
+	return a.buildContent(s, opts.IncludeRefs)
+}
+
 func (a *CursorAdapter) Describe(s *skill.Skill, targetPath string, opts InstallOpts) string {
 	dest := filepath.Join(targetPath, s.DirName+".mdc")
 	return fmt.Sprintf("write %s", dest)
@@ -53,8 +59,12 @@ func (a *CursorAdapter) buildContent(s *skill.Skill, includeRefs bool) (string,
 	// Cursor .mdc frontmatter
 	b.WriteString("---\n")
 	b.WriteString(fmt.Sprintf("description: %s\n", desc))
-	b.WriteString("globs:\n")
-	b.WriteString("alwaysApply: false\n")
+	if len(s.Frontmatter.Globs) > 0 {
+		b.WriteString(fmt.Sprintf("globs: %s\n", strings.Join(s.Frontmatter.Globs, ",")))
+	} else {
+		b.WriteString("globs:\n")
+	}
+	b.WriteString(fmt.Sprintf("alwaysApply: %t\n", s.Frontmatter.AlwaysApply))
 	b.WriteString("---\n\n")
 
 	// Body
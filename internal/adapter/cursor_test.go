@@ -80,3 +80,23 @@ func TestCursorAdapter_Uninstall_NotExists(t *testing.T) {
 		t.Fatalf("Uninstall of non-existent should not fail: %v", err)
 	}
 }
+
+func TestCursorAdapter_RenderGlobsAndAlwaysApply(t *testing.T) {
+	s := &skill.Skill{
+		Frontmatter: skill.Frontmatter{
+			Name:        "ts-style",
+			Description: "TS style",
+			Globs:       []string{"src/**/*.ts", "*.tsx"},
+			AlwaysApply: true,
+		},
+		DirName:      "ts-style",
+		MarkdownBody: "Body",
+	}
+	content, err := (&CursorAdapter{}).Render(s, InstallOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "globs: src/**/*.ts,*.tsx\nalwaysApply: true\n") {
+		t.Errorf("frontmatter not rendered from skill fields:\n%s", content)
+	}
+}
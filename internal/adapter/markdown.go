@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/yorch/aisk/internal/fsutil"
 	"github.com/yorch/aisk/internal/skill"
 )
 
@@ -34,6 +35,15 @@ func (a *MarkdownAdapter) Uninstall(s *skill.Skill, targetPath string) error {
 	return removeSection(targetPath, s.Frontmatter.Name)
 }
 
+// Render returns the managed section, including markers, as it would appear in the target file.
+func (a *MarkdownAdapter) Render(s *skill.Skill, opts InstallOpts) (string, error) {
+	content, err := a.buildContent(s, opts.IncludeRefs)
+	if err != nil {
+		return "", err
+	}
+	return wrapSection(s.Frontmatter.Name, content), nil
+}
+
 func (a *MarkdownAdapter) Describe(s *skill.Skill, targetPath string, opts InstallOpts) string {
 	return fmt.Sprintf("append skill section to %s", targetPath)
 }
@@ -77,17 +87,38 @@ func (a *MarkdownAdapter) buildContent(s *skill.Skill, includeRefs bool) (string
 func sectionStart(name string) string { return fmt.Sprintf("<!-- aisk:start:%s -->", name) }
 func sectionEnd(name string) string   { return fmt.Sprintf("<!-- aisk:end:%s -->", name) }
 
+// SectionMarkers returns the start and end markers that delimit a skill's managed section.
+func SectionMarkers(name string) (string, string) { return sectionStart(name), sectionEnd(name) }
+
+// wrapSection surrounds content with the start and end markers for a skill.
+func wrapSection(name, content string) string {
+	return fmt.Sprintf("%s\n%s\n%s", sectionStart(name), content, sectionEnd(name))
+}
+
+// ExtractSection returns a skill's managed section, including markers, from
+// file content. The boolean is false when the section is not present.
+func ExtractSection(content, skillName string) (string, bool) {
+	startMarker := sectionStart(skillName)
+	endMarker := sectionEnd(skillName)
+	startIdx := strings.Index(content, startMarker)
+	endIdx := strings.Index(content, endMarker)
+	if startIdx < 0 || endIdx < startIdx {
+		return "", false
+	}
+	return content[startIdx : endIdx+len(endMarker)], true
+}
+
 // appendOrReplaceSection adds or replaces a skill section in a markdown file.
 func appendOrReplaceSection(filePath, skillName, content string) error {
 	startMarker := sectionStart(skillName)
 	endMarker := sectionEnd(skillName)
-	wrapped := fmt.Sprintf("%s\n%s\n%s", startMarker, content, endMarker)
+	wrapped := wrapSection(skillName, content)
 
 	existing, err := os.ReadFile(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Create new file
-			return os.WriteFile(filePath, []byte(wrapped+"\n"), 0o644)
+			return fsutil.WriteFile(filePath, []byte(wrapped+"\n"), 0o644)
 		}
 		return err
 	}
@@ -101,7 +132,7 @@ func appendOrReplaceSection(filePath, skillName, content string) error {
 	if startIdx >= 0 && endIdx >= 0 {
 		// Replace existing section
 		newContent := fileContent[:startIdx] + wrapped + fileContent[endIdx+len(endMarker):]
-		return os.WriteFile(filePath, []byte(newContent), 0o644)
+		return fsutil.WriteFile(filePath, []byte(newContent), 0o644)
 	}
 
 	// Append new section
@@ -109,7 +140,7 @@ func appendOrReplaceSection(filePath, skillName, content string) error {
 		fileContent += "\n"
 	}
 	fileContent += "\n" + wrapped + "\n"
-	return os.WriteFile(filePath, []byte(fileContent), 0o644)
+	return fsutil.WriteFile(filePath, []byte(fileContent), 0o644)
 }
 
 // removeSection removes a skill section from a markdown file.
@@ -148,5 +179,5 @@ func removeSection(filePath, skillName string) error {
 		newContent = before + "\n\n" + after + "\n"
 	}
 
-	return os.WriteFile(filePath, []byte(newContent), 0o644)
+	return fsutil.WriteFile(filePath, []byte(newContent), 0o644)
 }
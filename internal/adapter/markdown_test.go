@@ -150,3 +150,31 @@ Content
 		t.Error("surrounding content should be preserved")
 	}
 }
+
+func TestMarkdownAdapter_RenderMatchesInstall(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "GEMINI.md")
+
+	s := &skill.Skill{
+		Frontmatter: skill.Frontmatter{
+			Name:        "test-skill",
+			Description: "A test skill",
+		},
+		DirName:      "test-skill",
+		MarkdownBody: "Body.",
+	}
+
+	adapter := &MarkdownAdapter{ClientName: "Gemini"}
+	rendered, err := adapter.Render(s, InstallOpts{})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if err := adapter.Install(s, target, InstallOpts{}); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(target)
+	if string(data) != rendered+"\n" {
+		t.Errorf("installed content differs from render:\n%q\nvs\n%q", data, rendered)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/yorch/aisk/internal/fsutil"
 	"github.com/yorch/aisk/internal/skill"
 )
 
@@ -14,18 +15,13 @@ import (
 type WindsurfAdapter struct{}
 
 func (a *WindsurfAdapter) Install(s *skill.Skill, targetPath string, opts InstallOpts) error {
-	body := s.MarkdownBody
-	if opts.IncludeRefs {
-		fullContent, err := skill.ReadFullContent(s, true)
-		if err != nil {
-			return err
-		}
-		body = fullContent
+	content, err := a.buildContent(s, opts.IncludeRefs)
+	if err != nil {
+		return err
 	}
 
 	if opts.Scope == "global" {
 		// Append to global rules file using section markers
-		content := fmt.Sprintf("# %s\n\n%s", s.Frontmatter.Name, body)
 		dir := filepath.Dir(targetPath)
 		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return fmt.Errorf("creating dir: %w", err)
@@ -39,8 +35,19 @@ func (a *WindsurfAdapter) Install(s *skill.Skill, targetPath string, opts Instal
 	}
 
 	dest := filepath.Join(targetPath, s.DirName+".md")
-	content := fmt.Sprintf("# %s\n\n%s", s.Frontmatter.Name, body)
-	return os.WriteFile(dest, []byte(content), 0o644)
+	return fsutil.WriteFile(dest, []byte(content), 0o644)
+}
+
+// Render returns the rule file content (project) or managed section (global).
+func (a *WindsurfAdapter) Render(s *skill.Skill, opts InstallOpts) (string, error) {
+	content, err := a.buildContent(s, opts.IncludeRefs)
+	if err != nil {
+		return "", err
+	}
+	if opts.Scope == "global" {
+		return wrapSection(s.Frontmatter.Name, content), nil
+	}
+	return content, nil
 }
 
 func (a *WindsurfAdapter) Uninstall(s *skill.Skill, targetPath string) error {
@@ -65,3 +72,15 @@ func (a *WindsurfAdapter) Describe(s *skill.Skill, targetPath string, opts Insta
 	dest := filepath.Join(targetPath, s.DirName+".md")
 	return fmt.Sprintf("write %s", dest)
 }
+
+func (a *WindsurfAdapter) buildContent(s *skill.Skill, includeRefs bool) (string, error) {
+	body := s.MarkdownBody
+	if includeRefs {
+		fullContent, err := skill.ReadFullContent(s, true)
+		if err != nil {
+			return "", err
+		}
+		body = fullContent
+	}
+	return fmt.Sprintf("# %s\n\n%s", s.Frontmatter.Name, body), nil
+}
@@ -65,3 +65,28 @@ func TestWindsurfAdapter_Install_Global(t *testing.T) {
 		t.Error("should use section markers for global install")
 	}
 }
+
+func TestWindsurfAdapter_Render_Scopes(t *testing.T) {
+	s := &skill.Skill{
+		Frontmatter:  skill.Frontmatter{Name: "test-skill"},
+		DirName:      "test-skill",
+		MarkdownBody: "Body.",
+	}
+
+	adapter := &WindsurfAdapter{}
+	project, err := adapter.Render(s, InstallOpts{Scope: "project"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if project != "# test-skill\n\nBody." {
+		t.Errorf("project render = %q", project)
+	}
+
+	global, err := adapter.Render(s, InstallOpts{Scope: "global"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.HasPrefix(global, "<!-- aisk:start:test-skill -->\n") || !strings.HasSuffix(global, "<!-- aisk:end:test-skill -->") {
+		t.Errorf("global render should be wrapped in markers, got %q", global)
+	}
+}
@@ -0,0 +1,372 @@
+// Package adopt finds hand-written client rules that correspond to skills in
+// the repository so they can be brought under aisk management.
+package adopt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yorch/aisk/internal/adapter"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/fsutil"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+// Match kinds describe how an artifact was tied to a skill.
+const (
+	MatchManaged = "managed" // already wrapped in aisk section markers
+	MatchContent = "content" // body hash equals the skill body hash
+	MatchName    = "name"    // file, directory or heading name equals the skill name
+)
+
+// Location is a client install target to scan.
+type Location struct {
+	ClientID client.ClientID
+	Scope    string
+	Path     string // client target path: a directory or a markdown file
+}
+
+// Found is an artifact matched to a repository skill.
+type Found struct {
+	Location
+	Skill    *skill.Skill
+	Artifact string // concrete file or directory that was matched
+	Target   string // where the client's adapter writes Skill; Apply renames Artifact here
+	Match    string
+
+	// section is set for unmanaged markdown sections that need markers.
+	section *region
+}
+
+// Unmatched is an artifact that could not be tied to any skill.
+type Unmatched struct {
+	Location
+	Artifact string
+	Reason   string
+}
+
+type region struct {
+	start, end int // byte offsets within the file
+}
+
+// NeedsMarkers reports whether applying this match rewrites the target file.
+func (f Found) NeedsMarkers() bool { return f.section != nil }
+
+// NeedsRename reports whether applying this match moves the artifact to the
+// name the client's adapter would give it, so update and uninstall find it.
+func (f Found) NeedsRename() bool { return f.Target != "" && f.Target != f.Artifact }
+
+// Scan inspects a location and matches its artifacts against skills.
+func Scan(loc Location, skills []*skill.Skill) ([]Found, []Unmatched, error) {
+	switch {
+	case loc.ClientID == client.Claude:
+		return scanDir(loc, skills, "", readClaudeDir)
+	case loc.ClientID == client.Cursor:
+		return scanDir(loc, skills, ".mdc", readRuleFile)
+	case loc.ClientID == client.Windsurf && loc.Scope == "project":
+		return scanDir(loc, skills, ".md", readRuleFile)
+	default:
+		return scanSections(loc, skills)
+	}
+}
+
+// Apply renames artifacts to their adapter output name and wraps every
+// unmanaged markdown section in found with aisk markers. Sections in the same
+// file are rewritten from the end so earlier offsets stay valid. Other
+// matches need no file changes.
+func Apply(found []Found) error {
+	for _, f := range found {
+		if !f.NeedsRename() {
+			continue
+		}
+		if _, err := os.Lstat(f.Target); err == nil {
+			return fmt.Errorf("renaming %s: %s already exists", f.Artifact, f.Target)
+		}
+		if err := os.Rename(f.Artifact, f.Target); err != nil {
+			return fmt.Errorf("renaming %s: %w", f.Artifact, err)
+		}
+	}
+
+	byFile := make(map[string][]Found)
+	var files []string
+	for _, f := range found {
+		if f.section == nil {
+			continue
+		}
+		if _, ok := byFile[f.Artifact]; !ok {
+			files = append(files, f.Artifact)
+		}
+		byFile[f.Artifact] = append(byFile[f.Artifact], f)
+	}
+
+	for _, file := range files {
+		matches := byFile[file]
+		sort.Slice(matches, func(i, j int) bool { return matches[i].section.start > matches[j].section.start })
+		if err := wrapSections(file, matches); err != nil {
+			return fmt.Errorf("wrapping sections in %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func wrapSections(path string, matches []Found) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(data)
+
+	for _, f := range matches {
+		if f.section.end > len(content) {
+			return fmt.Errorf("file changed since it was scanned")
+		}
+		body := strings.TrimRight(content[f.section.start:f.section.end], "\n")
+		startMarker, endMarker := adapter.SectionMarkers(f.Skill.Frontmatter.Name)
+		wrapped := startMarker + "\n" + body + "\n" + endMarker + "\n"
+		rest := content[f.section.end:]
+		if rest != "" && !strings.HasPrefix(rest, "\n") {
+			wrapped += "\n"
+		}
+		content = content[:f.section.start] + wrapped + rest
+	}
+
+	return fsutil.WriteFile(path, []byte(content), 0o644)
+}
+
+type artifactReader func(path string) (name, body string, ok bool)
+
+func scanDir(loc Location, skills []*skill.Skill, ext string, read artifactReader) ([]Found, []Unmatched, error) {
+	entries, err := os.ReadDir(loc.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	var found []Found
+	var unmatched []Unmatched
+	claimed := make(map[string]bool)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if ext != "" && filepath.Ext(entry.Name()) != ext {
+			continue
+		}
+		artifact := filepath.Join(loc.Path, entry.Name())
+		stem := strings.TrimSuffix(entry.Name(), ext)
+
+		name, body, ok := read(artifact)
+		if !ok {
+			unmatched = append(unmatched, Unmatched{Location: loc, Artifact: artifact, Reason: "not a readable skill artifact"})
+			continue
+		}
+
+		s, match := matchSkill(skills, []string{stem, name}, body)
+		if s == nil {
+			unmatched = append(unmatched, Unmatched{Location: loc, Artifact: artifact, Reason: "no skill with matching name or content"})
+			continue
+		}
+		// Adopted artifacts must sit where the adapter writes the skill, or
+		// update would write a second copy and uninstall would miss this one.
+		target := filepath.Join(loc.Path, s.DirName+ext)
+		if target != artifact && (claimed[target] || exists(target)) {
+			unmatched = append(unmatched, Unmatched{Location: loc, Artifact: artifact, Reason: fmt.Sprintf("matches %s, but %s is already taken", s.Frontmatter.Name, filepath.Base(target))})
+			continue
+		}
+		claimed[target] = true
+		found = append(found, Found{Location: loc, Skill: s, Artifact: artifact, Target: target, Match: match})
+	}
+	return found, unmatched, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func readClaudeDir(path string) (string, string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return "", "", false
+	}
+	data, err := os.ReadFile(filepath.Join(path, "SKILL.md"))
+	if err != nil {
+		return "", "", false
+	}
+	fm, body, err := skill.ParseFrontmatter(string(data))
+	if err != nil {
+		return "", string(data), true
+	}
+	return fm.Name, body, true
+}
+
+func readRuleFile(path string) (string, string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+	content := string(data)
+	if _, body, err := skill.ParseFrontmatter(content); err == nil {
+		content = body
+	}
+	heading, body := splitHeading(content)
+	return heading, body, true
+}
+
+func scanSections(loc Location, skills []*skill.Skill) ([]Found, []Unmatched, error) {
+	data, err := os.ReadFile(loc.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	content := string(data)
+
+	var found []Found
+	var unmatched []Unmatched
+	for _, sec := range splitSections(content) {
+		if sec.managed != "" {
+			s, _ := matchSkill(skills, []string{sec.managed}, "")
+			if s == nil {
+				unmatched = append(unmatched, Unmatched{Location: loc, Artifact: loc.Path, Reason: fmt.Sprintf("managed section %q has no skill in the repo", sec.managed)})
+				continue
+			}
+			found = append(found, Found{Location: loc, Skill: s, Artifact: loc.Path, Target: loc.Path, Match: MatchManaged})
+			continue
+		}
+
+		heading, body := splitHeading(content[sec.start:sec.end])
+		s, match := matchSkill(skills, []string{heading}, stripDescription(body))
+		if s == nil {
+			unmatched = append(unmatched, Unmatched{Location: loc, Artifact: loc.Path, Reason: fmt.Sprintf("no skill matches section %q", heading)})
+			continue
+		}
+		found = append(found, Found{
+			Location: loc,
+			Skill:    s,
+			Artifact: loc.Path,
+			Target:   loc.Path,
+			Match:    match,
+			section:  &region{start: sec.start, end: sec.end},
+		})
+	}
+	return found, unmatched, nil
+}
+
+type section struct {
+	start, end int
+	managed    string // skill name when the section is already wrapped in markers
+}
+
+// splitSections splits markdown into existing aisk-managed sections and
+// unmanaged sections that start at a level-1 heading. Headings inside fenced
+// code blocks are ignored; text before the first heading is not a section.
+func splitSections(content string) []section {
+	var sections []section
+	var current *section
+	inFence := false
+	managedName := ""
+
+	closeCurrent := func(at int) {
+		if current != nil {
+			current.end = at
+			sections = append(sections, *current)
+			current = nil
+		}
+	}
+
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+
+		if managedName != "" {
+			if _, endMarker := adapter.SectionMarkers(managedName); trimmed == endMarker {
+				managedName = ""
+			}
+			continue
+		}
+		if name, ok := strings.CutPrefix(trimmed, "<!-- aisk:start:"); ok && strings.HasSuffix(name, " -->") {
+			closeCurrent(lineStart)
+			managedName = strings.TrimSuffix(name, " -->")
+			sections = append(sections, section{start: lineStart, managed: managedName})
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "# ") {
+			closeCurrent(lineStart)
+			current = &section{start: lineStart}
+		}
+	}
+	closeCurrent(len(content))
+	return sections
+}
+
+// splitHeading separates a leading "# heading" line from the rest of the text.
+func splitHeading(content string) (string, string) {
+	content = strings.TrimLeft(content, "\n")
+	if !strings.HasPrefix(content, "# ") {
+		return "", content
+	}
+	line, rest, _ := strings.Cut(content, "\n")
+	return strings.TrimSpace(strings.TrimPrefix(line, "# ")), rest
+}
+
+// stripDescription drops the blockquoted description MarkdownAdapter writes
+// between the heading and the body.
+func stripDescription(body string) string {
+	lines := strings.Split(strings.TrimLeft(body, "\n"), "\n")
+	i := 0
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		i++
+	}
+	return strings.Join(lines[i:], "\n")
+}
+
+// matchSkill returns the skill whose body hash matches body, or failing that
+// whose name matches one of the candidate names.
+func matchSkill(skills []*skill.Skill, names []string, body string) (*skill.Skill, string) {
+	if strings.TrimSpace(body) != "" {
+		h := contentHash(body)
+		for _, s := range skills {
+			if contentHash(s.MarkdownBody) == h {
+				return s, MatchContent
+			}
+		}
+	}
+	for _, n := range names {
+		key := normalizeName(n)
+		if key == "" {
+			continue
+		}
+		for _, s := range skills {
+			if normalizeName(s.Frontmatter.Name) == key || normalizeName(s.DirName) == key {
+				return s, MatchName
+			}
+		}
+	}
+	return nil, ""
+}
+
+func contentHash(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	sum := sha256.Sum256([]byte(strings.TrimSpace(s)))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer(" ", "-", "_", "-").Replace(s)
+	return s
+}
@@ -0,0 +1,185 @@
+package adopt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+func testSkills() []*skill.Skill {
+	return []*skill.Skill{
+		{
+			Frontmatter:  skill.Frontmatter{Name: "code-review", Description: "Review code"},
+			DirName:      "code-review-skill",
+			MarkdownBody: "Review the diff carefully.\n",
+		},
+		{
+			Frontmatter:  skill.Frontmatter{Name: "five-whys"},
+			DirName:      "5-whys-skill",
+			MarkdownBody: "Ask why five times.\n",
+		},
+	}
+}
+
+func TestScan_SectionsByNameAndContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "AGENTS.md")
+	content := "Intro text.\n\n# Code Review\n\nOld hand-written notes.\n\n# Anything\n\n> desc\n\nAsk why five times.\n\n# Unknown\n\nStuff.\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loc := Location{ClientID: client.Codex, Scope: "project", Path: path}
+	found, unmatched, err := Scan(loc, testSkills())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("found %d matches, want 2: %+v", len(found), found)
+	}
+	if found[0].Skill.Frontmatter.Name != "code-review" || found[0].Match != MatchName {
+		t.Errorf("first match = %s/%s", found[0].Skill.Frontmatter.Name, found[0].Match)
+	}
+	if found[1].Skill.Frontmatter.Name != "five-whys" || found[1].Match != MatchContent {
+		t.Errorf("second match = %s/%s", found[1].Skill.Frontmatter.Name, found[1].Match)
+	}
+	if len(unmatched) != 1 || !strings.Contains(unmatched[0].Reason, "Unknown") {
+		t.Errorf("unmatched = %+v", unmatched)
+	}
+
+	if err := Apply(found); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	for _, want := range []string{
+		"Intro text.\n\n<!-- aisk:start:code-review -->\n# Code Review\n\nOld hand-written notes.\n<!-- aisk:end:code-review -->\n\n",
+		"<!-- aisk:start:five-whys -->\n# Anything\n\n> desc\n\nAsk why five times.\n<!-- aisk:end:five-whys -->\n\n# Unknown\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	// A second scan sees the sections as managed and makes no changes.
+	found, _, err = Scan(loc, testSkills())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range found {
+		if f.Match != MatchManaged || f.NeedsMarkers() {
+			t.Fatalf("expected managed matches after apply, got %+v", f)
+		}
+	}
+}
+
+func TestScan_CursorRules(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "code-review-skill.mdc"), []byte("---\ndescription: x\nglobs:\nalwaysApply: false\n---\n\nsomething else\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "whatever.mdc"), []byte("---\ndescription: y\n---\n\nAsk why five times.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "team-style.mdc"), []byte("Use tabs.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	found, unmatched, err := Scan(Location{ClientID: client.Cursor, Scope: "project", Path: dir}, testSkills())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || len(unmatched) != 1 {
+		t.Fatalf("found=%+v unmatched=%+v", found, unmatched)
+	}
+	if !strings.HasSuffix(unmatched[0].Artifact, "team-style.mdc") {
+		t.Errorf("unexpected unmatched artifact %s", unmatched[0].Artifact)
+	}
+
+	// whatever.mdc matched five-whys by content; Apply moves it to the name
+	// the Cursor adapter writes so update and uninstall find it.
+	var moved Found
+	for _, f := range found {
+		if f.NeedsRename() {
+			moved = f
+		}
+	}
+	if filepath.Base(moved.Artifact) != "whatever.mdc" || filepath.Base(moved.Target) != "5-whys-skill.mdc" {
+		t.Fatalf("rename = %s -> %s", moved.Artifact, moved.Target)
+	}
+	if err := Apply(found); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "whatever.mdc")); !os.IsNotExist(err) {
+		t.Error("whatever.mdc should have been renamed")
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "5-whys-skill.mdc")); err != nil || !strings.Contains(string(data), "Ask why five times.") {
+		t.Errorf("5-whys-skill.mdc = %q, %v", data, err)
+	}
+}
+
+func TestScan_RenameTargetTaken(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"5-whys-skill.mdc", "copy.mdc"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("Ask why five times.\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, unmatched, err := Scan(Location{ClientID: client.Cursor, Scope: "project", Path: dir}, testSkills())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].NeedsRename() {
+		t.Fatalf("found=%+v", found)
+	}
+	if len(unmatched) != 1 || !strings.HasSuffix(unmatched[0].Artifact, "copy.mdc") || !strings.Contains(unmatched[0].Reason, "already taken") {
+		t.Fatalf("unmatched=%+v", unmatched)
+	}
+}
+
+func TestScan_ClaudeSkillDirs(t *testing.T) {
+	dir := t.TempDir()
+	skillDir := filepath.Join(dir, "renamed")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: code-review\n---\nlocal edits\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	found, unmatched, err := Scan(Location{ClientID: client.Claude, Scope: "global", Path: dir}, testSkills())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Match != MatchName || len(unmatched) != 0 {
+		t.Fatalf("found=%+v unmatched=%+v", found, unmatched)
+	}
+	if err := Apply(found); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "code-review-skill", "SKILL.md")); err != nil {
+		t.Errorf("renamed directory should hold SKILL.md: %v", err)
+	}
+	if _, err := os.Stat(skillDir); !os.IsNotExist(err) {
+		t.Error("the old directory should be gone")
+	}
+}
+
+func TestScan_MissingLocation(t *testing.T) {
+	found, unmatched, err := Scan(Location{ClientID: client.Gemini, Scope: "global", Path: filepath.Join(t.TempDir(), "GEMINI.md")}, testSkills())
+	if err != nil || len(found) != 0 || len(unmatched) != 0 {
+		t.Fatalf("expected empty scan, got %v %v %v", found, unmatched, err)
+	}
+}
+
+func TestSplitSections_IgnoresFencedHeadings(t *testing.T) {
+	content := "# One\n```\n# not a heading\n```\n# Two\n"
+	secs := splitSections(content)
+	if len(secs) != 2 {
+		t.Fatalf("got %d sections, want 2", len(secs))
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/yorch/aisk/internal/secret"
 )
 
 const (
@@ -21,16 +23,8 @@ const (
 var maxLogSizeBytes int64 = 5 << 20 // 5 MiB
 var maxBackups = 3
 
-var sensitiveKeyPatterns = []*regexp.Regexp{
-	regexp.MustCompile(`(?i)token`),
-	regexp.MustCompile(`(?i)secret`),
-	regexp.MustCompile(`(?i)password`),
-	regexp.MustCompile(`(?i)authorization`),
-	regexp.MustCompile(`(?i)api[_-]?key`),
-}
-
 var bearerTokenPattern = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-\._~\+\/]+=*`)
-var keyValueSecretPattern = regexp.MustCompile(`(?i)\b(token|secret|password|api[_-]?key|authorization)\s*[:=]\s*([^\s,;]+)`)
+var keyValueSecretPattern = regexp.MustCompile(`(?i)\b` + secret.KeyPattern + `\s*[:=]\s*([^\s,;]+)`)
 
 // Event is a single structured audit entry.
 type Event struct {
@@ -240,7 +234,7 @@ func sanitizeEvent(e Event) Event {
 func sanitizeMap(m map[string]any) map[string]any {
 	out := make(map[string]any, len(m))
 	for k, v := range m {
-		if isSensitiveKey(k) {
+		if secret.IsSensitiveKey(k) {
 			out[k] = "[REDACTED]"
 			continue
 		}
@@ -266,17 +260,8 @@ func sanitizeValue(v any) any {
 	}
 }
 
-func isSensitiveKey(k string) bool {
-	for _, p := range sensitiveKeyPatterns {
-		if p.MatchString(k) {
-			return true
-		}
-	}
-	return false
-}
-
 func sanitizeString(s string) string {
 	out := bearerTokenPattern.ReplaceAllString(s, "${1}[REDACTED]")
 	out = keyValueSecretPattern.ReplaceAllString(out, "${1}=[REDACTED]")
-	return out
+	return secret.Redact(out)
 }
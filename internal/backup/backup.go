@@ -0,0 +1,542 @@
+// Package backup archives aisk's state and the client files it manages into
+// a tar.gz, and restores such an archive, remapping the home directory so a
+// backup taken on one machine can be restored on another.
+//
+// An archive starts with backup.json (Meta) followed by one member per
+// entry under files/<index>: a regular file, a symlink, or a directory tree.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
+)
+
+// FormatVersion is the archive layout written by Create.
+const FormatVersion = 1
+
+const metaName = "backup.json"
+
+// Entry kinds.
+const (
+	KindFile    = "file"
+	KindDir     = "dir"
+	KindSymlink = "symlink"
+)
+
+// Groups classify entries for restore.
+const (
+	GroupState   = "state"   // aisk's own files: manifests, project index
+	GroupAudit   = "audit"   // audit log; merged with the current one
+	GroupManaged = "managed" // client files aisk writes
+)
+
+// Source is a path to include in a backup.
+type Source struct {
+	Path  string
+	Group string
+	// RemapJSON marks JSON files whose string values hold absolute paths
+	// (the global manifest, the project index) to rewrite on restore.
+	RemapJSON bool
+}
+
+// Entry describes one archived path.
+type Entry struct {
+	Path      string `json:"path"` // absolute path at backup time
+	Member    string `json:"member"`
+	Kind      string `json:"kind"`
+	Group     string `json:"group"`
+	RemapJSON bool   `json:"remap_json,omitempty"`
+}
+
+// Meta is the archive's table of contents.
+type Meta struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	Host        string    `json:"host,omitempty"`
+	Home        string    `json:"home"`
+	AiskVersion string    `json:"aisk_version,omitempty"`
+	Entries     []Entry   `json:"entries"`
+}
+
+// Info describes a backup archive on disk.
+type Info struct {
+	Path string
+	Size int64
+	Meta *Meta
+}
+
+// Create writes an archive of sources to dest. Sources that do not exist are
+// skipped; duplicates are archived once.
+func Create(dest, home, aiskVersion string, sources []Source) (*Meta, error) {
+	host, _ := os.Hostname()
+	meta := &Meta{
+		Version:     FormatVersion,
+		CreatedAt:   time.Now().UTC(),
+		Host:        host,
+		Home:        home,
+		AiskVersion: aiskVersion,
+	}
+
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		p := filepath.Clean(src.Path)
+		if seen[p] {
+			continue
+		}
+		info, err := os.Lstat(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen[p] = true
+		kind := KindFile
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			kind = KindSymlink
+		case info.IsDir():
+			kind = KindDir
+		}
+		meta.Entries = append(meta.Entries, Entry{
+			Path:      p,
+			Member:    "files/" + strconv.Itoa(len(meta.Entries)),
+			Kind:      kind,
+			Group:     src.Group,
+			RemapJSON: src.RemapJSON,
+		})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := fsutil.TempSibling(dest)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeArchive(tmp, meta); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return meta, nil
+}
+
+func writeArchive(dest string, meta *Meta) (retErr error) {
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); retErr == nil {
+			retErr = err
+		}
+	}()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: metaName, Mode: 0o644, Size: int64(len(data)), ModTime: meta.CreatedAt}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	for _, e := range meta.Entries {
+		if err := addTree(tw, e.Path, e.Member); err != nil {
+			return fmt.Errorf("archiving %s: %w", e.Path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// addTree archives src under member without following symlinks.
+func addTree(tw *tar.Writer, src, member string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		name := member
+		if rel != "." {
+			name = path.Join(member, filepath.ToSlash(rel))
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uname, hdr.Gname = "", ""
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// ReadMeta returns the table of contents of an archive.
+func ReadMeta(archive string) (*Meta, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archive, err)
+	}
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archive, err)
+	}
+	if hdr.Name != metaName {
+		return nil, fmt.Errorf("%s: not an aisk backup (missing %s)", archive, metaName)
+	}
+	var meta Meta
+	if err := json.NewDecoder(tr).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("%s: reading %s: %w", archive, metaName, err)
+	}
+	if meta.Version > FormatVersion {
+		return nil, fmt.Errorf("%s: backup format %d is newer than this aisk supports (%d)", archive, meta.Version, FormatVersion)
+	}
+	return &meta, nil
+}
+
+// List returns the backups in dir, newest first.
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var infos []Info
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".tar.gz") {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		meta, err := ReadMeta(p)
+		if err != nil {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, Info{Path: p, Size: fi.Size(), Meta: meta})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Meta.CreatedAt.After(infos[j].Meta.CreatedAt) })
+	return infos, nil
+}
+
+// RemapHome rewrites p when it is oldHome or inside it so it points into
+// newHome instead. Other paths are returned unchanged.
+func RemapHome(p, oldHome, newHome string) string {
+	if oldHome == "" || newHome == "" || oldHome == newHome {
+		return p
+	}
+	prefix := strings.TrimRight(oldHome, `/\`)
+	if p == prefix {
+		return newHome
+	}
+	if len(p) > len(prefix) && strings.HasPrefix(p, prefix) && (p[len(prefix)] == '/' || p[len(prefix)] == '\\') {
+		rest := strings.ReplaceAll(p[len(prefix)+1:], `\`, "/")
+		return filepath.Join(newHome, filepath.FromSlash(rest))
+	}
+	return p
+}
+
+// RestoreOptions controls Restore.
+type RestoreOptions struct {
+	Home   string // home directory to restore into; paths under Meta.Home move here
+	DryRun bool
+	// BeforeWrite is called with each destination before it is replaced, so
+	// the caller can journal it.
+	BeforeWrite func(path string) error
+}
+
+// Restored reports what Restore did with one entry.
+type Restored struct {
+	Entry
+	Dest   string
+	Merged bool // an audit log combined with the existing one
+}
+
+// Restore writes every entry of archive back to its (remapped) location.
+// Files, directories and symlinks replace what is there. Audit logs are
+// merged instead: archived events missing from the current log are put in
+// front of it, so history recorded since the backup is kept.
+func Restore(archive string, opts RestoreOptions) ([]Restored, error) {
+	meta, err := ReadMeta(archive)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Restored, len(meta.Entries))
+	for i, e := range meta.Entries {
+		results[i] = Restored{Entry: e, Dest: RemapHome(e.Path, meta.Home, opts.Home)}
+		results[i].Merged = e.Group == GroupAudit && e.Kind == KindFile && exists(results[i].Dest)
+	}
+	if opts.DryRun {
+		return results, nil
+	}
+
+	staging, err := os.MkdirTemp("", "aisk-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+	if err := extract(archive, staging); err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		r := &results[i]
+		src := filepath.Join(staging, filepath.FromSlash(r.Member))
+		if r.RemapJSON {
+			if err := remapJSONFile(src, meta.Home, opts.Home); err != nil {
+				return results[:i], fmt.Errorf("remapping %s: %w", r.Path, err)
+			}
+		}
+		if r.Merged {
+			if err := mergeLines(src, r.Dest); err != nil {
+				return results[:i], fmt.Errorf("merging %s: %w", r.Path, err)
+			}
+		}
+		if opts.BeforeWrite != nil {
+			if err := opts.BeforeWrite(r.Dest); err != nil {
+				return results[:i], err
+			}
+		}
+		if err := place(src, r.Dest, r.Kind, meta.Home, opts.Home); err != nil {
+			return results[:i], fmt.Errorf("restoring %s: %w", r.Dest, err)
+		}
+	}
+	return results, nil
+}
+
+// extract unpacks the archive's members into dir. Members at or below a
+// symlink extracted earlier are refused, so a crafted archive cannot write
+// through a link to outside dir.
+func extract(archive, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	var links []string
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		if name == metaName {
+			continue
+		}
+		if !strings.HasPrefix(name, "files/") {
+			return fmt.Errorf("unexpected archive member %q", hdr.Name)
+		}
+		for _, link := range links {
+			if name == link || strings.HasPrefix(name, link+"/") {
+				return fmt.Errorf("archive member %q is inside symlink %q", hdr.Name, link)
+			}
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+			links = append(links, name)
+		case tar.TypeReg:
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// place moves an extracted entry to dest, replacing what is there.
+func place(src, dest, kind, oldHome, newHome string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	if kind == KindFile {
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		return fsutil.WriteFile(dest, data, info.Mode().Perm())
+	}
+
+	staged, err := fsutil.TempSibling(dest)
+	if err != nil {
+		return err
+	}
+	if kind == KindSymlink {
+		link, err := os.Readlink(src)
+		if err == nil {
+			err = os.Symlink(RemapHome(link, oldHome, newHome), staged)
+		}
+		if err != nil {
+			return err
+		}
+	} else if err := fsutil.CopyTree(src, staged); err != nil {
+		os.RemoveAll(staged)
+		return err
+	}
+	if err := fsutil.Swap(staged, dest); err != nil {
+		os.RemoveAll(staged)
+		return err
+	}
+	return nil
+}
+
+// remapJSONFile rewrites every string value in a JSON file that is a path
+// under oldHome.
+func remapJSONFile(file, oldHome, newHome string) error {
+	if oldHome == newHome {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(remapValue(v, oldHome, newHome), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, out, 0o644)
+}
+
+func remapValue(v any, oldHome, newHome string) any {
+	switch t := v.(type) {
+	case string:
+		return RemapHome(t, oldHome, newHome)
+	case []any:
+		for i := range t {
+			t[i] = remapValue(t[i], oldHome, newHome)
+		}
+	case map[string]any:
+		for k := range t {
+			t[k] = remapValue(t[k], oldHome, newHome)
+		}
+	}
+	return v
+}
+
+// mergeLines rewrites the archived log at src as its lines missing from the
+// current log at current, followed by the current log.
+func mergeLines(src, current string) error {
+	archived, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(current)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		have[line] = true
+	}
+	var b strings.Builder
+	for _, line := range strings.Split(string(archived), "\n") {
+		if line != "" && !have[line] {
+			b.WriteString(line + "\n")
+		}
+	}
+	b.Write(existing)
+	return os.WriteFile(src, []byte(b.String()), 0o644)
+}
+
+func exists(p string) bool {
+	_, err := os.Lstat(p)
+	return err == nil
+}
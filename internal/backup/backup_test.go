@@ -0,0 +1,173 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCreateAndRestore_RemapsHome(t *testing.T) {
+	oldHome, newHome := t.TempDir(), t.TempDir()
+
+	manifestPath := filepath.Join(oldHome, ".aisk", "manifest.json")
+	skillsDir := filepath.Join(oldHome, ".claude", "skills")
+	writeFile(t, manifestPath, `{"installations":[{"install_path":"`+filepath.ToSlash(skillsDir)+`"}]}`)
+	writeFile(t, filepath.Join(oldHome, ".codex", "instructions.md"), "managed section\n")
+	writeFile(t, filepath.Join(skillsDir, "copied", "SKILL.md"), "# Copied\n")
+	writeFile(t, filepath.Join(oldHome, "repo", "linked", "SKILL.md"), "# Linked\n")
+	if err := os.Symlink(filepath.Join(oldHome, "repo", "linked"), filepath.Join(skillsDir, "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "b.tar.gz")
+	meta, err := Create(archive, oldHome, "test", []Source{
+		{Path: manifestPath, Group: GroupState, RemapJSON: true},
+		{Path: filepath.Join(oldHome, ".codex", "instructions.md"), Group: GroupManaged},
+		{Path: filepath.Join(oldHome, ".codex", "instructions.md"), Group: GroupManaged},
+		{Path: filepath.Join(skillsDir, "copied"), Group: GroupManaged},
+		{Path: filepath.Join(skillsDir, "linked"), Group: GroupManaged},
+		{Path: filepath.Join(oldHome, "missing.md"), Group: GroupManaged},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta.Entries) != 4 {
+		t.Fatalf("entries = %d, want 4 (duplicates and missing paths skipped)", len(meta.Entries))
+	}
+
+	var written []string
+	results, err := Restore(archive, RestoreOptions{
+		Home:        newHome,
+		BeforeWrite: func(p string) error { written = append(written, p); return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 || len(written) != 4 {
+		t.Fatalf("restored %d, journaled %d; want 4 each", len(results), len(written))
+	}
+
+	if got := readFile(t, filepath.Join(newHome, ".codex", "instructions.md")); got != "managed section\n" {
+		t.Errorf("instructions.md = %q", got)
+	}
+	if got := readFile(t, filepath.Join(newHome, ".claude", "skills", "copied", "SKILL.md")); got != "# Copied\n" {
+		t.Errorf("copied SKILL.md = %q", got)
+	}
+	link, err := os.Readlink(filepath.Join(newHome, ".claude", "skills", "linked"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != filepath.Join(newHome, "repo", "linked") {
+		t.Errorf("symlink target = %q, want it remapped into the new home", link)
+	}
+
+	var m struct {
+		Installations []struct {
+			InstallPath string `json:"install_path"`
+		} `json:"installations"`
+	}
+	if err := json.Unmarshal([]byte(readFile(t, filepath.Join(newHome, ".aisk", "manifest.json"))), &m); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(newHome, ".claude", "skills"); m.Installations[0].InstallPath != want {
+		t.Errorf("manifest install path = %q, want %q", m.Installations[0].InstallPath, want)
+	}
+}
+
+func TestRestore_MergesAuditLog(t *testing.T) {
+	home := t.TempDir()
+	logPath := filepath.Join(home, ".aisk", "audit.log")
+	writeFile(t, logPath, "{\"a\":1}\n{\"a\":2}\n")
+
+	archive := filepath.Join(t.TempDir(), "b.tar.gz")
+	if _, err := Create(archive, home, "test", []Source{{Path: logPath, Group: GroupAudit}}); err != nil {
+		t.Fatal(err)
+	}
+	// Events written since the backup must survive the restore.
+	writeFile(t, logPath, "{\"a\":2}\n{\"a\":3}\n")
+
+	results, err := Restore(archive, RestoreOptions{Home: home})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Merged {
+		t.Fatal("expected the audit log to be merged")
+	}
+	if got, want := readFile(t, logPath), "{\"a\":1}\n{\"a\":2}\n{\"a\":3}\n"; got != want {
+		t.Fatalf("merged log = %q, want %q", got, want)
+	}
+}
+
+func TestReadMeta_RejectsOtherArchives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.tar.gz")
+	writeFile(t, path, "not gzip")
+	if _, err := ReadMeta(path); err == nil {
+		t.Fatal("expected an error for a non-backup file")
+	}
+}
+
+func TestExtract_RefusesWritesThroughSymlinks(t *testing.T) {
+	outside := t.TempDir()
+	for _, second := range []string{"files/x/authorized_keys", "files/x"} {
+		archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+		f, err := os.Create(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		_ = tw.WriteHeader(&tar.Header{Name: "files/x", Typeflag: tar.TypeSymlink, Linkname: outside})
+		_ = tw.WriteHeader(&tar.Header{Name: second, Typeflag: tar.TypeReg, Mode: 0o644, Size: 4})
+		_, _ = tw.Write([]byte("evil"))
+		_ = tw.Close()
+		_ = gz.Close()
+		_ = f.Close()
+
+		if err := extract(archive, t.TempDir()); err == nil || !strings.Contains(err.Error(), "symlink") {
+			t.Fatalf("%s: expected the member to be refused, got %v", second, err)
+		}
+		if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+			t.Fatalf("%s: extract wrote outside the staging directory", second)
+		}
+	}
+}
+
+func TestRemapHome(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"/home/old", "/home/new"},
+		{"/home/old/.claude/skills", filepath.Join("/home/new", ".claude", "skills")},
+		{"/home/older/x", "/home/older/x"},
+		{"/srv/project/AGENTS.md", "/srv/project/AGENTS.md"},
+	}
+	for _, tt := range tests {
+		if got := RemapHome(tt.path, "/home/old", "/home/new"); got != tt.want {
+			t.Errorf("RemapHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	if got := RemapHome("/home/old/x", "/home/old", "/home/old"); !strings.HasSuffix(got, "x") {
+		t.Errorf("same home should be a no-op, got %q", got)
+	}
+}
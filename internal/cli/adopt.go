@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/adopt"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/txn"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Bring existing client rules that match repo skills under management",
+	Long: `Scan client install locations for skills and rules that were written by
+hand or by another tool, match them to skills in the repository by name or
+content, and record them in the manifest. Markdown sections in shared files
+(GEMINI.md, AGENTS.md, ...) are wrapped in aisk section markers, and rule
+files or skill directories under another name are renamed to the one aisk
+writes, so later updates and uninstalls find them. Anything that cannot be matched is
+reported and left untouched.`,
+	Args: cobra.NoArgs,
+	RunE: runAdopt,
+}
+
+var (
+	adoptClient string
+	adoptScope  string
+	adoptDryRun bool
+)
+
+func init() {
+	adoptCmd.Flags().StringVar(&adoptClient, "client", "", "only scan this client")
+	adoptCmd.Flags().StringVar(&adoptScope, "scope", "all", "scope to scan (global, project, or all)")
+	adoptCmd.Flags().BoolVar(&adoptDryRun, "dry-run", false, "report matches without changing files or the manifest")
+}
+
+func runAdopt(_ *cobra.Command, _ []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "adopt")
+	al.Log("command.adopt", "started", map[string]any{
+		"client":  adoptClient,
+		"scope":   adoptScope,
+		"dry_run": adoptDryRun,
+	}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.adopt", status, nil, retErr)
+	}()
+
+	if adoptScope != "global" && adoptScope != "project" && adoptScope != "all" {
+		return fmt.Errorf("invalid scope %q (valid: global, project, all)", adoptScope)
+	}
+
+	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	if err != nil {
+		return fmt.Errorf("scanning skills: %w", err)
+	}
+	pol, err := loadPolicy(paths)
+	if err != nil {
+		return err
+	}
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return err
+	}
+
+	reg := client.NewRegistry()
+	client.DetectAll(reg, paths.Home)
+	clients := reg.Detected()
+	if adoptClient != "" {
+		clientID := client.ParseClientID(adoptClient)
+		if clientID == "" {
+			return fmt.Errorf("unknown client %q (valid: claude, gemini, codex, copilot, cursor, windsurf)", adoptClient)
+		}
+		clients = []*client.Client{reg.Get(clientID)}
+	}
+
+	cwd, _ := os.Getwd()
+	locations := adoptLocations(clients, adoptScope, config.FindProjectRoot(cwd))
+
+	var found []adopt.Found
+	var unmatched []adopt.Unmatched
+	for _, loc := range locations {
+		f, u, err := adopt.Scan(loc, skills)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: scanning %s: %v\n", loc.Path, err)
+			continue
+		}
+		found = append(found, f...)
+		unmatched = append(unmatched, u...)
+	}
+
+	if !adoptDryRun {
+		lock, err := acquireManifestLock(paths, al)
+		if err != nil {
+			return err
+		}
+		defer releaseManifestLock(lock, al)
+		recoverTransactions(paths, al)
+	}
+	m, err := loadManifests(paths, manifest.ViewDefault, al)
+	if err != nil {
+		return err
+	}
+
+	// Drop matches the manifest already tracks.
+	var adopted []adopt.Found
+	for _, f := range found {
+		if isTracked(m, f) {
+			continue
+		}
+		adopted = append(adopted, f)
+	}
+
+	// Adopting puts a skill under management as install would, so it must
+	// pass the same checks for every client and scope before anything is
+	// wrapped or recorded.
+	checked := make(map[*skill.Skill]bool)
+	for _, f := range adopted {
+		if !checked[f.Skill] {
+			checked[f.Skill] = true
+			if err := checkSignature(store, al, f.Skill, signatureRequired(false, pol)); err != nil {
+				return err
+			}
+			if scanRequested(false, pol) {
+				if err := checkScan(al, f.Skill); err != nil {
+					return err
+				}
+			}
+		}
+		if err := checkPolicy(pol, al, f.Skill, string(f.ClientID), f.Scope); err != nil {
+			return err
+		}
+	}
+
+	printAdoptReport(adopted, unmatched, adoptDryRun)
+	if adoptDryRun || len(adopted) == 0 {
+		return nil
+	}
+
+	tx, err := txn.Begin(paths.TxnDir, "adopt")
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	rollback := func(cause error) error {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w; rollback failed: %v (will retry on next run)", cause, rbErr)
+		}
+		return fmt.Errorf("%w; rolled back all changes", cause)
+	}
+	for _, f := range adopted {
+		if !f.NeedsMarkers() && !f.NeedsRename() {
+			continue
+		}
+		for _, p := range []string{f.Artifact, f.Target} {
+			if err := trackForWrite(tx, p); err != nil {
+				return rollback(fmt.Errorf("journaling %s: %w", p, err))
+			}
+		}
+	}
+	if err := adopt.Apply(adopted); err != nil {
+		return rollback(err)
+	}
+
+	now := time.Now()
+	for _, f := range adopted {
+		version := f.Skill.DisplayVersion()
+		hash, filesHash := skillContentHash(f.Skill), skillFilesHash(f.Skill)
+		if f.Match == adopt.MatchName {
+			// The content on disk differs from the repo, so leave the version
+			// and content unknown and let status/update offer a re-render.
+			version = "unversioned"
+			hash, filesHash = "", ""
+		}
+		if err := m.Add(manifest.Installation{
+			SkillName:    f.Skill.Frontmatter.Name,
+			SkillVersion: version,
+			ClientID:     string(f.ClientID),
+			Scope:        f.Scope,
+			InstallPath:  f.Path,
+			InstalledAt:  now,
+			UpdatedAt:    now,
+			Source:       f.Skill.SourceName(),
+			ContentHash:  hash,
+			FilesHash:    filesHash,
+		}); err != nil {
+			return rollback(fmt.Errorf("recording %s on %s: %w", f.Skill.Frontmatter.Name, f.ClientID, err))
+		}
+		al.LogEvent(audit.Event{
+			Action:   "adopt.record",
+			Status:   "success",
+			Skill:    f.Skill.Frontmatter.Name,
+			ClientID: string(f.ClientID),
+			Scope:    f.Scope,
+			Target:   f.Target,
+			Details:  map[string]any{"match": f.Match, "wrapped": f.NeedsMarkers(), "renamed_from": renamedFrom(f)},
+		})
+	}
+	for _, p := range m.Files() {
+		if err := trackForWrite(tx, p); err != nil {
+			return rollback(fmt.Errorf("journaling manifest: %w", err))
+		}
+	}
+	if err := m.Save(); err != nil {
+		return rollback(fmt.Errorf("saving manifest: %w", err))
+	}
+	if err := tx.Commit(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not clean up transaction %s: %v\n", tx.ID(), err)
+	}
+	return nil
+}
+
+// adoptLocations lists the install targets to scan for each client and scope.
+func adoptLocations(clients []*client.Client, scope, projectRoot string) []adopt.Location {
+	var locs []adopt.Location
+	for _, c := range clients {
+		if (scope == "global" || scope == "all") && c.SupportsGlobal && c.GlobalPath != "" {
+			locs = append(locs, adopt.Location{ClientID: c.ID, Scope: "global", Path: c.GlobalPath})
+		}
+		if (scope == "project" || scope == "all") && c.SupportsProject && projectRoot != "" {
+			locs = append(locs, adopt.Location{ClientID: c.ID, Scope: "project", Path: filepath.Join(projectRoot, c.ProjectPath)})
+		}
+	}
+	return locs
+}
+
+func isTracked(m *manifest.Set, f adopt.Found) bool {
+	for _, inst := range m.Find(f.Skill.Frontmatter.Name, string(f.ClientID)) {
+		if inst.Scope == f.Scope {
+			return true
+		}
+	}
+	return false
+}
+
+// renamedFrom returns the artifact path Apply moves away from, or "".
+func renamedFrom(f adopt.Found) string {
+	if f.NeedsRename() {
+		return f.Artifact
+	}
+	return ""
+}
+
+func printAdoptReport(adopted []adopt.Found, unmatched []adopt.Unmatched, dryRun bool) {
+	verb := "Adopted"
+	if dryRun {
+		verb = "Would adopt"
+	}
+	if len(adopted) == 0 {
+		fmt.Println("Nothing to adopt.")
+	} else {
+		fmt.Printf("%s %d artifact(s):\n", verb, len(adopted))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  SKILL\tCLIENT\tSCOPE\tMATCH\tARTIFACT")
+		for _, f := range adopted {
+			match := f.Match
+			if f.NeedsMarkers() {
+				match += " (wrap)"
+			}
+			if f.NeedsRename() {
+				match += " (rename to " + filepath.Base(f.Target) + ")"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", f.Skill.Frontmatter.Name, f.ClientID, f.Scope, match, f.Artifact)
+		}
+		w.Flush()
+	}
+
+	if len(unmatched) > 0 {
+		fmt.Printf("\nUnmatched (%d):\n", len(unmatched))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, u := range unmatched {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", u.ClientID, u.Artifact, u.Reason)
+		}
+		w.Flush()
+	}
+}
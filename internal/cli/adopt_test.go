@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestRunAdopt_WrapsSectionAndRecords(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	codexDir := filepath.Join(home, ".codex")
+	if err := os.MkdirAll(codexDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(codexDir, "instructions.md")
+	existing := "# skill-a\n\nMy own notes.\n\n# Personal\n\nOther text.\n"
+	if err := os.WriteFile(target, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	t.Chdir(t.TempDir())
+
+	prevClient, prevScope, prevDryRun := adoptClient, adoptScope, adoptDryRun
+	t.Cleanup(func() { adoptClient, adoptScope, adoptDryRun = prevClient, prevScope, prevDryRun })
+	adoptClient, adoptScope = "codex", "global"
+
+	adoptDryRun = true
+	out := captureStdout(t, func() {
+		if err := runAdopt(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "Would adopt 1") || !strings.Contains(out, "Personal") {
+		t.Fatalf("unexpected dry-run output:\n%s", out)
+	}
+	if data, _ := os.ReadFile(target); string(data) != existing {
+		t.Fatalf("dry run modified file:\n%s", data)
+	}
+
+	adoptDryRun = false
+	captureStdout(t, func() {
+		if err := runAdopt(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	data, _ := os.ReadFile(target)
+	if !strings.HasPrefix(string(data), "<!-- aisk:start:skill-a -->\n# skill-a\n") {
+		t.Fatalf("expected section to be wrapped, got:\n%s", data)
+	}
+
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifest.Load(paths.ManifestDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := m.Find("skill-a", "codex")
+	if len(found) != 1 || found[0].SkillVersion != "unversioned" || found[0].InstallPath != target {
+		t.Fatalf("unexpected manifest entries: %+v", found)
+	}
+
+	// Running again finds nothing new.
+	out = captureStdout(t, func() {
+		if err := runAdopt(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "Nothing to adopt.") {
+		t.Fatalf("expected no new adoptions, got:\n%s", out)
+	}
+}
+
+func TestRunAdopt_AppliesPolicy(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	codexDir := filepath.Join(home, ".codex")
+	if err := os.MkdirAll(codexDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(codexDir, "instructions.md")
+	existing := "# skill-a\n\nMy own notes.\n"
+	if err := os.WriteFile(target, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".aisk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".aisk", "policy.yaml"), []byte("clients:\n  deny: [codex]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SCAN", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	t.Chdir(t.TempDir())
+
+	prevClient, prevScope, prevDryRun := adoptClient, adoptScope, adoptDryRun
+	t.Cleanup(func() { adoptClient, adoptScope, adoptDryRun = prevClient, prevScope, prevDryRun })
+	adoptClient, adoptScope, adoptDryRun = "codex", "global", false
+
+	var err error
+	captureStdout(t, func() { err = runAdopt(nil, nil) })
+	if err == nil || !strings.Contains(err.Error(), "clients.deny") {
+		t.Fatalf("expected the policy to block adopting, got %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != existing {
+		t.Fatalf("a blocked adopt modified the file:\n%s", data)
+	}
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Find("skill-a", "codex"); len(got) != 0 {
+		t.Fatalf("a blocked adopt must not be recorded, got %+v", got)
+	}
+}
+
+func TestRunAdopt_RenamesToAdapterName(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	oldDir := filepath.Join(home, ".claude", "skills", "old-name")
+	if err := os.MkdirAll(oldDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(oldDir, "SKILL.md"), []byte("---\nname: old-name\n---\n# Skill\nUse when: test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SCAN", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	t.Chdir(t.TempDir())
+
+	prevClient, prevScope, prevDryRun := adoptClient, adoptScope, adoptDryRun
+	t.Cleanup(func() { adoptClient, adoptScope, adoptDryRun = prevClient, prevScope, prevDryRun })
+	adoptClient, adoptScope, adoptDryRun = "claude", "global", false
+
+	out := captureStdout(t, func() {
+		if err := runAdopt(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "rename to skill-a") {
+		t.Fatalf("expected the rename to be reported, got:\n%s", out)
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Fatal("old-name should have been renamed")
+	}
+	if _, err := os.Stat(filepath.Join(home, ".claude", "skills", "skill-a", "SKILL.md")); err != nil {
+		t.Fatalf("expected the skill under its adapter name: %v", err)
+	}
+
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := m.Find("skill-a", "claude")
+	if len(found) != 1 || found[0].SkillVersion != "1.0.0" || found[0].ContentHash == "" {
+		t.Fatalf("unexpected manifest entries: %+v", found)
+	}
+}
@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/backup"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/txn"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up and restore aisk state and managed files",
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Archive manifests, audit log and managed client files",
+	Long: `Archive the global and project manifests, the project index, the audit log,
+everything else in ~/.aisk, and every file aisk manages for the recorded
+installations (markdown files with managed sections, .mdc and Windsurf rule
+files, Claude skill directories or links) into ~/.aisk/backups.`,
+	Args: cobra.NoArgs,
+	RunE: runBackupCreate,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups in ~/.aisk/backups",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupList,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <backup>",
+	Short: "Restore a backup by name or path",
+	Long: `Restore every archived file to where it was backed up from. Paths under the
+home directory of the machine that created the backup are remapped to the
+current home directory, including install paths inside the manifest. Audit
+logs are merged with the current ones rather than replaced. The restore is
+journaled and rolled back as a whole if any file cannot be written.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackupRestore,
+}
+
+var backupRestoreDryRun bool
+
+func init() {
+	backupRestoreCmd.Flags().BoolVar(&backupRestoreDryRun, "dry-run", false, "show what would be restored without writing")
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+}
+
+func runBackupCreate(_ *cobra.Command, _ []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "backup")
+	al.Log("command.backup.create", "started", nil, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.backup.create", status, nil, retErr)
+	}()
+
+	// Hold the lock so the manifests and client files form one snapshot.
+	lock, err := acquireManifestLock(paths, al)
+	if err != nil {
+		return err
+	}
+	defer releaseManifestLock(lock, al)
+
+	sources, err := backupSources(paths, al)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(paths.BackupDir, "aisk-"+time.Now().UTC().Format("20060102T150405Z")+".tar.gz")
+	meta, err := backup.Create(dest, paths.Home, config.AppVersion, sources)
+	if err != nil {
+		return fmt.Errorf("creating backup: %w", err)
+	}
+	var size int64
+	if fi, err := os.Stat(dest); err == nil {
+		size = fi.Size()
+	}
+	al.Log("backup.create", "success", map[string]any{"path": dest, "entries": len(meta.Entries), "size": size}, nil)
+	fmt.Printf("Backed up %d path(s) to %s (%s)\n", len(meta.Entries), dest, formatSize(size))
+	return nil
+}
+
+// backupSources lists what a backup contains: aisk's own files, the audit
+// logs, the project manifests and the output of every recorded installation.
+func backupSources(paths config.Paths, al *audit.Logger) ([]backup.Source, error) {
+	var sources []backup.Source
+
+	auditLogs := make(map[string]bool)
+	for _, p := range audit.CandidateLogPaths(resolveAuditLogPath(paths)) {
+		auditLogs[filepath.Clean(p)] = true
+		sources = append(sources, backup.Source{Path: p, Group: backup.GroupAudit})
+	}
+
+	entries, err := os.ReadDir(paths.AiskDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		p := filepath.Join(paths.AiskDir, e.Name())
+		switch {
+		case p == paths.BackupDir, p == paths.CacheDir, p == paths.TxnDir,
+			strings.HasSuffix(e.Name(), ".lock"), auditLogs[p]:
+			continue
+		}
+		sources = append(sources, backup.Source{
+			Path:      p,
+			Group:     backup.GroupState,
+			RemapJSON: p == paths.ManifestDB || p == paths.ProjectsDB,
+		})
+	}
+
+	set, err := loadManifests(paths, manifest.ViewAllProjects, al)
+	if err != nil {
+		return nil, err
+	}
+	dirNames := make(map[string]string)
+	if skills, err := skill.ScanLocal(paths.SkillsRepo); err == nil {
+		for _, s := range skills {
+			dirNames[s.Frontmatter.Name] = s.DirName
+		}
+	}
+	for _, loc := range set.Locations() {
+		if loc.Root != "" {
+			sources = append(sources, backup.Source{Path: manifest.ProjectPath(loc.Root), Group: backup.GroupState})
+		}
+		for _, inst := range loc.Installations {
+			stub := &skill.Skill{DirName: inst.SkillName}
+			if dir, ok := dirNames[inst.SkillName]; ok {
+				stub.DirName = dir
+			}
+			out := adapterOutputPath(client.ParseClientID(inst.ClientID), inst.Scope, stub, inst.InstallPath)
+			sources = append(sources, backup.Source{Path: out, Group: backup.GroupManaged})
+		}
+	}
+	return sources, nil
+}
+
+func runBackupList(_ *cobra.Command, _ []string) error {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	infos, err := backup.List(paths.BackupDir)
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		fmt.Println("No backups found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCREATED\tSIZE\tPATHS\tHOST")
+	for _, info := range infos {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n",
+			filepath.Base(info.Path),
+			info.Meta.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			formatSize(info.Size),
+			len(info.Meta.Entries),
+			info.Meta.Host)
+	}
+	return w.Flush()
+}
+
+func runBackupRestore(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "backup")
+	al.Log("command.backup.restore", "started", map[string]any{"args": args, "dry_run": backupRestoreDryRun}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.backup.restore", status, nil, retErr)
+	}()
+
+	archive := resolveBackupArchive(paths, args[0])
+	meta, err := backup.ReadMeta(archive)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Restoring backup of %s taken %s\n", meta.Host, meta.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	if meta.Home != paths.Home {
+		fmt.Printf("Remapping %s -> %s\n", meta.Home, paths.Home)
+	}
+
+	if backupRestoreDryRun {
+		results, err := backup.Restore(archive, backup.RestoreOptions{Home: paths.Home, DryRun: true})
+		if err != nil {
+			return err
+		}
+		printRestoreResults(results, true)
+		fmt.Println("\n[dry-run] nothing restored")
+		return nil
+	}
+
+	lock, err := acquireManifestLock(paths, al)
+	if err != nil {
+		return err
+	}
+	defer releaseManifestLock(lock, al)
+	recoverTransactions(paths, al)
+
+	tx, err := txn.Begin(paths.TxnDir, "backup restore")
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	results, err := backup.Restore(archive, backup.RestoreOptions{
+		Home:        paths.Home,
+		BeforeWrite: func(p string) error { return trackForWrite(tx, p) },
+	})
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w; rollback failed: %v (will retry on next run)", err, rbErr)
+		}
+		return fmt.Errorf("%w; rolled back all changes", err)
+	}
+	if err := tx.Commit(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not clean up transaction %s: %v\n", tx.ID(), err)
+	}
+
+	printRestoreResults(results, false)
+	al.Log("backup.restore", "success", map[string]any{
+		"archive":  archive,
+		"entries":  len(results),
+		"from":     meta.Home,
+		"to":       paths.Home,
+		"remapped": meta.Home != paths.Home,
+	}, nil)
+	return nil
+}
+
+// resolveBackupArchive accepts a path to an archive or the name of one in
+// the backups directory, with or without the .tar.gz extension.
+func resolveBackupArchive(paths config.Paths, arg string) string {
+	if _, err := os.Stat(arg); err == nil {
+		return arg
+	}
+	name := arg
+	if !strings.HasSuffix(name, ".tar.gz") {
+		name += ".tar.gz"
+	}
+	return filepath.Join(paths.BackupDir, name)
+}
+
+func printRestoreResults(results []backup.Restored, dryRun bool) {
+	restored := 0
+	for _, r := range results {
+		verb := "restored"
+		switch {
+		case dryRun && r.Merged:
+			verb = "merge"
+		case dryRun:
+			verb = "restore"
+		case r.Merged:
+			verb = "merged"
+		}
+		fmt.Printf("  %-8s %s\n", verb, r.Dest)
+		restored++
+	}
+	if !dryRun {
+		fmt.Printf("\n%d path(s) restored.\n", restored)
+	}
+}
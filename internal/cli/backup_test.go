@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestBackupCreateAndRestoreIntoNewHome(t *testing.T) {
+	oldHome := t.TempDir()
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", oldHome)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	if err := os.MkdirAll(filepath.Join(oldHome, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+
+	origClient, origScope, origRefs, origDryRun := installClient, installScope, installIncludeRefs, installDryRun
+	origRestoreDryRun := backupRestoreDryRun
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun = origClient, origScope, origRefs, origDryRun
+		backupRestoreDryRun = origRestoreDryRun
+	})
+	installClient, installScope, installIncludeRefs, installDryRun = "codex", "global", false, false
+	captureStdout(t, func() {
+		if err := runInstall(nil, []string{"skill-a"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := runBackupCreate(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "Backed up") {
+		t.Fatalf("unexpected create output:\n%s", out)
+	}
+	archives, _ := filepath.Glob(filepath.Join(oldHome, ".aisk", "backups", "*.tar.gz"))
+	if len(archives) != 1 {
+		t.Fatalf("backups = %v, want one", archives)
+	}
+	out = captureStdout(t, func() {
+		if err := runBackupList(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, filepath.Base(archives[0])) {
+		t.Fatalf("list output missing backup:\n%s", out)
+	}
+
+	newHome := t.TempDir()
+	t.Setenv("HOME", newHome)
+	backupRestoreDryRun = false
+	out = captureStdout(t, func() {
+		if err := runBackupRestore(nil, []string{archives[0]}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "Remapping "+oldHome+" -> "+newHome) {
+		t.Fatalf("expected home remapping, got:\n%s", out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(newHome, ".codex", "instructions.md"))
+	if err != nil || !strings.Contains(string(data), "aisk:start:skill-a") {
+		t.Fatalf("managed file not restored: %v\n%s", err, data)
+	}
+	m, err := manifest.Load(filepath.Join(newHome, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := m.Find("skill-a", "codex")
+	if len(got) != 1 || got[0].InstallPath != filepath.Join(newHome, ".codex", "instructions.md") {
+		t.Fatalf("restored manifest = %+v, want install path in the new home", got)
+	}
+}
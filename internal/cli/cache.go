@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clean the remote skill cache",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached remote skills with their size and whether they are in use",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheList,
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove cached skills no installation refers to",
+	Long: `Remove cache entries whose source is not recorded by any installation in
+the global or project manifests, including entries left by interrupted
+downloads or older versions of aisk. With --all, empty the cache.`,
+	Args: cobra.NoArgs,
+	RunE: runCacheClean,
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check cached skills against the contents they were downloaded with",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheVerify,
+}
+
+var (
+	cacheCleanAll    bool
+	cacheCleanDryRun bool
+)
+
+func init() {
+	cacheCleanCmd.Flags().BoolVar(&cacheCleanAll, "all", false, "remove every entry, including ones in use")
+	cacheCleanCmd.Flags().BoolVar(&cacheCleanDryRun, "dry-run", false, "show what would be removed without deleting")
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	cacheCmd.AddCommand(cacheVerifyCmd)
+}
+
+// cachedSources returns the sources recorded by every installation, the set
+// of cache keys that are in use.
+func cachedSources(paths config.Paths, al *audit.Logger) (map[string]bool, error) {
+	set, err := loadManifests(paths, manifest.ViewAllProjects, al)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, inst := range set.Installations() {
+		used[inst.Source] = true
+	}
+	return used, nil
+}
+
+func cacheLabel(paths config.Paths, e skill.CacheEntry) string {
+	if e.Meta.Source != "" {
+		return e.Meta.Source
+	}
+	if rel, err := filepath.Rel(paths.CacheDir, e.Path); err == nil {
+		return rel + " (no metadata)"
+	}
+	return e.Path
+}
+
+func runCacheList(_ *cobra.Command, _ []string) error {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "cache")
+	entries, err := skill.ListCache(paths.CacheDir)
+	if err != nil {
+		return fmt.Errorf("reading cache: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("Cache is empty.")
+		return nil
+	}
+	used, err := cachedSources(paths, al)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SOURCE\tSIZE\tFETCHED\tSTATUS")
+	for _, e := range entries {
+		fetched, status := "-", "unused"
+		if !e.Meta.FetchedAt.IsZero() {
+			fetched = e.Meta.FetchedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		if e.Meta.Source != "" && used[e.Meta.Source] {
+			status = "in use"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cacheLabel(paths, e), formatSize(e.Size), fetched, status)
+		total += e.Size
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d entry(s), %s in %s\n", len(entries), formatSize(total), paths.CacheDir)
+	return nil
+}
+
+func runCacheClean(_ *cobra.Command, _ []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "cache")
+	al.Log("command.cache.clean", "started", map[string]any{"all": cacheCleanAll, "dry_run": cacheCleanDryRun}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.cache.clean", status, nil, retErr)
+	}()
+
+	// Hold the lock so an install cannot start using an entry being removed.
+	lock, err := acquireManifestLock(paths, al)
+	if err != nil {
+		return err
+	}
+	defer releaseManifestLock(lock, al)
+
+	entries, err := skill.ListCache(paths.CacheDir)
+	if err != nil {
+		return fmt.Errorf("reading cache: %w", err)
+	}
+	used, err := cachedSources(paths, al)
+	if err != nil {
+		return err
+	}
+
+	var removed int
+	var freed int64
+	for _, e := range entries {
+		if !cacheCleanAll && e.Meta.Source != "" && used[e.Meta.Source] {
+			continue
+		}
+		label := cacheLabel(paths, e)
+		if cacheCleanDryRun {
+			fmt.Printf("[dry-run] remove %s (%s)\n", label, formatSize(e.Size))
+		} else {
+			if err := skill.RemoveCacheEntry(paths.CacheDir, e); err != nil {
+				al.Log("cache.remove", "error", map[string]any{"path": e.Path}, err)
+				return fmt.Errorf("removing %s: %w", label, err)
+			}
+			al.Log("cache.remove", "success", map[string]any{"path": e.Path, "source": e.Meta.Source, "size": e.Size}, nil)
+			fmt.Printf("Removed %s (%s)\n", label, formatSize(e.Size))
+		}
+		removed++
+		freed += e.Size
+	}
+
+	// Cached GitHub responses are only revalidation data; --all drops them too.
+	httpDir := skill.HTTPCacheDir(paths.CacheDir)
+	size, err := skill.HTTPCacheSize(paths.CacheDir)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", httpDir, err)
+	}
+	if cacheCleanAll && size > 0 {
+		if cacheCleanDryRun {
+			fmt.Printf("[dry-run] remove cached GitHub responses (%s)\n", formatSize(size))
+		} else {
+			if err := os.RemoveAll(httpDir); err != nil {
+				al.Log("cache.remove", "error", map[string]any{"path": httpDir}, err)
+				return fmt.Errorf("removing cached GitHub responses: %w", err)
+			}
+			al.Log("cache.remove", "success", map[string]any{"path": httpDir, "size": size}, nil)
+			fmt.Printf("Removed cached GitHub responses (%s)\n", formatSize(size))
+		}
+		removed++
+		freed += size
+	}
+
+	switch {
+	case removed == 0:
+		fmt.Println("Nothing to clean.")
+	case cacheCleanDryRun:
+		fmt.Printf("\n[dry-run] would free %s from %d entry(s)\n", formatSize(freed), removed)
+	default:
+		fmt.Printf("\nFreed %s from %d entry(s).\n", formatSize(freed), removed)
+	}
+	return nil
+}
+
+func runCacheVerify(_ *cobra.Command, _ []string) error {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	entries, err := skill.ListCache(paths.CacheDir)
+	if err != nil {
+		return fmt.Errorf("reading cache: %w", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("Cache is empty.")
+		return nil
+	}
+
+	var failed int
+	for _, e := range entries {
+		if err := skill.VerifyCacheEntry(e); err != nil {
+			fmt.Printf("  FAIL  %s: %v\n", cacheLabel(paths, e), err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ok    %s\n", cacheLabel(paths, e))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d cache entry(s) failed verification", failed, len(entries))
+	}
+	fmt.Printf("\n%d entry(s) verified.\n", len(entries))
+	return nil
+}
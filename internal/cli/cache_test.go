@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+func TestRunCacheClean_KeepsEntriesInUse(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := func(repo string) string {
+		dir := skill.CachePath(paths.CacheDir, skill.RepoRef{Owner: "o", Repo: repo}, "")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: "+repo+"\n---\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := json.Marshal(skill.CacheMeta{Source: skill.RemoteSource(skill.RepoRef{Owner: "o", Repo: repo}, ""), FetchedAt: time.Now()})
+		if err := os.WriteFile(filepath.Join(dir, skill.CacheMetaFile), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	used, unused := cache("used"), cache("unused")
+	httpDir := skill.HTTPCacheDir(paths.CacheDir)
+	if err := os.MkdirAll(httpDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(httpDir, "x.body"), []byte("tree"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manifest.Load(paths.ManifestDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Add(manifest.Installation{SkillName: "used", ClientID: "codex", Scope: "global", InstallPath: "/x", Source: skill.RemoteSource(skill.RepoRef{Owner: "o", Repo: "used"}, "")})
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	origAll, origDryRun := cacheCleanAll, cacheCleanDryRun
+	t.Cleanup(func() { cacheCleanAll, cacheCleanDryRun = origAll, origDryRun })
+
+	out := captureStdout(t, func() {
+		if err := runCacheList(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "github.com/o/used@main") || !strings.Contains(out, "in use") || !strings.Contains(out, "2 entry(s)") {
+		t.Fatalf("unexpected list output:\n%s", out)
+	}
+
+	cacheCleanAll, cacheCleanDryRun = false, false
+	captureStdout(t, func() {
+		if err := runCacheClean(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if _, err := os.Stat(unused); !os.IsNotExist(err) {
+		t.Fatalf("unused entry should be removed, got %v", err)
+	}
+	if _, err := os.Stat(used); err != nil {
+		t.Fatalf("entry in use should be kept: %v", err)
+	}
+	if _, err := os.Stat(httpDir); err != nil {
+		t.Fatalf("cached responses should be kept without --all: %v", err)
+	}
+
+	cacheCleanAll = true
+	captureStdout(t, func() {
+		if err := runCacheClean(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if _, err := os.Stat(used); !os.IsNotExist(err) {
+		t.Fatalf("--all should remove every entry, got %v", err)
+	}
+	if _, err := os.Stat(httpDir); !os.IsNotExist(err) {
+		t.Fatalf("--all should remove cached responses, got %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/adapter"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/policy"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/trust"
+	"github.com/yorch/aisk/internal/tui"
+	"github.com/yorch/aisk/internal/watch"
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev <skill>",
+	Short: "Watch a skill directory and reinstall it on every change",
+	Long: `Install a skill to the given clients, then watch its directory
+(SKILL.md, reference/, examples/, assets/) and re-render it through the
+adapters whenever files change. SKILL.md is linted on every change.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDev,
+}
+
+var (
+	devClients     []string
+	devScope       string
+	devIncludeRefs bool
+	devDebounce    time.Duration
+	devAcceptTools bool
+)
+
+func init() {
+	devCmd.Flags().StringSliceVar(&devClients, "client", nil, "target clients (repeatable or comma-separated)")
+	devCmd.Flags().StringVar(&devScope, "scope", "global", "installation scope (global or project)")
+	devCmd.Flags().BoolVar(&devIncludeRefs, "include-refs", false, "inline reference files in output")
+	devCmd.Flags().DurationVar(&devDebounce, "debounce", 300*time.Millisecond, "quiet period before reinstalling after a change")
+	devCmd.Flags().BoolVar(&devAcceptTools, "accept-tools", false, "accept tool permissions the skill requests beyond those of the installed version")
+}
+
+func runDev(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "dev")
+	al.Log("command.dev", "started", map[string]any{
+		"args":         args,
+		"clients":      devClients,
+		"scope":        devScope,
+		"include_refs": devIncludeRefs,
+		"accept_tools": devAcceptTools,
+	}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.dev", status, nil, retErr)
+	}()
+
+	if len(devClients) == 0 {
+		return fmt.Errorf("--client is required")
+	}
+
+	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	if err != nil {
+		return fmt.Errorf("scanning skills: %w", err)
+	}
+	target := findSkillByArg(skills, args[0])
+	if target == nil {
+		return fmt.Errorf("skill %q not found", args[0])
+	}
+
+	reg := client.NewRegistry()
+	client.DetectAll(reg, paths.Home)
+	targetClients, err := resolveDevClients(reg, devClients)
+	if err != nil {
+		return err
+	}
+
+	if err := paths.EnsureDirs(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	session, err := newDevSession(paths, al, target.Path, targetClients, adapter.InstallOpts{
+		Scope:       devScope,
+		IncludeRefs: devIncludeRefs,
+	})
+	if err != nil {
+		return err
+	}
+	if err := session.apply(true); err != nil {
+		return err
+	}
+
+	w, err := watch.New(target.Path)
+	if err != nil {
+		return fmt.Errorf("watching %s: %w", target.Path, err)
+	}
+	defer w.Close()
+
+	fmt.Printf("\nWatching %s (Ctrl-C to stop)\n", target.Path)
+	batches := watch.Debounce(w.Events(), devDebounce)
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("\nStopped watching.")
+			return nil
+		case err := <-w.Errors():
+			return fmt.Errorf("watching %s: %w", target.Path, err)
+		case batch := <-batches:
+			fmt.Printf("\n[%s] %d change(s) detected\n", time.Now().Format("15:04:05"), len(batch))
+			if err := session.apply(false); err != nil {
+				fmt.Fprintf(os.Stderr, "  skipping install: %v\n", err)
+			}
+		}
+	}
+}
+
+func resolveDevClients(reg *client.Registry, ids []string) ([]*client.Client, error) {
+	var result []*client.Client
+	for _, raw := range ids {
+		clientID := client.ParseClientID(raw)
+		if clientID == "" {
+			return nil, fmt.Errorf("unknown client %q (valid: claude, gemini, codex, copilot, cursor, windsurf)", raw)
+		}
+		c := reg.Get(clientID)
+		if !c.Detected {
+			return nil, fmt.Errorf("client %s not detected on this system", c.Name)
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// devSession re-lints and reinstalls one skill directory to a fixed set of clients.
+type devSession struct {
+	paths    config.Paths
+	skillDir string
+	clients  []*client.Client
+	opts     adapter.InstallOpts
+	al       *audit.Logger
+	pol      *policy.Set
+	store    *trust.Store
+	tools    *toolConfirmer
+}
+
+func newDevSession(paths config.Paths, al *audit.Logger, skillDir string, clients []*client.Client, opts adapter.InstallOpts) (*devSession, error) {
+	pol, err := loadPolicy(paths)
+	if err != nil {
+		return nil, err
+	}
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return nil, err
+	}
+	return &devSession{
+		paths:    paths,
+		skillDir: skillDir,
+		clients:  clients,
+		opts:     opts,
+		al:       al,
+		pol:      pol,
+		store:    store,
+		tools:    newToolConfirmer(pol, al, devAcceptTools),
+	}, nil
+}
+
+// apply lints SKILL.md and re-renders the skill through each adapter. On the
+// first pass every client is installed; afterwards adapters that link the
+// skill directory (and so already see the change) are only recorded. It
+// returns an error, and installs nothing, when install would refuse the
+// skill as it now stands.
+func (d *devSession) apply(initial bool) error {
+	data, err := os.ReadFile(filepath.Join(d.skillDir, "SKILL.md"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  error: reading SKILL.md: %v\n", err)
+		return nil
+	}
+	report := skill.LintSkillMD(string(data))
+	if len(report.Results) == 0 {
+		fmt.Println("  lint: no issues")
+	} else {
+		printLintResults(report, "  lint ")
+	}
+
+	s, err := skill.LoadLocal(d.skillDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  skipping install: %v\n", err)
+		d.al.Log("dev.reload", "error", map[string]any{"path": d.skillDir}, err)
+		return nil
+	}
+	if err := d.check(s); err != nil {
+		return err
+	}
+
+	cwd, _ := os.Getwd()
+	projectRoot := config.FindProjectRoot(cwd)
+
+	var installed []manifest.Installation
+	var projectClients []*client.Client
+	for _, c := range d.clients {
+		targetPath := resolveTargetPath(c, d.opts.Scope)
+		if targetPath == "" {
+			fmt.Fprintf(os.Stderr, "  %s does not support %s scope, skipping\n", c.Name, d.opts.Scope)
+			continue
+		}
+		adp, err := adapter.ForClient(c.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  no adapter for %s: %v\n", c.Name, err)
+			continue
+		}
+		if _, renders := adp.(adapter.Renderer); renders || initial || s.Source != skill.SourceLocal {
+			if err := adp.Install(s, targetPath, d.opts); err != nil {
+				fmt.Fprintf(os.Stderr, "  %s %s: %v\n", tui.ErrorStyle.Render("!"), c.Name, err)
+				d.al.LogEvent(audit.Event{
+					Action:   "dev.adapter.apply",
+					Status:   "error",
+					Skill:    s.Frontmatter.Name,
+					ClientID: string(c.ID),
+					Scope:    d.opts.Scope,
+					Target:   targetPath,
+					Error:    err.Error(),
+				})
+				continue
+			}
+			fmt.Printf("  %s %s  %s\n", tui.DoneIndicator, c.Name, adp.Describe(s, targetPath, d.opts))
+			d.al.LogEvent(audit.Event{
+				Action:   "dev.adapter.apply",
+				Status:   "success",
+				Skill:    s.Frontmatter.Name,
+				ClientID: string(c.ID),
+				Scope:    d.opts.Scope,
+				Target:   targetPath,
+			})
+		}
+
+		manifestPath := targetPath
+		if d.opts.Scope == "project" && projectRoot != "" {
+			manifestPath = filepath.Join(projectRoot, targetPath)
+		}
+		installed = append(installed, manifest.Installation{
+			SkillName:    s.Frontmatter.Name,
+			SkillVersion: s.DisplayVersion(),
+			ClientID:     string(c.ID),
+			Scope:        d.opts.Scope,
+			InstallPath:  manifestPath,
+			Source:       s.SourceName(),
+			ContentHash:  skillContentHash(s),
+			FilesHash:    skillFilesHash(s),
+			Options:      installOptions(d.opts),
+			AllowedTools: grantedTools(s),
+		})
+		if d.opts.Scope == "project" {
+			projectClients = append(projectClients, c)
+		}
+	}
+
+	if len(installed) > 0 {
+		if err := d.record(installed); err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: could not update manifest: %v\n", err)
+		}
+	}
+	if initial && len(projectClients) > 0 {
+		manageGitignoreOnInstall(projectClients)
+	}
+	return nil
+}
+
+// check applies install's checks to s: its signature and content scan when
+// required, the policy for every client, and any tools it requests beyond
+// those its installations were granted.
+func (d *devSession) check(s *skill.Skill) error {
+	if err := checkSignature(d.store, d.al, s, signatureRequired(false, d.pol)); err != nil {
+		return err
+	}
+	if scanRequested(false, d.pol) {
+		if err := checkScan(d.al, s); err != nil {
+			return err
+		}
+	}
+	for _, c := range d.clients {
+		if err := checkPolicy(d.pol, d.al, s, string(c.ID), d.opts.Scope); err != nil {
+			return err
+		}
+	}
+	m, err := loadManifests(d.paths, manifest.ViewDefault, d.al)
+	if err != nil {
+		return err
+	}
+	var existing []manifest.Installation
+	for _, c := range d.clients {
+		for _, inst := range m.Find(s.Frontmatter.Name, string(c.ID)) {
+			if inst.Scope == d.opts.Scope {
+				existing = append(existing, inst)
+			}
+		}
+	}
+	return d.tools.check(s, existing...)
+}
+
+// record upserts installations into the manifest, keeping original install times.
+func (d *devSession) record(installed []manifest.Installation) error {
+	lock, err := acquireManifestLock(d.paths, d.al)
+	if err != nil {
+		return err
+	}
+	defer releaseManifestLock(lock, d.al)
+
+	m, err := loadManifests(d.paths, manifest.ViewDefault, d.al)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, inst := range installed {
+		inst.InstalledAt = now
+		for _, existing := range m.Find(inst.SkillName, inst.ClientID) {
+			if existing.Scope == inst.Scope {
+				inst.InstalledAt = existing.InstalledAt
+			}
+		}
+		inst.UpdatedAt = now
+		if err := m.Add(inst); err != nil {
+			return err
+		}
+	}
+	return m.Save()
+}
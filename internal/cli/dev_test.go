@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/adapter"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestDevSession_ApplyReinstallsAndRecords(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+	reg := client.NewRegistry()
+	client.DetectAll(reg, home)
+	clients, err := resolveDevClients(reg, []string{"codex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := newDevSession(paths, audit.New(paths.AiskDir, "dev"), filepath.Join(skillsRepo, "skill-a"), clients, adapter.InstallOpts{Scope: "global"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() { err = session.apply(true) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(home, ".codex", "instructions.md")
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("expected initial install: %v", err)
+	}
+	if !strings.Contains(string(data), "Use when: test") {
+		t.Fatalf("unexpected content: %s", data)
+	}
+
+	updated := "---\nname: skill-a\ndescription: test\nversion: 1.1.0\n---\n# Skill\nUse when: edited\n"
+	if err := os.WriteFile(filepath.Join(skillsRepo, "skill-a", "SKILL.md"), []byte(updated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { err = session.apply(false) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ = os.ReadFile(target)
+	if !strings.Contains(string(data), "Use when: edited") || strings.Contains(string(data), "Use when: test") {
+		t.Fatalf("expected section to be re-rendered, got: %s", data)
+	}
+
+	m, err := manifest.Load(paths.ManifestDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := m.Find("skill-a", "codex")
+	if len(found) != 1 || found[0].SkillVersion != "1.1.0" {
+		t.Fatalf("expected manifest to track version 1.1.0, got %+v", found)
+	}
+}
+
+func TestDevSession_LintFindingsPrinted(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	skillDir := filepath.Join(skillsRepo, "skill-a")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: skill-a\ndescription: test\n---\nno trigger\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := newDevSession(paths, audit.New(paths.AiskDir, "dev"), skillDir, nil, adapter.InstallOpts{Scope: "global"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := captureStdout(t, func() { session.apply(false) })
+	if !strings.Contains(out, "Use when:") {
+		t.Fatalf("expected lint warning in output, got: %s", out)
+	}
+}
+
+func TestDev_AppliesPolicy(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".aisk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SCAN", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	policyFile := filepath.Join(home, ".aisk", "policy.yaml")
+	target := filepath.Join(home, ".codex", "instructions.md")
+
+	origClients, origScope, origRefs := devClients, devScope, devIncludeRefs
+	t.Cleanup(func() { devClients, devScope, devIncludeRefs = origClients, origScope, origRefs })
+	devClients, devScope, devIncludeRefs = []string{"codex"}, "global", false
+
+	// A denied client fails before anything is written, as with install.
+	if err := os.WriteFile(policyFile, []byte("clients:\n  deny: [codex]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	captureStdout(t, func() { err = runDev(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "clients.deny") {
+		t.Fatalf("expected the policy to block dev, got %v", err)
+	}
+	if _, statErr := os.Stat(target); !os.IsNotExist(statErr) {
+		t.Fatal("nothing should be written when the policy blocks dev")
+	}
+
+	// A reload that starts requesting a denied tool is not installed.
+	if err := os.WriteFile(policyFile, []byte("denied_tools: [Bash]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := client.NewRegistry()
+	client.DetectAll(reg, home)
+	clients, err := resolveDevClients(reg, devClients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := newDevSession(paths, audit.New(paths.AiskDir, "dev"), filepath.Join(skillsRepo, "skill-a"), clients, adapter.InstallOpts{Scope: "global"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { err = session.apply(true) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := "---\nname: skill-a\ndescription: test\nversion: 1.1.0\nallowed-tools: Bash\n---\n# Skill\nUse when: edited\n"
+	if err := os.WriteFile(filepath.Join(skillsRepo, "skill-a", "SKILL.md"), []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { err = session.apply(false) })
+	if err == nil || !strings.Contains(err.Error(), "denied_tools") {
+		t.Fatalf("expected the reload to be refused, got %v", err)
+	}
+	if data, _ := os.ReadFile(target); strings.Contains(string(data), "Use when: edited") {
+		t.Fatalf("a refused reload must not be installed:\n%s", data)
+	}
+}
+
+func TestResolveDevClients_UnknownClient(t *testing.T) {
+	reg := client.NewRegistry()
+	if _, err := resolveDevClients(reg, []string{"emacs"}); err == nil {
+		t.Fatal("expected error for unknown client")
+	}
+}
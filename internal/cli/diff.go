@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/adapter"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/diff"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/tui"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [skill]",
+	Short: "Show changes update would make to installed skills",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runDiff,
+}
+
+var diffClient string
+
+func init() {
+	diffCmd.Flags().StringVar(&diffClient, "client", "", "specific client to diff")
+}
+
+func runDiff(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "diff")
+	al.Log("command.diff", "started", map[string]any{
+		"args":   args,
+		"client": diffClient,
+	}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.diff", status, nil, retErr)
+	}()
+
+	m, err := loadManifests(paths, manifest.ViewDefault, al)
+	if err != nil {
+		return err
+	}
+
+	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	if err != nil {
+		return fmt.Errorf("scanning skills: %w", err)
+	}
+	skillMap := make(map[string]*skill.Skill)
+	for _, s := range skills {
+		skillMap[s.Frontmatter.Name] = s
+		skillMap[s.DirName] = s
+	}
+
+	var targets []manifest.Installation
+	if len(args) > 0 {
+		targets = m.Find(args[0], diffClient)
+		if len(targets) == 0 {
+			if s, ok := skillMap[args[0]]; ok {
+				targets = m.Find(s.Frontmatter.Name, diffClient)
+			}
+		}
+	} else {
+		targets = m.Installations()
+		if diffClient != "" {
+			targets = m.FindByClient(diffClient)
+		}
+	}
+
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "No matching installations to diff.")
+		return nil
+	}
+
+	color := stdoutIsTerminal()
+	changed := 0
+	for _, inst := range targets {
+		s := skillMap[inst.SkillName]
+		if s == nil {
+			fmt.Fprintf(os.Stderr, "warning: skill %q not found in repo, skipping\n", inst.SkillName)
+			continue
+		}
+
+		patch, err := diffInstallation(inst, s, replayOptions(inst, optionOverrides{}))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: diff %s on %s: %v\n", inst.SkillName, inst.ClientID, err)
+			continue
+		}
+		if patch == "" {
+			continue
+		}
+		changed++
+		if color {
+			patch = colorizePatch(patch)
+		}
+		fmt.Print(patch)
+	}
+
+	if changed == 0 {
+		fmt.Fprintln(os.Stderr, "No differences.")
+	} else {
+		fmt.Fprintf(os.Stderr, "%d installation(s) differ.\n", changed)
+	}
+	al.Log("diff.compute", "success", map[string]any{"installations": len(targets), "changed": changed}, nil)
+	return nil
+}
+
+// diffInstallation renders s through the installation's adapter and returns a
+// unified diff against what is currently on disk, or "" when they match.
+func diffInstallation(inst manifest.Installation, s *skill.Skill, opts adapter.InstallOpts) (string, error) {
+	clientID := client.ParseClientID(inst.ClientID)
+	adp, err := adapter.ForClient(clientID)
+	if err != nil {
+		return "", err
+	}
+
+	r, ok := adp.(adapter.Renderer)
+	if !ok {
+		return diffSkillTree(filepath.Join(inst.InstallPath, s.DirName), s.Path)
+	}
+
+	rendered, err := r.Render(s, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if isSectionBasedClient(clientID, inst.Scope) {
+		current, err := readFileOrEmpty(inst.InstallPath)
+		if err != nil {
+			return "", err
+		}
+		section, _ := adapter.ExtractSection(current, s.Frontmatter.Name)
+		return diff.Unified(inst.InstallPath, inst.InstallPath, section, rendered), nil
+	}
+
+	dest := filepath.Join(inst.InstallPath, s.DirName+".md")
+	if clientID == client.Cursor {
+		dest = filepath.Join(inst.InstallPath, s.DirName+".mdc")
+	}
+	current, err := readFileOrEmpty(dest)
+	if err != nil {
+		return "", err
+	}
+	return diff.Unified(dest, dest, current, rendered), nil
+}
+
+// diffSkillTree compares every file in an installed skill directory (following
+// a symlinked root) with the repository copy.
+func diffSkillTree(installedDir, repoDir string) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(installedDir); err == nil {
+		if repoResolved, err := filepath.EvalSymlinks(repoDir); err == nil && resolved == repoResolved {
+			return "", nil
+		}
+		installedDir = resolved
+	}
+
+	installed, err := listTreeFiles(installedDir)
+	if err != nil {
+		return "", err
+	}
+	repo, err := listTreeFiles(repoDir)
+	if err != nil {
+		return "", err
+	}
+
+	union := make(map[string]bool)
+	for _, f := range installed {
+		union[f] = true
+	}
+	for _, f := range repo {
+		union[f] = true
+	}
+	rels := make([]string, 0, len(union))
+	for f := range union {
+		rels = append(rels, f)
+	}
+	sort.Strings(rels)
+
+	var b strings.Builder
+	for _, rel := range rels {
+		from, err := readFileOrEmpty(filepath.Join(installedDir, rel))
+		if err != nil {
+			return "", err
+		}
+		to, err := readFileOrEmpty(filepath.Join(repoDir, rel))
+		if err != nil {
+			return "", err
+		}
+		if from == to {
+			continue
+		}
+		name := filepath.Join(installedDir, rel)
+		if isBinary(from) || isBinary(to) {
+			fmt.Fprintf(&b, "Binary files %s and %s differ\n", name, filepath.Join(repoDir, rel))
+			continue
+		}
+		b.WriteString(diff.Unified(name, name, from, to))
+	}
+	return b.String(), nil
+}
+
+func listTreeFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+func readFileOrEmpty(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+func isBinary(s string) bool {
+	return bytes.IndexByte([]byte(s), 0) >= 0
+}
+
+func colorizePatch(patch string) string {
+	add := lipgloss.NewStyle().Foreground(tui.Green)
+	del := lipgloss.NewStyle().Foreground(tui.Red)
+	hunk := lipgloss.NewStyle().Foreground(tui.Cyan)
+	header := lipgloss.NewStyle().Bold(true)
+
+	lines := strings.SplitAfter(patch, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+		nl := line[len(text):]
+		switch {
+		case strings.HasPrefix(text, "+++ "), strings.HasPrefix(text, "--- "):
+			text = header.Render(text)
+		case strings.HasPrefix(text, "@@"):
+			text = hunk.Render(text)
+		case strings.HasPrefix(text, "+"):
+			text = add.Render(text)
+		case strings.HasPrefix(text, "-"):
+			text = del.Render(text)
+		}
+		b.WriteString(text + nl)
+	}
+	return b.String()
+}
+
+// stdoutIsTerminal reports whether stdout is attached to a terminal.
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
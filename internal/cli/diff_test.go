@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/adapter"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+func loadTestSkill(t *testing.T, repo, name string) *skill.Skill {
+	t.Helper()
+	skills, err := skill.ScanLocal(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := findSkillByArg(skills, name)
+	if s == nil {
+		t.Fatalf("skill %q not found", name)
+	}
+	return s
+}
+
+func TestDiffInstallation_SectionChanged(t *testing.T) {
+	repo := t.TempDir()
+	createTestSkill(t, repo, "skill-a", "1.0.0")
+	s := loadTestSkill(t, repo, "skill-a")
+
+	target := filepath.Join(t.TempDir(), "AGENTS.md")
+	adp := &adapter.MarkdownAdapter{ClientName: "Codex"}
+	if err := adp.Install(s, target, adapter.InstallOpts{Scope: "project"}); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := manifest.Installation{SkillName: "skill-a", ClientID: "codex", Scope: "project", InstallPath: target}
+	patch, err := diffInstallation(inst, s, adapter.InstallOpts{Scope: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch != "" {
+		t.Fatalf("expected no diff right after install, got:\n%s", patch)
+	}
+
+	s.MarkdownBody = "# Skill\nUse when: changed\n"
+	patch, err = diffInstallation(inst, s, adapter.InstallOpts{Scope: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(patch, "-Use when: test") || !strings.Contains(patch, "+Use when: changed") {
+		t.Fatalf("unexpected patch:\n%s", patch)
+	}
+}
+
+func TestDiffInstallation_CursorMissingFile(t *testing.T) {
+	repo := t.TempDir()
+	createTestSkill(t, repo, "skill-a", "1.0.0")
+	s := loadTestSkill(t, repo, "skill-a")
+
+	rulesDir := t.TempDir()
+	inst := manifest.Installation{SkillName: "skill-a", ClientID: "cursor", Scope: "project", InstallPath: rulesDir}
+	patch, err := diffInstallation(inst, s, adapter.InstallOpts{Scope: "project"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(patch, "@@ -0,0 +1,") || !strings.Contains(patch, "+alwaysApply: false") {
+		t.Fatalf("unexpected patch:\n%s", patch)
+	}
+}
+
+func TestDiffInstallation_ClaudeCopiedTree(t *testing.T) {
+	repo := t.TempDir()
+	createTestSkill(t, repo, "skill-a", "1.0.0")
+	s := loadTestSkill(t, repo, "skill-a")
+
+	skillsDir := t.TempDir()
+	installed := filepath.Join(skillsDir, "skill-a")
+	if err := os.MkdirAll(installed, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(installed, "SKILL.md"), []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(installed, "stale.md"), []byte("gone\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := manifest.Installation{SkillName: "skill-a", ClientID: "claude", Scope: "global", InstallPath: skillsDir}
+	patch, err := diffInstallation(inst, s, adapter.InstallOpts{Scope: "global"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(patch, "-old") || !strings.Contains(patch, "+name: skill-a") || !strings.Contains(patch, "-gone") {
+		t.Fatalf("unexpected patch:\n%s", patch)
+	}
+}
+
+func TestDiffInstallation_ClaudeSymlinkUpToDate(t *testing.T) {
+	repo := t.TempDir()
+	createTestSkill(t, repo, "skill-a", "1.0.0")
+	s := loadTestSkill(t, repo, "skill-a")
+
+	skillsDir := t.TempDir()
+	if err := os.Symlink(s.Path, filepath.Join(skillsDir, "skill-a")); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := manifest.Installation{SkillName: "skill-a", ClientID: "claude", Scope: "global", InstallPath: skillsDir}
+	patch, err := diffInstallation(inst, s, adapter.InstallOpts{Scope: "global"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patch != "" {
+		t.Fatalf("expected no diff for symlink to repo, got:\n%s", patch)
+	}
+}
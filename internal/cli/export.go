@@ -0,0 +1,454 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/adapter"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/policy"
+	"github.com/yorch/aisk/internal/registry"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/txn"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the global install set as JSON for aisk import",
+	Long: `Print every global installation as JSON, one entry per skill with the
+version, source, install options and the clients it is installed to. Feed the
+output to "aisk import" on another machine to reproduce the same installs.`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+// exportFormatVersion is bumped when the export file layout changes.
+const exportFormatVersion = 1
+
+// exportFile is the document written by aisk export.
+type exportFile struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Skills     []exportedSkill `json:"skills"`
+}
+
+// exportedSkill is one skill installed with the same version, source,
+// content and options to one or more clients. Commit and the digests pin
+// remote skills to what was exported.
+type exportedSkill struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Source      string            `json:"source,omitempty"`
+	Commit      string            `json:"commit,omitempty"`
+	ContentHash string            `json:"content_hash,omitempty"`
+	FilesHash   string            `json:"files_hash,omitempty"`
+	Clients     []string          `json:"clients"`
+	Options     *manifest.Options `json:"options,omitempty"`
+}
+
+func runExport(_ *cobra.Command, _ []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "export")
+	al.Log("command.export", "started", nil, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.export", status, nil, retErr)
+	}()
+
+	m, err := loadManifests(paths, manifest.ViewGlobal, al)
+	if err != nil {
+		return err
+	}
+	doc := buildExport(m.Installations())
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	al.Log("export.write", "success", map[string]any{"skills": len(doc.Skills)}, nil)
+	return nil
+}
+
+// buildExport groups global installations by skill, version, source,
+// content and options, listing the clients of each group.
+func buildExport(insts []manifest.Installation) exportFile {
+	doc := exportFile{Version: exportFormatVersion, ExportedAt: time.Now().UTC(), Skills: []exportedSkill{}}
+	for _, inst := range insts {
+		if inst.Scope != "global" {
+			continue
+		}
+		found := false
+		for i := range doc.Skills {
+			e := &doc.Skills[i]
+			if e.Name == inst.SkillName && e.Version == inst.SkillVersion && e.Source == inst.Source &&
+				e.Commit == inst.Commit && e.ContentHash == inst.ContentHash && e.FilesHash == inst.FilesHash &&
+				sameOptions(e.Options, inst.Options) {
+				e.Clients = append(e.Clients, inst.ClientID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			doc.Skills = append(doc.Skills, exportedSkill{
+				Name:        inst.SkillName,
+				Version:     inst.SkillVersion,
+				Source:      inst.Source,
+				Commit:      inst.Commit,
+				ContentHash: inst.ContentHash,
+				FilesHash:   inst.FilesHash,
+				Clients:     []string{inst.ClientID},
+				Options:     inst.Options,
+			})
+		}
+	}
+	for i := range doc.Skills {
+		sort.Strings(doc.Skills[i].Clients)
+	}
+	sort.SliceStable(doc.Skills, func(i, j int) bool { return doc.Skills[i].Name < doc.Skills[j].Name })
+	return doc
+}
+
+func sameOptions(a, b *manifest.Options) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func readExport(path string) (*exportFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading export: %w", err)
+	}
+	var doc exportFile
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w (rule files need --from)", path, err)
+	}
+	if doc.Version == 0 || doc.Version > exportFormatVersion {
+		return nil, fmt.Errorf("%s: unsupported export version %d (this aisk reads up to %d)", path, doc.Version, exportFormatVersion)
+	}
+	return &doc, nil
+}
+
+// importResult is the outcome for one skill and client of an import.
+type importResult struct {
+	skill  string
+	client string
+	status string // installed, skipped, blocked or missing
+	detail string
+}
+
+// runImportInstalls reproduces the installs in an export file. Skills are
+// resolved from their recorded source and installed globally to each listed
+// client that is detected here; the rest are reported and skipped. All writes
+// are journaled and rolled back together if any install fails.
+func runImportInstalls(paths config.Paths, al *audit.Logger, path string) error {
+	doc, err := readExport(path)
+	if err != nil {
+		return err
+	}
+	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	if err != nil {
+		return fmt.Errorf("scanning skills: %w", err)
+	}
+	reg := client.NewRegistry()
+	client.DetectAll(reg, paths.Home)
+	pol, err := loadPolicy(paths)
+	if err != nil {
+		return err
+	}
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return err
+	}
+	fetchOpts, err := remoteFetchOptions(paths)
+	if err != nil {
+		return err
+	}
+	sources := &importSources{
+		paths:      paths,
+		al:         al,
+		pol:        pol,
+		local:      skills,
+		remotes:    newRemoteCache(al, fetchOpts),
+		pins:       newPinChecker(fetchOpts, al, importAcceptChanges),
+		registries: newRegistrySources(paths, al),
+	}
+	tools := newToolConfirmer(pol, al, importAcceptTools)
+
+	if err := paths.EnsureDirs(); err != nil {
+		return err
+	}
+	lock, err := acquireManifestLock(paths, al)
+	if err != nil {
+		return err
+	}
+	defer releaseManifestLock(lock, al)
+	recoverTransactions(paths, al)
+
+	m, err := loadManifests(paths, manifest.ViewGlobal, al)
+	if err != nil {
+		return err
+	}
+	tx, err := txn.Begin(paths.TxnDir, "import")
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	rollback := func(cause error) error {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w; rollback failed: %v (will retry on next run)", cause, rbErr)
+		}
+		return fmt.Errorf("%w; rolled back all changes", cause)
+	}
+
+	var results []importResult
+	for _, e := range doc.Skills {
+		target, status, detail := sources.resolve(e)
+		if target == nil {
+			results = append(results, importResult{skill: e.Name, status: status, detail: detail})
+			continue
+		}
+		version := ""
+		if target.DisplayVersion() != e.Version {
+			version = fmt.Sprintf("version %s (exported %s)", target.DisplayVersion(), e.Version)
+		}
+		opts := replayOptions(manifest.Installation{Scope: "global", Options: e.Options}, optionOverrides{})
+		hash := skillContentHash(target)
+		filesHash := skillFilesHash(target)
+		sigErr := checkSignature(store, al, target, signatureRequired(false, pol))
+		if sigErr == nil && scanRequested(false, pol) {
+			sigErr = checkScan(al, target)
+		}
+
+		for _, raw := range e.Clients {
+			res := importResult{skill: e.Name, client: raw, status: "skipped"}
+			id := client.ParseClientID(raw)
+			if id == "" {
+				res.detail = "unknown client"
+				results = append(results, res)
+				continue
+			}
+			c := reg.Get(id)
+			res.client = c.Name
+			targetPath := resolveTargetPath(c, "global")
+			switch {
+			case !c.Detected:
+				res.detail = "not detected on this system"
+			case targetPath == "":
+				res.detail = "does not support global scope"
+			case sigErr != nil:
+				res.status, res.detail = "blocked", sigErr.Error()
+			default:
+				if err := checkPolicy(pol, al, target, string(id), "global"); err != nil {
+					res.status, res.detail = "blocked", err.Error()
+				} else if err := tools.check(target, m.Find(target.Frontmatter.Name, string(id))...); err != nil {
+					res.detail = err.Error()
+				}
+			}
+			if res.detail != "" {
+				results = append(results, res)
+				al.LogEvent(audit.Event{
+					Action:   "import.adapter.apply",
+					Status:   "skipped",
+					Skill:    e.Name,
+					ClientID: raw,
+					Scope:    "global",
+					Error:    res.detail,
+				})
+				continue
+			}
+
+			adp, err := adapter.ForClient(id)
+			if err != nil {
+				return rollback(fmt.Errorf("no adapter for %s: %w", c.Name, err))
+			}
+			if err := trackForWrite(tx, adapterOutputPath(id, "global", target, targetPath)); err != nil {
+				return rollback(fmt.Errorf("journaling %s: %w", c.Name, err))
+			}
+			if err := adp.Install(target, targetPath, opts); err != nil {
+				al.LogEvent(audit.Event{
+					Action:   "import.adapter.apply",
+					Status:   "error",
+					Skill:    e.Name,
+					ClientID: raw,
+					Scope:    "global",
+					Target:   targetPath,
+					Error:    err.Error(),
+				})
+				return rollback(fmt.Errorf("installing %s to %s: %w", e.Name, c.Name, err))
+			}
+			now := time.Now()
+			if err := m.Add(manifest.Installation{
+				SkillName:    target.Frontmatter.Name,
+				SkillVersion: target.DisplayVersion(),
+				ClientID:     string(id),
+				Scope:        "global",
+				InstalledAt:  now,
+				UpdatedAt:    now,
+				InstallPath:  targetPath,
+				Source:       target.SourceName(),
+				ContentHash:  hash,
+				FilesHash:    filesHash,
+				Commit:       target.Commit,
+				Options:      installOptions(opts),
+				AllowedTools: grantedTools(target),
+			}); err != nil {
+				return rollback(fmt.Errorf("recording %s: %w", c.Name, err))
+			}
+			al.LogEvent(audit.Event{
+				Action:   "import.adapter.apply",
+				Status:   "success",
+				Skill:    e.Name,
+				ClientID: raw,
+				Scope:    "global",
+				Target:   targetPath,
+			})
+			res.status, res.detail = "installed", version
+			results = append(results, res)
+		}
+	}
+
+	for _, f := range m.Files() {
+		if err := trackForWrite(tx, f); err != nil {
+			return rollback(fmt.Errorf("journaling manifest: %w", err))
+		}
+	}
+	if err := m.Save(); err != nil {
+		return rollback(fmt.Errorf("saving manifest: %w", err))
+	}
+	if err := tx.Commit(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not clean up transaction %s: %v\n", tx.ID(), err)
+	}
+
+	printImportResults(results)
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.status]++
+	}
+	al.Log("import.installs", "success", map[string]any{
+		"source":    path,
+		"installed": counts["installed"],
+		"skipped":   counts["skipped"],
+		"blocked":   counts["blocked"],
+		"missing":   counts["missing"],
+	}, nil)
+	return nil
+}
+
+// importSources resolves export entries from the source they were
+// installed from, as update does: the skills repo for local installs, and
+// otherwise the same repository, registry version or package. Remote
+// entries are fetched at their exported commit. An entry is never satisfied
+// by a local skill that merely shares its name.
+type importSources struct {
+	paths      config.Paths
+	al         *audit.Logger
+	pol        *policy.Set
+	local      []*skill.Skill
+	remotes    *remoteCache
+	pins       *pinChecker
+	registries *registrySources
+}
+
+// resolve returns the skill e names, or nil with the status and detail to
+// report instead.
+func (is *importSources) resolve(e exportedSkill) (*skill.Skill, string, string) {
+	var s *skill.Skill
+	var err error
+	if r, ref, ok := skill.ParseRemoteSource(e.Source); ok {
+		if err := checkSourcePolicy(is.pol, is.al, e.Name, e.Source); err != nil {
+			return nil, "blocked", err.Error()
+		}
+		if s, err = is.remote(r, ref, e); err != nil {
+			return nil, "missing", err.Error()
+		}
+		if err := is.checkPin(e, s); err != nil {
+			return nil, "skipped", err.Error()
+		}
+	} else if _, _, _, ok := registry.ParseSource(e.Source); ok {
+		s, err = is.registries.exact(e.Source)
+	} else if strings.HasPrefix(e.Source, skill.ArchiveSource("")) {
+		s, err = loadArchiveSource(is.paths, is.al, manifest.Installation{SkillName: e.Name, Source: e.Source})
+	} else if e.Source == "" || e.Source == manifest.SourceLocal {
+		if s = findSkillByArg(is.local, e.Name); s == nil {
+			return nil, "missing", "not found in " + is.paths.SkillsRepo
+		}
+	} else {
+		return nil, "missing", fmt.Sprintf("unsupported source %q", e.Source)
+	}
+	if err != nil {
+		return nil, "missing", err.Error()
+	}
+	if s.Frontmatter.Name != e.Name {
+		return nil, "missing", fmt.Sprintf("%s now holds %s", e.Source, s.Frontmatter.Name)
+	}
+	return s, "", ""
+}
+
+// remote fetches a remote entry at its exported commit, keeping the exported
+// source so later updates follow the same ref. Entries exported without a
+// commit, or whose commit is no longer available, are fetched at the ref.
+func (is *importSources) remote(r skill.RepoRef, ref string, e exportedSkill) (*skill.Skill, error) {
+	if e.Commit != "" {
+		if s, err := is.remotes.get(r, e.Commit); err == nil {
+			exact := *s
+			exact.Origin = e.Source
+			return &exact, nil
+		}
+	}
+	return is.remotes.get(r, ref)
+}
+
+// checkPin holds a remote skill to the commit and digest it was exported
+// with, as update holds it to its pin: a rewritten history or changed
+// content is shown and must be accepted.
+func (is *importSources) checkPin(e exportedSkill, s *skill.Skill) error {
+	if e.Commit == "" || (e.ContentHash == "" && e.FilesHash == "" && s.Commit == e.Commit) {
+		return nil
+	}
+	return is.pins.check(manifest.Installation{
+		SkillName:    e.Name,
+		SkillVersion: e.Version,
+		Source:       e.Source,
+		Commit:       e.Commit,
+		ContentHash:  e.ContentHash,
+		FilesHash:    e.FilesHash,
+	}, s)
+}
+
+func printImportResults(results []importResult) {
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.status]++
+		line := r.skill
+		if r.client != "" {
+			line += " -> " + r.client
+		}
+		if r.detail != "" {
+			line += " (" + r.detail + ")"
+		}
+		fmt.Printf("  %-9s %s\n", r.status, line)
+	}
+	var summary []string
+	for _, status := range []string{"installed", "skipped", "missing"} {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[status], status))
+	}
+	if counts["blocked"] > 0 {
+		summary = append(summary, fmt.Sprintf("%d blocked by policy", counts["blocked"]))
+	}
+	fmt.Printf("\n%s.\n", strings.Join(summary, ", "))
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestExportImport_ReproducesInstalls(t *testing.T) {
+	skillsRepo := t.TempDir()
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	refDir := filepath.Join(skillsRepo, "skill-a", "references")
+	if err := os.MkdirAll(refDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(refDir, "guide.md"), []byte("reference body"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldHome := t.TempDir()
+	t.Setenv("HOME", oldHome)
+	m, err := manifest.Load(filepath.Join(oldHome, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, inst := range []manifest.Installation{
+		{SkillName: "skill-a", SkillVersion: "1.0.0", ClientID: "codex", Options: &manifest.Options{IncludeRefs: true}},
+		{SkillName: "skill-a", SkillVersion: "1.0.0", ClientID: "claude", Options: &manifest.Options{IncludeRefs: true}},
+		{SkillName: "skill-b", SkillVersion: "2.0.0", ClientID: "codex"},
+	} {
+		inst.Scope, inst.Source, inst.InstalledAt, inst.UpdatedAt = "global", manifest.SourceLocal, now, now
+		inst.InstallPath = filepath.Join(oldHome, "."+inst.ClientID)
+		m.Add(inst)
+	}
+	if err := os.MkdirAll(filepath.Join(oldHome, ".aisk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	exported := captureStdout(t, func() {
+		if err := runExport(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(exported, `"claude",`) || !strings.Contains(exported, `"include_refs": true`) {
+		t.Fatalf("export should group clients and keep options:\n%s", exported)
+	}
+	exportPath := filepath.Join(t.TempDir(), "skills.json")
+	if err := os.WriteFile(exportPath, []byte(exported), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh machine with only Codex installed and without skill-b.
+	newHome := t.TempDir()
+	t.Setenv("HOME", newHome)
+	t.Setenv("PATH", "")
+	if err := os.MkdirAll(filepath.Join(newHome, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	orig := importFrom
+	t.Cleanup(func() { importFrom = orig })
+	importFrom = ""
+	out := captureStdout(t, func() {
+		if err := runImport(nil, []string{exportPath}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	flat := strings.Join(strings.Fields(out), " ")
+	for _, want := range []string{"installed skill-a -> Codex CLI", "skipped skill-a -> Claude Code (not detected on this system)", "missing skill-b", "1 installed, 1 skipped, 1 missing"} {
+		if !strings.Contains(flat, want) {
+			t.Fatalf("import output missing %q:\n%s", want, out)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(newHome, ".codex", "instructions.md"))
+	if err != nil || !strings.Contains(string(data), "reference body") {
+		t.Fatalf("expected skill-a with inlined references, got %q (%v)", data, err)
+	}
+	m, err = manifest.Load(filepath.Join(newHome, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := m.Find("skill-a", "codex")
+	if len(got) != 1 || got[0].Options == nil || !got[0].Options.IncludeRefs {
+		t.Fatalf("expected imported install with recorded options, got %+v", got)
+	}
+	if len(m.Installations) != 1 {
+		t.Fatalf("expected only the codex install to be recorded, got %+v", m.Installations)
+	}
+}
+
+func TestImportInstalls_ResolvesBySource(t *testing.T) {
+	home, repo := setupRemoteRepo(t)
+	repo.push("c1", "---\nname: r\ndescription: remote skill\nversion: 1.0.0\nallowed-tools: [Read, Bash]\n---\n# Remote\nfrom the repository\n", "")
+	// A local skill of the same name must never stand in for another source.
+	createTestSkill(t, os.Getenv("AISK_SKILLS_PATH"), "r", "9.0.0")
+
+	origFrom, origAccept, origPrompt := importFrom, importAcceptTools, confirmPrompt
+	t.Cleanup(func() { importFrom, importAcceptTools, confirmPrompt = origFrom, origAccept, origPrompt })
+	importFrom, importAcceptTools = "", false
+	confirmPrompt = func(string) (bool, error) { return false, errNoPrompt }
+
+	registrySource := filepath.Join(t.TempDir(), "index.json") + "#r@1.0.0"
+	exportPath := filepath.Join(t.TempDir(), "skills.json")
+	doc := `{"version": 1, "skills": [
+		{"name": "r", "version": "1.0.0", "source": "github.com/o/r@main", "clients": ["codex"]},
+		{"name": "r", "version": "1.0.0", "source": "` + registrySource + `", "clients": ["codex"]}
+	]}`
+	if err := os.WriteFile(exportPath, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// An installation here granting fewer tools is not expanded silently.
+	manifestPath := filepath.Join(home, ".aisk", "manifest.json")
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	m.Add(manifest.Installation{
+		SkillName: "r", SkillVersion: "0.9.0", ClientID: "codex", Scope: "global",
+		InstallPath: filepath.Join(home, ".codex", "instructions.md"), Source: "github.com/o/r@main",
+		InstalledAt: now, UpdatedAt: now, AllowedTools: []string{"Read"},
+	})
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	out := captureStdout(t, func() {
+		if err := runImport(nil, []string{exportPath}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	flat := strings.Join(strings.Fields(out), " ")
+	if !strings.Contains(flat, "skipped r -> Codex CLI") || !strings.Contains(flat, "Bash") || !strings.Contains(flat, "missing r") {
+		t.Fatalf("expected the tool expansion to be refused and the registry entry reported missing:\n%s", out)
+	}
+
+	importAcceptTools = true
+	out = captureStdout(t, func() {
+		if err := runImport(nil, []string{exportPath}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(strings.Join(strings.Fields(out), " "), "installed r -> Codex CLI") {
+		t.Fatalf("expected the remote skill to be installed:\n%s", out)
+	}
+	if m, err = manifest.Load(manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	got := m.Find("r", "codex")
+	if len(got) != 1 || got[0].Source != "github.com/o/r@main" || got[0].Commit != "c1" || got[0].SkillVersion != "1.0.0" {
+		t.Fatalf("expected the pinned remote install, got %+v", got)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "instructions.md"))
+	if err != nil || !strings.Contains(string(data), "from the repository") {
+		t.Fatalf("expected the remote skill content, got %q (%v)", data, err)
+	}
+
+	// A denied source is refused before the host is contacted.
+	if err := os.WriteFile(filepath.Join(home, ".aisk", "policy.yaml"), []byte("sources:\n  deny: [\"github.com/o/*\"]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before := repo.requests.Load()
+	out = captureStdout(t, func() {
+		if err := runImport(nil, []string{exportPath}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "sources.deny") {
+		t.Fatalf("expected the remote entry to be blocked:\n%s", out)
+	}
+	if n := repo.requests.Load(); n != before {
+		t.Fatalf("a denied source was contacted %d time(s)", n-before)
+	}
+}
+
+func TestExportImport_PinsRemoteCommit(t *testing.T) {
+	home, repo := setupRemoteRepo(t)
+	origFrom, origTools, origChanges, origPrompt := importFrom, importAcceptTools, importAcceptChanges, confirmPrompt
+	t.Cleanup(func() {
+		importFrom, importAcceptTools, importAcceptChanges, confirmPrompt = origFrom, origTools, origChanges, origPrompt
+	})
+	importFrom, importAcceptTools, importAcceptChanges = "", false, false
+	confirmPrompt = func(string) (bool, error) { return false, errNoPrompt }
+
+	manifestPath := filepath.Join(home, ".aisk", "manifest.json")
+	instructions := filepath.Join(home, ".codex", "instructions.md")
+	importDoc := func(doc string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "skills.json")
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range []string{manifestPath, instructions} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+		}
+		out := captureStdout(t, func() {
+			if err := runImport(nil, []string{path}); err != nil {
+				t.Fatal(err)
+			}
+		})
+		return strings.Join(strings.Fields(out), " ")
+	}
+	installed := func() manifest.Installation {
+		t.Helper()
+		m, err := manifest.Load(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := m.Find("r", "codex")
+		if len(got) != 1 {
+			t.Fatalf("expected one installation, got %+v", got)
+		}
+		return got[0]
+	}
+
+	repo.push("c1", pinnedSkillMD("1.0.0", "first"), "")
+	importDoc(`{"version": 1, "skills": [{"name": "r", "version": "1.0.0", "source": "github.com/o/r@main", "clients": ["codex"]}]}`)
+	exported := captureStdout(t, func() {
+		if err := runExport(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(exported, `"commit": "c1"`) || !strings.Contains(exported, `"files_hash": "sha256:`) {
+		t.Fatalf("export should pin the commit and content:\n%s", exported)
+	}
+
+	// The branch moved on; import still installs what was exported.
+	repo.push("c2", pinnedSkillMD("1.1.0", "second"), "ahead")
+	if out := importDoc(exported); !strings.Contains(out, "installed r -> Codex CLI") {
+		t.Fatalf("expected the exported commit to install:\n%s", out)
+	}
+	if inst := installed(); inst.Commit != "c1" || inst.Source != "github.com/o/r@main" || inst.SkillVersion != "1.0.0" {
+		t.Fatalf("expected the install pinned at c1 on main, got %+v", inst)
+	}
+	if data, _ := os.ReadFile(instructions); !strings.Contains(string(data), "first") {
+		t.Fatalf("expected the exported content, got %q", data)
+	}
+
+	// Once the commit is gone, the ref is fetched and held like an update.
+	repo.mu.Lock()
+	delete(repo.history, "c1")
+	repo.mu.Unlock()
+	repo.push("c3", pinnedSkillMD("1.1.0", "rewritten"), "diverged")
+	if out := importDoc(exported); !strings.Contains(out, "skipped r") || !strings.Contains(out, "--accept-changes") {
+		t.Fatalf("expected the rewritten source to be held:\n%s", out)
+	}
+	importAcceptChanges = true
+	if out := importDoc(exported); !strings.Contains(out, "installed r -> Codex CLI") {
+		t.Fatalf("expected --accept-changes to install:\n%s", out)
+	}
+	if inst := installed(); inst.Commit != "c3" {
+		t.Fatalf("expected the accepted commit, got %+v", inst)
+	}
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <path>",
+	Short: "Reproduce exported installs, or convert a client rule into a new skill",
+	Long: `Without --from, reproduce the installs in a file written by "aisk export":
+each skill is resolved from the source it was installed from (the skills
+repo, a remote repository, a registry version or a package) and installed
+globally to the listed clients detected on this machine. Missing skills and
+clients are reported and skipped.
+
+With --from, convert a Cursor .mdc rule, Windsurf rule, Copilot instructions
+file or CLAUDE.md section into a new skill directory in the skills repo.
+
+Rule frontmatter is carried over: description becomes the skill description,
+globs (or Copilot applyTo) become globs, and alwaysApply (or a Windsurf
+always_on trigger) becomes always-apply.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+var (
+	importFrom    string
+	importName    string
+	importSection string
+	importPath    string
+
+	importAcceptTools   bool
+	importAcceptChanges bool
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importFrom, "from", "", "rule format: cursor, windsurf, copilot, or claude (omit to import an aisk export)")
+	importCmd.Flags().StringVar(&importName, "name", "", "skill name (default: derived from the file name or section)")
+	importCmd.Flags().StringVar(&importSection, "section", "", "convert only the level-1 section with this heading")
+	importCmd.Flags().StringVar(&importPath, "path", "", "parent directory for the new skill (default: skills repo path)")
+	importCmd.Flags().BoolVar(&importAcceptTools, "accept-tools", false, "accept tool permissions imported skills request beyond those of the installed versions")
+	importCmd.Flags().BoolVar(&importAcceptChanges, "accept-changes", false, "accept remote skills whose exported commit is gone or whose content differs from the export")
+}
+
+func runImport(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "import")
+	al.Log("command.import", "started", map[string]any{
+		"args":           args,
+		"from":           importFrom,
+		"name":           importName,
+		"section":        importSection,
+		"path":           importPath,
+		"accept_tools":   importAcceptTools,
+		"accept_changes": importAcceptChanges,
+	}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.import", status, nil, retErr)
+	}()
+
+	if importFrom == "" {
+		if importName != "" || importSection != "" || importPath != "" {
+			return fmt.Errorf("--name, --section and --path need --from")
+		}
+		return runImportInstalls(paths, al, args[0])
+	}
+
+	format := skill.ParseRuleFormat(importFrom)
+	if format == "" {
+		return fmt.Errorf("--from must be one of: cursor, windsurf, copilot, claude")
+	}
+
+	src := args[0]
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("reading rule: %w", err)
+	}
+
+	name := importName
+	if name == "" {
+		if importSection != "" {
+			name = skill.SuggestName(importSection)
+		} else {
+			name = skill.SuggestName(src)
+		}
+	}
+	if format == skill.RuleClaude && importSection == "" && strings.EqualFold(name, "claude") {
+		return fmt.Errorf("importing a whole CLAUDE.md needs --name or --section")
+	}
+
+	fm, body, err := skill.ConvertRule(format, string(data), name, importSection)
+	if err != nil {
+		return fmt.Errorf("converting %s: %w", src, err)
+	}
+	if fm.Description == "" {
+		fm.Description = "TODO — describe what this skill does"
+	}
+
+	parentDir := importPath
+	if parentDir == "" {
+		parentDir = paths.SkillsRepo
+	}
+	skillDir, err := skill.WriteConverted(parentDir, fm, body)
+	if err != nil {
+		al.Log("import.write", "error", map[string]any{"name": fm.Name, "path": parentDir}, err)
+		return err
+	}
+	al.Log("import.write", "success", map[string]any{"name": fm.Name, "path": skillDir, "source": src, "from": string(format)}, nil)
+
+	fmt.Printf("Imported %s as skill %q at %s\n", src, fm.Name, skillDir)
+	report, err := skill.LintSkillDir(skillDir)
+	if err == nil && len(report.Results) > 0 {
+		fmt.Println("Review before installing:")
+		printLintResults(report, "  ")
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -13,12 +15,15 @@ import (
 	"github.com/yorch/aisk/internal/config"
 	"github.com/yorch/aisk/internal/gitignore"
 	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/policy"
+	"github.com/yorch/aisk/internal/registry"
 	"github.com/yorch/aisk/internal/skill"
 	"github.com/yorch/aisk/internal/tui"
+	"github.com/yorch/aisk/internal/txn"
 )
 
 var installCmd = &cobra.Command{
-	Use:   "install [skill]",
+	Use:   "install [skill | [host/]owner/repo[@ref] | package.tar.gz]",
 	Short: "Install a skill to one or more AI clients",
 	Args:  cobra.MaximumNArgs(1),
 	RunE:  runInstall,
@@ -29,13 +34,23 @@ var (
 	installScope       string
 	installIncludeRefs bool
 	installDryRun      bool
+	installAtomic      bool
+	installRegistry    string
+	installRequireSig  bool
+	installAcceptTools bool
+	installScan        bool
 )
 
 func init() {
-	installCmd.Flags().StringVar(&installClient, "client", "", "target client (claude, gemini, codex, copilot, cursor, windsurf)")
+	installCmd.Flags().StringVar(&installClient, "client", "", "target client(s), comma-separated (claude, gemini, codex, copilot, cursor, windsurf)")
 	installCmd.Flags().StringVar(&installScope, "scope", "global", "installation scope (global or project)")
 	installCmd.Flags().BoolVar(&installIncludeRefs, "include-refs", false, "inline reference files in output")
 	installCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "show what would be done without making changes")
+	installCmd.Flags().BoolVar(&installAtomic, "atomic", false, "install to all clients or none; roll back on any failure")
+	installCmd.Flags().StringVar(&installRegistry, "registry", "", "install from a skill registry (URL or directory; also AISK_REGISTRY); accepts name@version")
+	installCmd.Flags().BoolVar(&installRequireSig, "require-signature", false, "refuse skills not signed by a trusted key (also AISK_REQUIRE_SIGNATURE)")
+	installCmd.Flags().BoolVar(&installScan, "scan", false, "scan the skill for secrets and unsafe content and refuse it on errors (also AISK_SCAN)")
+	installCmd.Flags().BoolVar(&installAcceptTools, "accept-tools", false, "accept tool permissions the skill requests beyond those of the installed version")
 }
 
 func runInstall(_ *cobra.Command, args []string) (retErr error) {
@@ -50,6 +65,11 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 		"scope":        installScope,
 		"include_refs": installIncludeRefs,
 		"dry_run":      installDryRun,
+		"atomic":       installAtomic,
+		"registry":     installRegistry,
+		"require_sig":  installRequireSig,
+		"accept_tools": installAcceptTools,
+		"scan":         installScan,
 	}, nil)
 	defer func() {
 		status := "success"
@@ -66,36 +86,39 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 		return err
 	}
 
-	// Discover available skills
-	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	pol, err := loadPolicy(paths)
 	if err != nil {
-		return fmt.Errorf("scanning skills: %w", err)
+		return err
 	}
 
-	if len(skills) == 0 {
-		return fmt.Errorf("no skills found in %s", paths.SkillsRepo)
+	// Resolving the target can fill cache entries, which cache clean removes
+	// under the same lock.
+	lock, err := acquireManifestLock(paths, al)
+	if err != nil {
+		return err
+	}
+	defer releaseManifestLock(lock, al)
+	if !installDryRun {
+		recoverTransactions(paths, al)
 	}
 
-	// Resolve skill — TUI if no argument
-	var target *skill.Skill
-	if len(args) == 0 {
-		selected, err := tui.RunSkillSelect(skills)
-		if err != nil {
+	target, err := resolveInstallTarget(paths, al, pol, args)
+	if err != nil {
+		return err
+	}
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return err
+	}
+	if err := checkSignature(store, al, target, signatureRequired(installRequireSig, pol)); err != nil {
+		return err
+	}
+	if scanRequested(installScan, pol) {
+		if err := checkScan(al, target); err != nil {
 			return err
 		}
-		target = selected
-	} else {
-		skillArg := args[0]
-		for _, s := range skills {
-			if s.DirName == skillArg || s.Frontmatter.Name == skillArg {
-				target = s
-				break
-			}
-		}
-		if target == nil {
-			return fmt.Errorf("skill %q not found", skillArg)
-		}
 	}
+	printToolPermissions(target)
 
 	// Detect clients
 	reg := client.NewRegistry()
@@ -119,15 +142,24 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 		}
 		targetClients = selected
 	} else {
-		clientID := client.ParseClientID(installClient)
-		if clientID == "" {
-			return fmt.Errorf("unknown client %q (valid: claude, gemini, codex, copilot, cursor, windsurf)", installClient)
+		for _, raw := range strings.Split(installClient, ",") {
+			clientID := client.ParseClientID(strings.TrimSpace(raw))
+			if clientID == "" {
+				return fmt.Errorf("unknown client %q (valid: claude, gemini, codex, copilot, cursor, windsurf)", raw)
+			}
+			c := reg.Get(clientID)
+			if !c.Detected {
+				return fmt.Errorf("client %s not detected on this system", c.Name)
+			}
+			targetClients = append(targetClients, c)
 		}
-		c := reg.Get(clientID)
-		if !c.Detected {
-			return fmt.Errorf("client %s not detected on this system", c.Name)
+	}
+
+	// The policy must allow every client before any is touched.
+	for _, c := range targetClients {
+		if err := checkPolicy(pol, al, target, string(c.ID), installScope); err != nil {
+			return err
 		}
-		targetClients = []*client.Client{c}
 	}
 
 	// Ensure dirs for manifest
@@ -147,23 +179,26 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 	}
 
 	// Install to each selected client
-	lock := manifest.NewLock(paths.ManifestDB)
-	al.Log("manifest.lock", "started", map[string]any{"path": paths.ManifestDB + ".lock"}, nil)
-	if err := lock.Acquire(5 * time.Second); err != nil {
-		al.Log("manifest.lock", "error", nil, err)
-		fmt.Fprintf(os.Stderr, "warning: could not acquire lock: %v\n", err)
-	} else {
-		al.Log("manifest.lock", "success", nil, nil)
-		defer lock.Release()
-		defer al.Log("manifest.lock", "released", nil, nil)
-	}
 
-	m, err := manifest.Load(paths.ManifestDB)
+	m, err := loadManifests(paths, manifest.ViewDefault, al)
 	if err != nil {
-		al.Log("manifest.load", "error", nil, err)
-		return fmt.Errorf("loading manifest: %w", err)
+		return err
+	}
+
+	// Reinstalling must not quietly grant tools the installed version lacked.
+	if !installDryRun {
+		var existing []manifest.Installation
+		for _, c := range targetClients {
+			for _, inst := range m.Find(target.Frontmatter.Name, string(c.ID)) {
+				if inst.Scope == installScope {
+					existing = append(existing, inst)
+				}
+			}
+		}
+		if err := newToolConfirmer(pol, al, installAcceptTools).check(target, existing...); err != nil {
+			return err
+		}
 	}
-	al.Log("manifest.load", "success", map[string]any{"installations": len(m.Installations)}, nil)
 
 	opts := adapter.InstallOpts{
 		Scope:       installScope,
@@ -171,8 +206,38 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 		DryRun:      installDryRun,
 	}
 
+	hash := skillContentHash(target)
+	filesHash := skillFilesHash(target)
+
+	var tx *txn.Tx
+	if installAtomic && !installDryRun {
+		// All-or-nothing: refuse up front rather than skip clients part way.
+		for _, c := range targetClients {
+			if resolveTargetPath(c, installScope) == "" {
+				return fmt.Errorf("%s does not support %s scope", c.Name, installScope)
+			}
+			if _, err := adapter.ForClient(c.ID); err != nil {
+				return fmt.Errorf("no adapter for %s: %w", c.Name, err)
+			}
+		}
+		tx, err = txn.Begin(paths.TxnDir, "install")
+		if err != nil {
+			return fmt.Errorf("starting transaction: %w", err)
+		}
+		al.Log("install.txn", "started", map[string]any{"id": tx.ID()}, nil)
+	}
+	rollback := func(cause error) error {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			al.Log("install.txn", "error", map[string]any{"id": tx.ID()}, rbErr)
+			return fmt.Errorf("%w; rollback failed: %v (will retry on next run)", cause, rbErr)
+		}
+		al.Log("install.txn", "rolled_back", map[string]any{"id": tx.ID()}, cause)
+		return fmt.Errorf("%w; rolled back all changes", cause)
+	}
+
 	var installed int
-	var successfulProjectClients []*client.Client
+	var jobs []applyJob
+	var jobItems []int // progress item (and target client) of each job
 	for i, c := range targetClients {
 		targetPath := resolveTargetPath(c, installScope)
 		if targetPath == "" {
@@ -224,18 +289,67 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 			continue
 		}
 
-		progressItems[i].Status = tui.StatusActive
-		al.LogEvent(audit.Event{
-			Action:   "install.adapter.apply",
-			Status:   "started",
-			Skill:    target.Frontmatter.Name,
-			ClientID: string(c.ID),
-			Scope:    installScope,
-			Target:   targetPath,
+		output := adapterOutputPath(c.ID, installScope, target, targetPath)
+		if tx != nil {
+			if err := trackForWrite(tx, output); err != nil {
+				return rollback(fmt.Errorf("journaling %s: %w", c.Name, err))
+			}
+		}
+		jobs = append(jobs, applyJob{
+			key: output,
+			run: func() error {
+				al.LogEvent(audit.Event{
+					Action:   "install.adapter.apply",
+					Status:   "started",
+					Skill:    target.Frontmatter.Name,
+					ClientID: string(c.ID),
+					Scope:    installScope,
+					Target:   targetPath,
+				})
+				return adp.Install(target, targetPath, opts)
+			},
 		})
-		if err := adp.Install(target, targetPath, opts); err != nil {
-			progressItems[i].Status = tui.StatusError
-			progressItems[i].Detail = err.Error()
+		jobItems = append(jobItems, i)
+	}
+
+	var errs []error
+	if len(jobs) > 0 {
+		progress := startProgress(fmt.Sprintf("Installing %q", target.Frontmatter.Name), progressItems)
+		errs = runJobs(jobs, tx != nil, func(j int, status tui.ProgressStatus, err error) {
+			detail := ""
+			if err != nil {
+				detail = err.Error()
+			}
+			progress.Set(jobItems[j], status, detail)
+		})
+		progress.Stop()
+	}
+
+	// An atomic install rolls back if any client failed; clients that were
+	// never started because of it are not failures of their own.
+	if tx != nil {
+		for j, err := range errs {
+			if err != nil && !errors.Is(err, errNotStarted) {
+				al.LogEvent(audit.Event{
+					Action:   "install.adapter.apply",
+					Status:   "error",
+					Skill:    target.Frontmatter.Name,
+					ClientID: string(targetClients[jobItems[j]].ID),
+					Scope:    installScope,
+					Target:   resolveTargetPath(targetClients[jobItems[j]], installScope),
+					Error:    err.Error(),
+				})
+				return rollback(fmt.Errorf("installing to %s: %w", targetClients[jobItems[j]].Name, err))
+			}
+		}
+	}
+
+	var successfulProjectClients []*client.Client
+	for j, err := range errs {
+		i := jobItems[j]
+		c := targetClients[i]
+		targetPath := resolveTargetPath(c, installScope)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "  error installing to %s: %v\n", c.Name, err)
 			al.LogEvent(audit.Event{
 				Action:   "install.adapter.apply",
@@ -254,7 +368,7 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 			manifestPath = filepath.Join(projectRoot, targetPath)
 		}
 
-		m.Add(manifest.Installation{
+		if err := m.Add(manifest.Installation{
 			SkillName:    target.Frontmatter.Name,
 			SkillVersion: target.DisplayVersion(),
 			ClientID:     string(c.ID),
@@ -262,9 +376,20 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 			InstalledAt:  time.Now(),
 			UpdatedAt:    time.Now(),
 			InstallPath:  manifestPath,
-		})
+			Source:       target.SourceName(),
+			ContentHash:  hash,
+			FilesHash:    filesHash,
+			Options:      installOptions(opts),
+			AllowedTools: grantedTools(target),
+			Commit:       target.Commit,
+		}); err != nil {
+			err = fmt.Errorf("recording %s: %w", c.Name, err)
+			if tx != nil {
+				return rollback(err)
+			}
+			return err
+		}
 
-		progressItems[i].Status = tui.StatusDone
 		installed++
 		if installScope == "project" {
 			successfulProjectClients = append(successfulProjectClients, c)
@@ -280,11 +405,27 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 	}
 
 	if !installDryRun {
+		if tx != nil {
+			for _, f := range m.Files() {
+				if err := trackForWrite(tx, f); err != nil {
+					return rollback(fmt.Errorf("journaling manifest: %w", err))
+				}
+			}
+		}
 		if err := m.Save(); err != nil {
 			al.Log("manifest.save", "error", nil, err)
+			if tx != nil {
+				return rollback(fmt.Errorf("saving manifest: %w", err))
+			}
 			return fmt.Errorf("saving manifest: %w", err)
 		}
-		al.Log("manifest.save", "success", map[string]any{"installations": len(m.Installations)}, nil)
+		al.Log("manifest.save", "success", map[string]any{"installations": len(m.Installations())}, nil)
+	}
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not clean up transaction %s: %v\n", tx.ID(), err)
+		}
+		al.Log("install.txn", "committed", map[string]any{"id": tx.ID()}, nil)
 	}
 
 	// Manage .gitignore for project-scope installs
@@ -294,14 +435,152 @@ func runInstall(_ *cobra.Command, args []string) (retErr error) {
 		al.Log("gitignore.update", "success", nil, nil)
 	}
 
-	// Print progress summary
-	fmt.Println()
-	tui.PrintProgress(fmt.Sprintf("Installing %q", target.Frontmatter.Name), progressItems)
+	// The live view has already shown the outcome of each client; dry runs
+	// and runs with nothing to apply get the static summary.
+	if len(jobs) == 0 {
+		fmt.Println()
+		tui.PrintProgress(fmt.Sprintf("Installing %q", target.Frontmatter.Name), progressItems)
+	}
 	fmt.Printf("\n%d client(s) done.\n", installed)
 
 	return nil
 }
 
+// resolveInstallTarget returns the skill named by the install argument: a
+// package file written by aisk pack, a remote repository, a skill of the
+// local repository or the registry, or the TUI selection when there is no
+// argument. A remote source the policy denies is refused before it is
+// fetched.
+func resolveInstallTarget(paths config.Paths, al *audit.Logger, pol *policy.Set, args []string) (*skill.Skill, error) {
+	if len(args) > 0 {
+		if r, ref, ok := parseRemoteArg(args[0]); ok {
+			if err := checkSourcePolicy(pol, al, r.Repo, skill.RemoteSource(r, ref)); err != nil {
+				return nil, err
+			}
+			opts, err := remoteFetchOptions(paths)
+			if err != nil {
+				return nil, err
+			}
+			return newRemoteCache(al, opts).get(r, ref)
+		}
+	}
+	if len(args) > 0 && isArchivePath(args[0]) {
+		target, err := skill.LoadArchive(args[0], paths.CacheDir)
+		if err != nil {
+			al.Log("archive.load", "error", map[string]any{"path": args[0]}, err)
+			return nil, err
+		}
+		al.Log("archive.load", "success", map[string]any{"path": args[0], "skill": target.DirName, "version": target.Version}, nil)
+		return target, nil
+	}
+
+	// Discover available skills: the local repository, plus the registry
+	// when one is configured. An explicit --registry installs from it only.
+	var skills []*skill.Skill
+	var err error
+	if installRegistry == "" {
+		skills, err = skill.ScanLocal(paths.SkillsRepo)
+		if err != nil {
+			return nil, fmt.Errorf("scanning skills: %w", err)
+		}
+	}
+	var catalog *registry.Registry
+	if location := registryLocation(installRegistry); location != "" {
+		catalog, err = loadRegistry(paths, location)
+		if err != nil {
+			if installRegistry != "" {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		} else {
+			skills = append(skills, catalog.Skills()...)
+		}
+	}
+
+	if len(skills) == 0 {
+		if installRegistry != "" {
+			return nil, fmt.Errorf("registry %s lists no skills", installRegistry)
+		}
+		return nil, fmt.Errorf("no skills found in %s", paths.SkillsRepo)
+	}
+
+	// Resolve skill — TUI if no argument
+	var target *skill.Skill
+	if len(args) == 0 {
+		selected, err := tui.RunSkillSelect(skills)
+		if err != nil {
+			return nil, err
+		}
+		target = selected
+	} else {
+		skillArg, version, _ := strings.Cut(args[0], "@")
+		if version == "" {
+			target = findSkillByArg(skills, skillArg)
+		}
+		if target == nil && catalog != nil {
+			// name@version, or a name only the registry has
+			entry, err := catalog.Find(skillArg, version)
+			if err != nil {
+				return nil, err
+			}
+			target = &skill.Skill{DirName: entry.Dir, Source: skill.SourceRegistry, Origin: catalog.Source(entry)}
+			target.Version = entry.Version
+			target.Name = entry.Name
+		}
+		if target == nil {
+			if version != "" {
+				return nil, fmt.Errorf("skill %q not found (versions can only be chosen from a registry)", args[0])
+			}
+			return nil, fmt.Errorf("skill %q not found", skillArg)
+		}
+	}
+
+	// Registry listings carry metadata only; download the chosen version.
+	if target.Source == skill.SourceRegistry && target.Path == "" {
+		entry, err := catalog.Find(target.DirName, target.Version)
+		if err != nil {
+			return nil, err
+		}
+		fetched, err := catalog.Fetch(entry)
+		if err != nil {
+			al.Log("registry.fetch", "error", map[string]any{"skill": entry.Dir, "version": entry.Version}, err)
+			return nil, err
+		}
+		al.Log("registry.fetch", "success", map[string]any{"skill": entry.Dir, "version": entry.Version, "source": fetched.Origin}, nil)
+		target = fetched
+	}
+	return target, nil
+}
+
+// isArchivePath reports whether an install argument names a package file
+// rather than a skill.
+func isArchivePath(arg string) bool {
+	if !strings.HasSuffix(arg, ".tar.gz") && !strings.HasSuffix(arg, ".tgz") {
+		return false
+	}
+	info, err := os.Stat(arg)
+	return err == nil && !info.IsDir()
+}
+
+// loadArchiveSource reloads the package an installation recorded as its
+// source, failing when the file is gone or now holds another skill.
+func loadArchiveSource(paths config.Paths, al *audit.Logger, inst manifest.Installation) (*skill.Skill, error) {
+	path := strings.TrimPrefix(inst.Source, skill.ArchiveSource(""))
+	if !isArchivePath(path) {
+		return nil, fmt.Errorf("package %s no longer exists", path)
+	}
+	s, err := skill.LoadArchive(path, paths.CacheDir)
+	if err != nil {
+		al.Log("archive.load", "error", map[string]any{"path": path}, err)
+		return nil, err
+	}
+	al.Log("archive.load", "success", map[string]any{"path": path, "skill": s.DirName, "version": s.Version}, nil)
+	if s.Frontmatter.Name != inst.SkillName {
+		return nil, fmt.Errorf("package %s now holds %s", path, s.Frontmatter.Name)
+	}
+	return s, nil
+}
+
 func validateInstallNonInteractive(args []string) error {
 	if !assumeYes {
 		return nil
@@ -345,6 +624,62 @@ func manageGitignoreOnInstall(clients []*client.Client) {
 	}
 }
 
+// skillContentHash returns the digest recorded in the manifest for s, or ""
+// when the skill directory cannot be read.
+func skillContentHash(s *skill.Skill) string {
+	h, err := skill.DirHash(s.Path)
+	if err != nil {
+		return ""
+	}
+	return h
+}
+
+// skillFilesHash returns the digest of every file of s that pins a remote
+// installation, or "" when it cannot be computed.
+func skillFilesHash(s *skill.Skill) string {
+	h, err := skill.FilesHash(s.Path)
+	if err != nil {
+		return ""
+	}
+	return h
+}
+
+// installOptions returns the manifest form of opts, or nil for defaults.
+func installOptions(opts adapter.InstallOpts) *manifest.Options {
+	if !opts.IncludeRefs {
+		return nil
+	}
+	return &manifest.Options{IncludeRefs: true}
+}
+
+// optionOverrides replace recorded install options for one update run.
+type optionOverrides struct {
+	includeRefs   bool
+	noIncludeRefs bool
+}
+
+func addOptionOverrideFlags(cmd *cobra.Command, o *optionOverrides) {
+	cmd.Flags().BoolVar(&o.includeRefs, "include-refs", false, "inline reference files, overriding the recorded option")
+	cmd.Flags().BoolVar(&o.noIncludeRefs, "no-include-refs", false, "stop inlining reference files, overriding the recorded option")
+	cmd.MarkFlagsMutuallyExclusive("include-refs", "no-include-refs")
+}
+
+// replayOptions rebuilds the install options recorded for inst, so an update
+// reproduces the original install, then applies any overrides.
+func replayOptions(inst manifest.Installation, o optionOverrides) adapter.InstallOpts {
+	opts := adapter.InstallOpts{Scope: inst.Scope}
+	if inst.Options != nil {
+		opts.IncludeRefs = inst.Options.IncludeRefs
+	}
+	switch {
+	case o.includeRefs:
+		opts.IncludeRefs = true
+	case o.noIncludeRefs:
+		opts.IncludeRefs = false
+	}
+	return opts
+}
+
 func resolveTargetPath(c *client.Client, scope string) string {
 	switch scope {
 	case "global":
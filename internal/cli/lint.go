@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -54,6 +55,11 @@ func runLint(_ *cobra.Command, args []string) error {
 			return fmt.Errorf("reading %s: %w", target, err)
 		}
 		report = skill.LintSkillMD(string(data))
+		for _, f := range skill.ScanContent(filepath.Base(target), data) {
+			if !f.Suppressed {
+				report.Results = append(report.Results, f.LintResult())
+			}
+		}
 	}
 
 	if len(report.Results) == 0 {
@@ -62,20 +68,7 @@ func runLint(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	errStyle := lipgloss.NewStyle().Foreground(tui.Red)
-	warnStyle := lipgloss.NewStyle().Foreground(tui.Yellow)
-
-	for _, r := range report.Results {
-		prefix := warnStyle.Render("warning")
-		if r.Severity == skill.SeverityError {
-			prefix = errStyle.Render("error")
-		}
-		field := r.Field
-		if field != "" {
-			field = "[" + field + "] "
-		}
-		fmt.Printf("  %s: %s%s\n", prefix, field, r.Message)
-	}
+	printLintResults(report, "  ")
 
 	errs := report.Errors()
 	warns := report.Warnings()
@@ -103,3 +96,21 @@ func runLint(_ *cobra.Command, args []string) error {
 	}, nil)
 	return nil
 }
+
+// printLintResults prints each finding on its own line with a colored severity.
+func printLintResults(report *skill.LintReport, indent string) {
+	errStyle := lipgloss.NewStyle().Foreground(tui.Red)
+	warnStyle := lipgloss.NewStyle().Foreground(tui.Yellow)
+
+	for _, r := range report.Results {
+		prefix := warnStyle.Render("warning")
+		if r.Severity == skill.SeverityError {
+			prefix = errStyle.Render("error")
+		}
+		field := r.Field
+		if field != "" {
+			field = "[" + field + "] "
+		}
+		fmt.Printf("%s%s: %s%s\n", indent, prefix, field, r.Message)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -20,15 +19,17 @@ var listCmd = &cobra.Command{
 }
 
 var (
-	listRemote bool
-	listJSON   bool
-	listRepo   string
+	listRemote   bool
+	listJSON     bool
+	listRepo     string
+	listRegistry string
 )
 
 func init() {
 	listCmd.Flags().BoolVar(&listRemote, "remote", false, "also fetch remote skills from GitHub")
 	listCmd.Flags().BoolVar(&listJSON, "json", false, "output as JSON")
-	listCmd.Flags().StringVar(&listRepo, "repo", "", "GitHub repo to fetch from (owner/repo)")
+	listCmd.Flags().StringVar(&listRepo, "repo", "", "GitHub repo to fetch from (owner/repo or host/owner/repo)")
+	listCmd.Flags().StringVar(&listRegistry, "registry", "", "also list skills from a registry (URL or directory; also AISK_REGISTRY)")
 }
 
 func runList(_ *cobra.Command, _ []string) (retErr error) {
@@ -38,9 +39,10 @@ func runList(_ *cobra.Command, _ []string) (retErr error) {
 	}
 	al := audit.New(paths.AiskDir, "list")
 	al.Log("command.list", "started", map[string]any{
-		"remote": listRemote,
-		"repo":   listRepo,
-		"json":   listJSON,
+		"remote":   listRemote,
+		"repo":     listRepo,
+		"json":     listJSON,
+		"registry": listRegistry,
 	}, nil)
 	defer func() {
 		status := "success"
@@ -63,18 +65,27 @@ func runList(_ *cobra.Command, _ []string) (retErr error) {
 			repo = os.Getenv("AISK_REMOTE_REPO")
 		}
 		if repo != "" {
-			parts := strings.SplitN(repo, "/", 2)
-			if len(parts) == 2 {
-				al.Log("list.remote.fetch", "started", map[string]any{"repo": repo}, nil)
-				fmt.Fprintf(os.Stderr, "Fetching skills from %s...\n", repo)
-				remote, err := skill.FetchRemoteList(parts[0], parts[1])
-				if err != nil {
-					al.Log("list.remote.fetch", "error", map[string]any{"repo": repo}, err)
-					fmt.Fprintf(os.Stderr, "warning: remote fetch failed: %v\n", err)
-				} else {
-					skills = append(skills, remote...)
-					al.Log("list.remote.fetch", "success", map[string]any{"repo": repo, "count": len(remote)}, nil)
-				}
+			ref, ok := skill.ParseRepoURL(repo)
+			if !ok {
+				return fmt.Errorf("invalid repository %q: expected owner/repo or host/owner/repo", repo)
+			}
+			opts, err := remoteFetchOptions(paths)
+			if err != nil {
+				return err
+			}
+			al.Log("list.remote.fetch", "started", map[string]any{"repo": ref.String(), "offline": opts.Offline}, nil)
+			if opts.Offline {
+				fmt.Fprintf(os.Stderr, "Listing cached skills from %s (offline)...\n", ref)
+			} else {
+				fmt.Fprintf(os.Stderr, "Fetching skills from %s...\n", ref)
+			}
+			remote, err := skill.FetchRemoteList(ref, opts)
+			if err != nil {
+				al.Log("list.remote.fetch", "error", map[string]any{"repo": ref.String()}, err)
+				fmt.Fprintf(os.Stderr, "warning: remote fetch failed: %v\n", err)
+			} else {
+				skills = append(skills, remote...)
+				al.Log("list.remote.fetch", "success", map[string]any{"repo": ref.String(), "count": len(remote)}, nil)
 			}
 		} else {
 			al.Log("list.remote.fetch", "skipped", map[string]any{"reason": "missing repo"}, nil)
@@ -82,6 +93,8 @@ func runList(_ *cobra.Command, _ []string) (retErr error) {
 		}
 	}
 
+	skills = appendRegistrySkills(paths, al, skills, listRegistry)
+
 	if len(skills) == 0 {
 		fmt.Println("No skills found.")
 		fmt.Printf("Set AISK_SKILLS_PATH or run from a directory containing skill folders.\n")
@@ -95,6 +108,23 @@ func runList(_ *cobra.Command, _ []string) (retErr error) {
 	return printSkillsTable(skills)
 }
 
+// appendRegistrySkills adds the newest version of each skill in the registry
+// named by flag or AISK_REGISTRY. A registry that cannot be read is a warning.
+func appendRegistrySkills(paths config.Paths, al *audit.Logger, skills []*skill.Skill, flag string) []*skill.Skill {
+	location := registryLocation(flag)
+	if location == "" {
+		return skills
+	}
+	reg, err := loadRegistry(paths, location)
+	if err != nil {
+		al.Log("registry.load", "error", map[string]any{"registry": location}, err)
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		return skills
+	}
+	al.Log("registry.load", "success", map[string]any{"registry": reg.Location, "count": len(reg.Index.Skills)}, nil)
+	return append(skills, reg.Skills()...)
+}
+
 func printSkillsTable(skills []*skill.Skill) error {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintf(w, "NAME\tVERSION\tDIRECTORY\tSOURCE\n")
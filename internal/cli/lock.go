@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+// acquireManifestLock takes the manifest lock, waiting up to --wait for
+// another aisk process to finish. Failing to lock is an error: commands must
+// not modify client files or the manifest without holding it.
+func acquireManifestLock(paths config.Paths, al *audit.Logger) (*manifest.Lock, error) {
+	if err := paths.EnsureDirs(); err != nil {
+		return nil, err
+	}
+	lock := manifest.NewLock(paths.ManifestDB)
+	al.Log("manifest.lock", "started", map[string]any{"path": paths.ManifestDB + ".lock", "wait": lockWait.String()}, nil)
+	if err := lock.Acquire(lockWait); err != nil {
+		al.Log("manifest.lock", "error", nil, err)
+		return nil, err
+	}
+	al.Log("manifest.lock", "success", nil, nil)
+	return lock, nil
+}
+
+// releaseManifestLock releases a lock taken by acquireManifestLock.
+func releaseManifestLock(lock *manifest.Lock, al *audit.Logger) {
+	lock.Release()
+	al.Log("manifest.lock", "released", nil, nil)
+}
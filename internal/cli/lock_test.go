@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+// TestHelperProcess runs the aisk CLI when invoked as a subprocess by
+// runAiskProcess; it is skipped in normal test runs.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("AISK_TEST_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	rootCmd.SetArgs(strings.Split(os.Getenv("AISK_TEST_ARGS"), "\x1f"))
+	if err := Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func runAiskProcess(home, skillsRepo string, args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(),
+		"AISK_TEST_HELPER=1",
+		"AISK_TEST_ARGS="+strings.Join(args, "\x1f"),
+		"HOME="+home,
+		"AISK_SKILLS_PATH="+skillsRepo,
+		"AISK_AUDIT_ENABLED=false",
+	)
+	cmd.Dir = home
+	return cmd
+}
+
+func TestConcurrentInstallUpdateUninstall(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns aisk subprocesses")
+	}
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	const skills = 6
+	for i := range skills {
+		createTestSkill(t, skillsRepo, fmt.Sprintf("skill-%d", i), "1.0.0")
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := runAiskProcess(home, skillsRepo, "install", "skill-0", "--client", "codex", "--yes").CombinedOutput(); err != nil {
+		t.Fatalf("initial install failed: %v\n%s", err, out)
+	}
+
+	var cmds [][]string
+	for i := 1; i < skills; i++ {
+		cmds = append(cmds, []string{"install", fmt.Sprintf("skill-%d", i), "--client", "codex", "--yes", "--wait", "30s"})
+	}
+	cmds = append(cmds,
+		[]string{"update", "--wait", "30s"},
+		[]string{"uninstall", "skill-0", "--wait", "30s"},
+	)
+
+	var wg sync.WaitGroup
+	failures := make(chan string, len(cmds))
+	for _, args := range cmds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out, err := runAiskProcess(home, skillsRepo, args...).CombinedOutput(); err != nil {
+				failures <- fmt.Sprintf("aisk %s: %v\n%s", strings.Join(args, " "), err, out)
+			}
+		}()
+	}
+	wg.Wait()
+	close(failures)
+	for f := range failures {
+		t.Error(f)
+	}
+
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "instructions.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < skills; i++ {
+		name := fmt.Sprintf("skill-%d", i)
+		if len(m.Find(name, "codex")) != 1 {
+			t.Errorf("manifest lost installation of %s: %+v", name, m.Installations)
+		}
+		if strings.Count(string(data), "<!-- aisk:start:"+name+" -->") != 1 {
+			t.Errorf("instructions.md should contain exactly one %s section:\n%s", name, data)
+		}
+	}
+	if len(m.Find("skill-0", "codex")) != 0 || strings.Contains(string(data), "aisk:start:skill-0") {
+		t.Errorf("skill-0 should be uninstalled:\n%s", data)
+	}
+}
+
+func TestRunInstall_FailsWhenLocked(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+	held := manifest.NewLock(paths.ManifestDB)
+	if err := held.Acquire(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	defer held.Release()
+
+	origClient, origScope, origDryRun, origWait := installClient, installScope, installDryRun, lockWait
+	t.Cleanup(func() {
+		installClient, installScope, installDryRun, lockWait = origClient, origScope, origDryRun, origWait
+	})
+	installClient, installScope, installDryRun, lockWait = "codex", "global", false, 100*time.Millisecond
+
+	err = runInstall(nil, []string{"skill-a"})
+	if !errors.Is(err, manifest.ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".codex", "instructions.md")); !os.IsNotExist(err) {
+		t.Errorf("nothing should be installed without the lock, stat err = %v", err)
+	}
+}
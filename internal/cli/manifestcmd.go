@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Maintain the installation manifest",
+}
+
+var manifestMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade manifest.json to the current schema version",
+	Long: `Upgrade manifest.json to the current schema version. Older manifests are
+also migrated automatically the next time a command saves the manifest; this
+command does it explicitly and shows each change. The original file is kept
+as manifest.json.v<N>.bak. Project installations recorded in the global
+manifest are moved to <project>/.aisk/manifest.json.`,
+	Args: cobra.NoArgs,
+	RunE: runManifestMigrate,
+}
+
+var manifestMigrateDryRun bool
+
+func init() {
+	manifestMigrateCmd.Flags().BoolVar(&manifestMigrateDryRun, "dry-run", false, "show the changes without writing")
+	manifestCmd.AddCommand(manifestMigrateCmd)
+}
+
+func runManifestMigrate(_ *cobra.Command, _ []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "manifest")
+	al.Log("command.manifest.migrate", "started", map[string]any{"dry_run": manifestMigrateDryRun}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.manifest.migrate", status, nil, retErr)
+	}()
+
+	if !manifestMigrateDryRun {
+		lock, err := acquireManifestLock(paths, al)
+		if err != nil {
+			return err
+		}
+		defer releaseManifestLock(lock, al)
+	}
+
+	set, err := loadManifests(paths, manifest.ViewAllProjects, al)
+	if err != nil {
+		return err
+	}
+	m := set.Global
+	report := m.Migration()
+	if report == nil {
+		fmt.Printf("Manifest is already at schema version %d.\n", manifest.SchemaVersion)
+		return nil
+	}
+
+	fmt.Printf("Schema version %d -> %d\n", report.From, report.To)
+	for _, step := range report.Steps {
+		fmt.Printf("  %s\n", step)
+	}
+	if len(report.Changes) > 0 {
+		fmt.Println("\nChanges:")
+		for _, c := range report.Changes {
+			fmt.Printf("  %s\n", c)
+		}
+	}
+
+	if manifestMigrateDryRun {
+		fmt.Println("\n[dry-run] manifest not modified")
+		return nil
+	}
+
+	backup := m.BackupPath()
+	if err := set.Save(); err != nil {
+		return fmt.Errorf("saving manifest: %w", err)
+	}
+	al.Log("manifest.migrate", "success", map[string]any{
+		"from":    report.From,
+		"to":      report.To,
+		"changes": len(report.Changes),
+		"moved":   set.Moved(),
+		"backup":  backup,
+	}, nil)
+	fmt.Printf("\nMigrated %s (backup: %s)\n", paths.ManifestDB, backup)
+	return nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/config"
+)
+
+func TestRunManifestMigrate_DryRunThenApply(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := paths.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"installations":[{"skill_name":"a","skill_version":"1.0.0","client_id":"codex","scope":"global","install_path":"/x"}]}`
+	if err := os.WriteFile(paths.ManifestDB, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := manifestMigrateDryRun
+	t.Cleanup(func() { manifestMigrateDryRun = orig })
+
+	manifestMigrateDryRun = true
+	out := captureStdout(t, func() {
+		if err := runManifestMigrate(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "Schema version 1 -> 3") || !strings.Contains(out, `source set to "local"`) || !strings.Contains(out, "[dry-run]") {
+		t.Fatalf("unexpected dry-run output:\n%s", out)
+	}
+	if data, _ := os.ReadFile(paths.ManifestDB); string(data) != legacy {
+		t.Fatal("dry run must not modify the manifest")
+	}
+
+	manifestMigrateDryRun = false
+	captureStdout(t, func() {
+		if err := runManifestMigrate(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(paths.AiskDir, "manifest.json.v1.bak")); err != nil {
+		t.Fatalf("expected backup: %v", err)
+	}
+	out = captureStdout(t, func() {
+		if err := runManifestMigrate(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "already at schema version 3") {
+		t.Fatalf("expected no-op on second run, got:\n%s", out)
+	}
+}
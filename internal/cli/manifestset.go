@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+// manifestViewFlags selects which manifests status, update and uninstall
+// operate on. With none set they combine global installations with those of
+// the current project.
+type manifestViewFlags struct {
+	project     bool
+	global      bool
+	allProjects bool
+}
+
+func addManifestViewFlags(cmd *cobra.Command, f *manifestViewFlags) {
+	cmd.Flags().BoolVar(&f.project, "project", false, "only the current project's installations")
+	cmd.Flags().BoolVar(&f.global, "global", false, "only global installations")
+	cmd.Flags().BoolVar(&f.allProjects, "all-projects", false, "global installations and those of every known project")
+	cmd.MarkFlagsMutuallyExclusive("project", "global", "all-projects")
+}
+
+func (f manifestViewFlags) view() manifest.View {
+	switch {
+	case f.project:
+		return manifest.ViewProject
+	case f.global:
+		return manifest.ViewGlobal
+	case f.allProjects:
+		return manifest.ViewAllProjects
+	}
+	return manifest.ViewDefault
+}
+
+// loadManifests loads the global manifest together with the project
+// manifests view needs. Commands that modify the result must hold the
+// manifest lock, which covers project manifests too.
+func loadManifests(paths config.Paths, view manifest.View, al *audit.Logger) (*manifest.Set, error) {
+	cwd, _ := os.Getwd()
+	set, err := manifest.LoadSet(manifest.SetConfig{
+		GlobalPath:  paths.ManifestDB,
+		IndexPath:   paths.ProjectsDB,
+		ProjectRoot: config.FindProjectRoot(cwd),
+		View:        view,
+	})
+	if err != nil {
+		al.Log("manifest.load", "error", nil, err)
+		return nil, fmt.Errorf("loading manifest: %w", err)
+	}
+	for _, root := range set.Missing {
+		fmt.Fprintf(os.Stderr, "warning: project %s no longer has a manifest (moved or deleted?)\n", root)
+	}
+	al.Log("manifest.load", "success", map[string]any{
+		"installations": len(set.Installations()),
+		"moved":         set.Moved(),
+	}, nil)
+	return set, nil
+}
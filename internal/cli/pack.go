@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/fsutil"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+var packCmd = &cobra.Command{
+	Use:   "pack <skill|path>",
+	Short: "Package a skill as a reproducible <name>-<version>.tar.gz",
+	Long: `Lint a skill of the skills repository (or the skill directory at path) and
+write it as <name>-<version>.tar.gz. Entries are sorted and carry fixed
+timestamps, so packing the same contents always gives the same file, and the
+archive embeds a manifest of every file's SHA-256 digest.
+
+Install the package with "aisk install ./<name>-<version>.tar.gz". Lint
+errors or a missing version stop packing.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPack,
+}
+
+var packOut string
+
+func init() {
+	packCmd.Flags().StringVarP(&packOut, "out", "o", ".", "directory to write the package to")
+}
+
+func runPack(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "pack")
+	al.Log("command.pack", "started", map[string]any{"target": args[0], "out": packOut}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.pack", status, nil, retErr)
+	}()
+
+	target, err := resolveSkillOrDir(paths, args[0])
+	if err != nil {
+		return err
+	}
+
+	report, err := skill.LintSkillDir(target.Path)
+	if err != nil {
+		return err
+	}
+	if len(report.Results) > 0 {
+		printLintResults(report, "  ")
+	}
+	if report.HasErrors() {
+		return fmt.Errorf("%s has %d lint error(s); fix them before packing", target.DirName, len(report.Errors()))
+	}
+	if target.Version == "" {
+		return fmt.Errorf("%s has no version; set one in SKILL.md before packing", target.DirName)
+	}
+	if err := skill.ValidateName(target.DirName); err != nil {
+		return fmt.Errorf("%s: directory %w", target.DirName, err)
+	}
+
+	var buf bytes.Buffer
+	if err := skill.PackSkill(target, &buf); err != nil {
+		return fmt.Errorf("packing %s: %w", target.DirName, err)
+	}
+	if err := os.MkdirAll(packOut, 0o755); err != nil {
+		return err
+	}
+	out := filepath.Join(packOut, skill.ArchiveName(target))
+	if err := fsutil.WriteFile(out, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", out, err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	digest := hex.EncodeToString(sum[:])
+	fmt.Printf("Packed %s %s to %s (%s)\n", target.DirName, target.Version, out, formatSize(int64(buf.Len())))
+	fmt.Printf("sha256 %s\n", digest)
+	al.Log("pack.write", "success", map[string]any{
+		"skill":   target.DirName,
+		"version": target.Version,
+		"path":    out,
+		"sha256":  digest,
+	}, nil)
+	return nil
+}
+
+// resolveSkillOrDir returns the skill named by arg in the skills repository,
+// or the skill directory at arg.
+func resolveSkillOrDir(paths config.Paths, arg string) (*skill.Skill, error) {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		dir, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+		return skill.LoadLocal(dir)
+	}
+	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	if err != nil {
+		return nil, fmt.Errorf("scanning skills: %w", err)
+	}
+	if s := findSkillByArg(skills, arg); s != nil {
+		return s, nil
+	}
+	return nil, fmt.Errorf("skill %q not found in %s", arg, paths.SkillsRepo)
+}
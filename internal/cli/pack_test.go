@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestPackThenInstallArchive(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	outDir := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origOut := packOut
+	origClient, origScope, origRefs, origDryRun, origRegistry := installClient, installScope, installIncludeRefs, installDryRun, installRegistry
+	t.Cleanup(func() {
+		packOut = origOut
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry = origClient, origScope, origRefs, origDryRun, origRegistry
+	})
+	packOut = outDir
+
+	createTestSkill(t, skillsRepo, "skill-a", "1.2.0")
+	out := captureStdout(t, func() {
+		if err := runPack(nil, []string{"skill-a"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	archive := filepath.Join(outDir, "skill-a-1.2.0.tar.gz")
+	if !strings.Contains(out, archive) || !strings.Contains(out, "sha256 ") {
+		t.Fatalf("unexpected pack output:\n%s", out)
+	}
+	first, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		if err := runPack(nil, []string{filepath.Join(skillsRepo, "skill-a")}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if second, _ := os.ReadFile(archive); string(second) != string(first) {
+		t.Fatal("packing twice should give identical archives")
+	}
+
+	// The repository moves on; the package still installs 1.2.0.
+	createTestSkill(t, skillsRepo, "skill-a", "2.0.0")
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry = "codex", "global", false, false, ""
+	captureStdout(t, func() {
+		if err := runInstall(nil, []string{archive}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := m.Find("skill-a", "codex")
+	if len(got) != 1 || got[0].SkillVersion != "1.2.0" || got[0].Source != "archive:"+archive {
+		t.Fatalf("unexpected installation %+v", got)
+	}
+
+	// Update reloads the recorded package rather than taking the local 2.0.0,
+	// and leaves the installation alone once the package is gone.
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	origUpdateClient, origOverrides := updateClient, updateOverrides
+	t.Cleanup(func() { updateClient, updateOverrides = origUpdateClient, origOverrides })
+	updateClient, updateOverrides = "", optionOverrides{}
+	for _, remove := range []bool{false, true} {
+		if remove {
+			if err := os.Remove(archive); err != nil {
+				t.Fatal(err)
+			}
+		}
+		captureStdout(t, func() {
+			if err := runUpdate(nil, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if m, err = manifest.Load(filepath.Join(home, ".aisk", "manifest.json")); err != nil {
+			t.Fatal(err)
+		}
+		got = m.Find("skill-a", "codex")
+		if len(got) != 1 || got[0].SkillVersion != "1.2.0" || got[0].Source != "archive:"+archive {
+			t.Fatalf("update replaced the packaged install (removed %v): %+v", remove, got)
+		}
+	}
+}
+
+func TestPack_RefusesLintErrors(t *testing.T) {
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	origOut := packOut
+	t.Cleanup(func() { packOut = origOut })
+	packOut = t.TempDir()
+
+	dir := filepath.Join(skillsRepo, "broken")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: broken\nversion: 1.0.0\n---\n# Broken\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	captureStdout(t, func() { err = runPack(nil, []string{"broken"}) })
+	if err == nil || !strings.Contains(err.Error(), "lint error") {
+		t.Fatalf("expected a lint failure, got %v", err)
+	}
+	if entries, _ := os.ReadDir(packOut); len(entries) != 0 {
+		t.Fatalf("nothing should be written, found %d file(s)", len(entries))
+	}
+}
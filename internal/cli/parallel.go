@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/yorch/aisk/internal/tui"
+)
+
+// maxWorkers bounds how many adapter writes run at once.
+const maxWorkers = 4
+
+// errNotStarted is reported for jobs skipped after an earlier job failed.
+var errNotStarted = errors.New("not started: an earlier client failed")
+
+// applyJob is one adapter write.
+type applyJob struct {
+	// key is the file or directory the job writes. Markdown adapters rewrite
+	// shared files such as AGENTS.md in place, so jobs with the same key run
+	// one after another, in order.
+	key string
+	run func() error
+}
+
+// runJobs runs jobs on a bounded worker pool and returns their errors,
+// indexed like jobs. report, if set, is called as each job starts and
+// finishes. With stopOnError, jobs that have not started when one fails are
+// not run and report errNotStarted.
+func runJobs(jobs []applyJob, stopOnError bool, report func(i int, status tui.ProgressStatus, err error)) []error {
+	if report == nil {
+		report = func(int, tui.ProgressStatus, error) {}
+	}
+
+	// Jobs sharing a key form a chain that one worker runs in order.
+	var chains [][]int
+	byKey := make(map[string]int)
+	for i, job := range jobs {
+		c, ok := byKey[job.key]
+		if !ok {
+			c = len(chains)
+			byKey[job.key] = c
+			chains = append(chains, nil)
+		}
+		chains[c] = append(chains[c], i)
+	}
+
+	errs := make([]error, len(jobs))
+	var (
+		mu     sync.Mutex
+		failed bool
+		wg     sync.WaitGroup
+	)
+	queue := make(chan []int)
+	workers := min(maxWorkers, len(chains))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chain := range queue {
+				for _, i := range chain {
+					mu.Lock()
+					skip := stopOnError && failed
+					mu.Unlock()
+					if skip {
+						errs[i] = errNotStarted
+						report(i, tui.StatusError, errNotStarted)
+						continue
+					}
+
+					report(i, tui.StatusActive, nil)
+					if err := jobs[i].run(); err != nil {
+						errs[i] = err
+						mu.Lock()
+						failed = true
+						mu.Unlock()
+						report(i, tui.StatusError, err)
+						continue
+					}
+					report(i, tui.StatusDone, nil)
+				}
+			}
+		}()
+	}
+	for _, chain := range chains {
+		queue <- chain
+	}
+	close(queue)
+	wg.Wait()
+	return errs
+}
+
+// startProgress reports progress live when stdout is a terminal and as plain
+// lines otherwise.
+func startProgress(title string, items []tui.ProgressItem) *tui.Progress {
+	return tui.StartProgress(title, items, stdoutIsTerminal())
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yorch/aisk/internal/tui"
+)
+
+func TestRunJobs_SerializesSharedOutputs(t *testing.T) {
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	perKey := make(map[string][]int)
+	active := make(map[string]bool)
+
+	var jobs []applyJob
+	for i := range 12 {
+		key := fmt.Sprintf("file-%d", i%3)
+		jobs = append(jobs, applyJob{key: key, run: func() error {
+			mu.Lock()
+			if active[key] {
+				mu.Unlock()
+				return fmt.Errorf("%s written concurrently", key)
+			}
+			active[key] = true
+			perKey[key] = append(perKey[key], i)
+			mu.Unlock()
+
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+
+			mu.Lock()
+			active[key] = false
+			mu.Unlock()
+			return nil
+		}})
+	}
+
+	var reports atomic.Int32
+	errs := runJobs(jobs, false, func(int, tui.ProgressStatus, error) { reports.Add(1) })
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("job %d: %v", i, err)
+		}
+	}
+	if peak.Load() < 2 || peak.Load() > maxWorkers {
+		t.Fatalf("expected jobs on different files to run concurrently within the pool bound, peak %d", peak.Load())
+	}
+	for key, order := range perKey {
+		for j := 1; j < len(order); j++ {
+			if order[j] < order[j-1] {
+				t.Fatalf("jobs for %s ran out of order: %v", key, order)
+			}
+		}
+	}
+	if reports.Load() != int32(2*len(jobs)) {
+		t.Fatalf("expected a start and finish report per job, got %d", reports.Load())
+	}
+}
+
+func TestRunJobs_StopOnError(t *testing.T) {
+	boom := errors.New("boom")
+	var ran atomic.Int32
+	jobs := []applyJob{
+		{key: "a", run: func() error { ran.Add(1); return boom }},
+		{key: "a", run: func() error { ran.Add(1); return nil }},
+	}
+	errs := runJobs(jobs, true, nil)
+	if !errors.Is(errs[0], boom) || !errors.Is(errs[1], errNotStarted) || ran.Load() != 1 {
+		t.Fatalf("expected the second job to be skipped, got %v (ran %d)", errs, ran.Load())
+	}
+
+	errs = runJobs(jobs, false, nil)
+	if !errors.Is(errs[0], boom) || errs[1] != nil {
+		t.Fatalf("without stopOnError every job should run, got %v", errs)
+	}
+}
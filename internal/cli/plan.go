@@ -47,6 +47,7 @@ var (
 	planInstallScope       string
 	planInstallIncludeRefs bool
 	planUpdateClient       string
+	planUpdateOverrides    optionOverrides
 	planUninstallClient    string
 )
 
@@ -56,6 +57,7 @@ func init() {
 	planInstallCmd.Flags().BoolVar(&planInstallIncludeRefs, "include-refs", false, "inline reference files in output")
 
 	planUpdateCmd.Flags().StringVar(&planUpdateClient, "client", "", "specific client to update")
+	addOptionOverrideFlags(planUpdateCmd, &planUpdateOverrides)
 	planUninstallCmd.Flags().StringVar(&planUninstallClient, "client", "", "specific client to uninstall from")
 
 	planCmd.AddCommand(planInstallCmd)
@@ -155,9 +157,9 @@ func runPlanUpdate(_ *cobra.Command, args []string) (retErr error) {
 		al.Log("command.plan", status, map[string]any{"mode": "update"}, retErr)
 	}()
 
-	m, err := manifest.Load(paths.ManifestDB)
+	m, err := loadManifests(paths, manifest.ViewDefault, al)
 	if err != nil {
-		return fmt.Errorf("loading manifest: %w", err)
+		return err
 	}
 
 	skills, err := skill.ScanLocal(paths.SkillsRepo)
@@ -180,7 +182,7 @@ func runPlanUpdate(_ *cobra.Command, args []string) (retErr error) {
 			}
 		}
 	} else {
-		targets = m.Installations
+		targets = m.Installations()
 		if planUpdateClient != "" {
 			targets = m.FindByClient(planUpdateClient)
 		}
@@ -206,7 +208,7 @@ func runPlanUpdate(_ *cobra.Command, args []string) (retErr error) {
 			continue
 		}
 
-		opts := adapter.InstallOpts{Scope: inst.Scope}
+		opts := replayOptions(inst, planUpdateOverrides)
 		desc := adp.Describe(s, inst.InstallPath, opts)
 		op := inferInstallOperation(clientID, inst.InstallPath, s, inst.Scope)
 		versionNote := "no version change"
@@ -216,6 +218,9 @@ func runPlanUpdate(_ *cobra.Command, args []string) (retErr error) {
 
 		fmt.Printf("- %s on %s (%s): %s [%s]\n", inst.SkillName, inst.ClientID, inst.Scope, op, versionNote)
 		fmt.Printf("  adapter: %s\n", desc)
+		if opts.IncludeRefs {
+			fmt.Println("  options: include-refs")
+		}
 	}
 
 	return nil
@@ -242,9 +247,9 @@ func runPlanUninstall(_ *cobra.Command, args []string) (retErr error) {
 	}()
 
 	skillArg := args[0]
-	m, err := manifest.Load(paths.ManifestDB)
+	m, err := loadManifests(paths, manifest.ViewDefault, al)
 	if err != nil {
-		return fmt.Errorf("loading manifest: %w", err)
+		return err
 	}
 
 	installations := m.Find(skillArg, planUninstallClient)
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/policy"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/trust"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the install policy",
+	Long: `Policies restrict which clients, scopes and sources skills may be installed
+to and from, whether they must be signed, their size and the allowed-tools
+they may request. They are read from the system policy (AISK_SYSTEM_POLICY or
+/etc/aisk/policy.yaml), ~/.aisk/policy.yaml and <project>/.aisk/policy.yaml;
+every one that exists must permit an install.`,
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check <skill|path|package.tar.gz>",
+	Short: "Evaluate the policy for installing a skill without installing it",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicyCheck,
+}
+
+var (
+	policyCheckClient string
+	policyCheckScope  string
+)
+
+func init() {
+	policyCheckCmd.Flags().StringVar(&policyCheckClient, "client", "", "client(s) to check, comma-separated (default: all)")
+	policyCheckCmd.Flags().StringVar(&policyCheckScope, "scope", "global", "installation scope to check (global or project)")
+	policyCmd.AddCommand(policyCheckCmd)
+}
+
+// policyFiles returns the policy files that apply here, most general first.
+func policyFiles(paths config.Paths) []policy.File {
+	files := []policy.File{
+		{Path: policy.SystemPath(), Level: policy.LevelSystem},
+		{Path: filepath.Join(paths.AiskDir, policy.FileName), Level: policy.LevelUser},
+	}
+	cwd, _ := os.Getwd()
+	if root := config.FindProjectRoot(cwd); root != "" {
+		files = append(files, policy.File{Path: filepath.Join(root, manifest.ProjectDir, policy.FileName), Level: policy.LevelProject})
+	}
+	return files
+}
+
+func loadPolicy(paths config.Paths) (*policy.Set, error) {
+	return policy.Load(policyFiles(paths)...)
+}
+
+// checkPolicy evaluates the policy for installing s to a client and scope,
+// logging and returning the rule that blocks it.
+func checkPolicy(pol *policy.Set, al *audit.Logger, s *skill.Skill, clientID, scope string) error {
+	req, err := policyRequest(pol, s, clientID, scope)
+	if err != nil {
+		return err
+	}
+	v := pol.Check(req)
+	if v == nil {
+		return nil
+	}
+	al.LogEvent(audit.Event{
+		Action:   "policy.check",
+		Status:   "error",
+		Skill:    s.DirName,
+		ClientID: clientID,
+		Scope:    scope,
+		Details:  map[string]any{"policy": v.Path, "rule": v.Rule, "source": req.Source},
+		Error:    v.Error(),
+	})
+	return v
+}
+
+// checkSourcePolicy refuses a source the policy blocks before anything is
+// fetched from it, so a denied host is never contacted.
+func checkSourcePolicy(pol *policy.Set, al *audit.Logger, skillName, source string) error {
+	v := pol.CheckSource(source)
+	if v == nil {
+		return nil
+	}
+	al.LogEvent(audit.Event{
+		Action:  "policy.check",
+		Status:  "error",
+		Skill:   skillName,
+		Details: map[string]any{"policy": v.Path, "rule": v.Rule, "source": source},
+		Error:   v.Error(),
+	})
+	return v
+}
+
+// policyRequest describes installing s for pol. The skill is only measured
+// when a policy limits its size.
+func policyRequest(pol *policy.Set, s *skill.Skill, clientID, scope string) (policy.Request, error) {
+	var size int64
+	if pol.LimitsSize() {
+		var err error
+		if size, err = skill.DirSize(s.Path); err != nil {
+			return policy.Request{}, fmt.Errorf("reading %s: %w", s.DirName, err)
+		}
+	}
+	return policy.Request{
+		Skill:        s.DirName,
+		Client:       clientID,
+		Scope:        scope,
+		Source:       s.SourceName(),
+		Size:         size,
+		AllowedTools: s.Frontmatter.AllowedTools,
+	}, nil
+}
+
+func runPolicyCheck(_ *cobra.Command, args []string) error {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "policy")
+	pol, err := loadPolicy(paths)
+	if err != nil {
+		return err
+	}
+
+	var target *skill.Skill
+	if isArchivePath(args[0]) {
+		target, err = skill.LoadArchive(args[0], paths.CacheDir)
+	} else {
+		target, err = resolveSkillOrDir(paths, args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	clientIDs := client.AllClientIDs
+	if policyCheckClient != "" {
+		clientIDs = nil
+		for _, raw := range strings.Split(policyCheckClient, ",") {
+			id := client.ParseClientID(strings.TrimSpace(raw))
+			if id == "" {
+				return fmt.Errorf("unknown client %q (valid: claude, gemini, codex, copilot, cursor, windsurf)", raw)
+			}
+			clientIDs = append(clientIDs, id)
+		}
+	}
+
+	if len(pol.Policies) == 0 {
+		fmt.Println("No policy files found; everything is allowed.")
+	} else {
+		fmt.Println("Policies:")
+		for _, p := range pol.Policies {
+			fmt.Printf("  %s (%s)\n", p.Path, p.Level)
+		}
+	}
+	fmt.Printf("Skill: %s %s (source %s)\n", target.DirName, target.DisplayVersion(), target.SourceName())
+	unsigned := false
+	if pol.RequireSignature() {
+		store, err := loadTrustStore(paths)
+		if err != nil {
+			return err
+		}
+		res, err := trust.Verify(target.Path, store)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Signature: %s (required)\n", res.Status)
+		if res.Status != trust.Verified {
+			unsigned = true
+		}
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tSCOPE\tRESULT\tRULE")
+	blocked := 0
+	for _, id := range clientIDs {
+		req, err := policyRequest(pol, target, string(id), policyCheckScope)
+		if err != nil {
+			return err
+		}
+		if v := pol.Check(req); v != nil {
+			blocked++
+			fmt.Fprintf(w, "%s\t%s\tblocked\t%s in %s: %s\n", id, policyCheckScope, v.Rule, v.Path, v.Message)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\tallowed\t\n", id, policyCheckScope)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	al.Log("policy.check", "success", map[string]any{
+		"skill":    target.DirName,
+		"scope":    policyCheckScope,
+		"clients":  len(clientIDs),
+		"blocked":  blocked,
+		"unsigned": unsigned,
+		"policies": len(pol.Policies),
+	}, nil)
+	if unsigned {
+		return fmt.Errorf("the policy requires a signature by a trusted key")
+	}
+	if blocked > 0 {
+		return fmt.Errorf("%d of %d install(s) blocked by policy", blocked, len(clientIDs))
+	}
+	return nil
+}
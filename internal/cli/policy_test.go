@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolicyBlocksInstallAndCheck(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".aisk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	policyFile := filepath.Join(home, ".aisk", "policy.yaml")
+	if err := os.WriteFile(policyFile, []byte("clients:\n  deny: [codex]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origClient, origScope, origRefs, origDryRun, origRegistry, origRequire := installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig
+	origCheckClient, origCheckScope := policyCheckClient, policyCheckScope
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig = origClient, origScope, origRefs, origDryRun, origRegistry, origRequire
+		policyCheckClient, policyCheckScope = origCheckClient, origCheckScope
+	})
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig = "codex", "global", false, false, "", false
+
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	var err error
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "clients.deny") || !strings.Contains(err.Error(), policyFile) {
+		t.Fatalf("expected the policy to block codex, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(home, ".codex", "instructions.md")); !os.IsNotExist(statErr) {
+		t.Fatal("nothing should be written when the policy blocks an install")
+	}
+
+	policyCheckClient, policyCheckScope = "claude,codex", "global"
+	out := captureStdout(t, func() { err = runPolicyCheck(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Fatalf("policy check error = %v", err)
+	}
+	if !strings.Contains(out, "claude") || !strings.Contains(out, "allowed") || !strings.Contains(out, "blocked") {
+		t.Fatalf("unexpected policy check output:\n%s", out)
+	}
+
+	// require_signature in the policy applies like --require-signature.
+	if err := os.WriteFile(policyFile, []byte("require_signature: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Fatalf("expected the policy to require a signature, got %v", err)
+	}
+
+	// Hidden files count towards max_skill_size.
+	if err := os.WriteFile(policyFile, []byte("max_skill_size: 1KB\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(skillsRepo, "skill-a", ".payload"), []byte(strings.Repeat("x", 4096)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "max_skill_size") {
+		t.Fatalf("expected the hidden payload to exceed the size limit, got %v", err)
+	}
+}
+
+func TestInstallSymlinkedReferenceWithoutPolicy(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SCAN", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origClient, origScope, origRefs, origDryRun, origRegistry, origRequire := installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig = origClient, origScope, origRefs, origDryRun, origRegistry, origRequire
+	})
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig = "codex", "global", true, false, "", false
+
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	shared := filepath.Join(skillsRepo, "common.md")
+	if err := os.WriteFile(shared, []byte("shared reference"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(skillsRepo, "skill-a", "reference"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(shared, filepath.Join(skillsRepo, "skill-a", "reference", "common.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	var err error
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil {
+		t.Fatalf("a symlinked reference must install without a policy, got %v", err)
+	}
+
+	// A size limit measures what the link points at.
+	if err := os.WriteFile(filepath.Join(home, ".aisk", "policy.yaml"), []byte("max_skill_size: 10B\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "max_skill_size") {
+		t.Fatalf("expected the size limit to apply, got %v", err)
+	}
+}
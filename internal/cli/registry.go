@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/registry"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Build static skill registries",
+}
+
+var registryBuildCmd = &cobra.Command{
+	Use:   "build <dir>",
+	Short: "Pack the skills repository into a static registry in <dir>",
+	Long: `Pack every versioned skill of the skills repository (AISK_SKILLS_PATH or
+--from) into <dir>/skills/ and write <dir>/index.json listing each skill
+version with its archive URL and SHA-256 digest. Serve <dir> from any static
+web host, or use it as a local directory, with --registry or AISK_REGISTRY.
+
+Versions already in <dir>/index.json are kept. Publishing a version again
+with different contents fails unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRegistryBuild,
+}
+
+var (
+	registryBuildFrom  string
+	registryBuildForce bool
+)
+
+func init() {
+	registryBuildCmd.Flags().StringVar(&registryBuildFrom, "from", "", "skills repository to pack (default: AISK_SKILLS_PATH or the current directory)")
+	registryBuildCmd.Flags().BoolVar(&registryBuildForce, "force", false, "replace versions already published with different contents")
+	registryCmd.AddCommand(registryBuildCmd)
+}
+
+// registryLocation returns the registry named by a --registry flag, or
+// AISK_REGISTRY when the flag is empty.
+func registryLocation(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return os.Getenv("AISK_REGISTRY")
+}
+
+func loadRegistry(paths config.Paths, location string) (*registry.Registry, error) {
+	return registry.Load(location, registry.Options{CacheDir: paths.CacheDir, Offline: offlineMode()})
+}
+
+// registrySources resolves installation sources recorded from a registry,
+// loading each index and fetching each version at most once per command.
+type registrySources struct {
+	paths      config.Paths
+	al         *audit.Logger
+	registries map[string]*registry.Registry
+	skills     map[string]*skill.Skill
+	errs       map[string]error
+}
+
+func newRegistrySources(paths config.Paths, al *audit.Logger) *registrySources {
+	return &registrySources{
+		paths:      paths,
+		al:         al,
+		registries: make(map[string]*registry.Registry),
+		skills:     make(map[string]*skill.Skill),
+		errs:       make(map[string]error),
+	}
+}
+
+// latest returns the newest version of the skill an installation recorded
+// as source, from the same registry.
+func (rs *registrySources) latest(source string) (*skill.Skill, error) {
+	location, dir, _, ok := registry.ParseSource(source)
+	if !ok {
+		return nil, fmt.Errorf("invalid registry source %q", source)
+	}
+	return rs.get(location, dir, "")
+}
+
+// exact returns the version of the skill recorded in source.
+func (rs *registrySources) exact(source string) (*skill.Skill, error) {
+	location, dir, version, ok := registry.ParseSource(source)
+	if !ok {
+		return nil, fmt.Errorf("invalid registry source %q", source)
+	}
+	return rs.get(location, dir, version)
+}
+
+func (rs *registrySources) get(location, dir, version string) (*skill.Skill, error) {
+	key := location + "#" + dir + "@" + version
+	if s, ok := rs.skills[key]; ok {
+		return s, nil
+	}
+	if err, ok := rs.errs[key]; ok {
+		return nil, err
+	}
+	s, err := rs.fetch(location, dir, version)
+	if err != nil {
+		rs.errs[key] = err
+		return nil, err
+	}
+	rs.skills[key] = s
+	return s, nil
+}
+
+func (rs *registrySources) fetch(location, dir, version string) (*skill.Skill, error) {
+	reg, ok := rs.registries[location]
+	if !ok {
+		var err error
+		if reg, err = loadRegistry(rs.paths, location); err != nil {
+			return nil, err
+		}
+		rs.registries[location] = reg
+	}
+	entry, err := reg.Find(dir, version)
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := reg.Fetch(entry)
+	if err != nil {
+		rs.al.Log("registry.fetch", "error", map[string]any{"skill": entry.Dir, "version": entry.Version}, err)
+		return nil, err
+	}
+	rs.al.Log("registry.fetch", "success", map[string]any{"skill": entry.Dir, "version": entry.Version, "source": fetched.Origin}, nil)
+	return fetched, nil
+}
+
+func runRegistryBuild(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	from := registryBuildFrom
+	if from == "" {
+		from = paths.SkillsRepo
+	}
+	al := audit.New(paths.AiskDir, "registry")
+	al.Log("command.registry.build", "started", map[string]any{"from": from, "out": args[0], "force": registryBuildForce}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.registry.build", status, nil, retErr)
+	}()
+
+	res, err := registry.Build(from, args[0], registry.BuildOptions{Force: registryBuildForce})
+	if err != nil {
+		return err
+	}
+	for _, e := range res.Added {
+		fmt.Printf("  added      %s %s (%s)\n", e.Dir, e.Version, formatSize(e.Size))
+	}
+	for _, e := range res.Unchanged {
+		fmt.Printf("  unchanged  %s %s\n", e.Dir, e.Version)
+	}
+	for _, dir := range res.Skipped {
+		fmt.Printf("  skipped    %s (no version in SKILL.md)\n", dir)
+	}
+	fmt.Printf("\nWrote %d version(s) of %d skill(s) to %s/%s\n",
+		len(res.Index.Skills), countSkills(res.Index), args[0], registry.IndexFile)
+	return nil
+}
+
+func countSkills(idx registry.Index) int {
+	dirs := make(map[string]bool)
+	for _, e := range idx.Skills {
+		dirs[e.Dir] = true
+	}
+	return len(dirs)
+}
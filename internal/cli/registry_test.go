@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestRegistryBuildThenInstallVersion(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	regDir := filepath.Join(t.TempDir(), "registry")
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origFrom, origForce := registryBuildFrom, registryBuildForce
+	origClient, origScope, origRefs, origDryRun, origRegistry := installClient, installScope, installIncludeRefs, installDryRun, installRegistry
+	origSearchRegistry := searchRegistry
+	t.Cleanup(func() {
+		registryBuildFrom, registryBuildForce = origFrom, origForce
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry = origClient, origScope, origRefs, origDryRun, origRegistry
+		searchRegistry = origSearchRegistry
+	})
+	registryBuildFrom, registryBuildForce = "", false
+
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	captureStdout(t, func() {
+		if err := runRegistryBuild(nil, []string{regDir}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	createTestSkill(t, skillsRepo, "skill-a", "2.0.0")
+	out := captureStdout(t, func() {
+		if err := runRegistryBuild(nil, []string{regDir}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "added      skill-a 2.0.0") || !strings.Contains(out, "2 version(s) of 1 skill(s)") {
+		t.Fatalf("unexpected build output:\n%s", out)
+	}
+
+	searchRegistry = regDir
+	out = captureStdout(t, func() {
+		if err := runSearch(nil, []string{"SKILL-A"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "registry") || !strings.Contains(out, "local") {
+		t.Fatalf("search should find the local and the registry skill:\n%s", out)
+	}
+
+	// The local repository now holds 2.0.0; the registry still serves 1.0.0.
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry = "codex", "global", false, false, regDir
+	captureStdout(t, func() {
+		if err := runInstall(nil, []string{"skill-a@1.0.0"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := m.Find("skill-a", "codex")
+	if len(got) != 1 || got[0].SkillVersion != "1.0.0" || !strings.HasSuffix(got[0].Source, "index.json#skill-a@1.0.0") {
+		t.Fatalf("unexpected installation %+v", got)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "instructions.md"))
+	if err != nil || !strings.Contains(string(data), "aisk:start:skill-a") {
+		t.Fatalf("skill not written: %v\n%s", err, data)
+	}
+
+	// Update follows the registry to its newest version, never the local
+	// skill of the same name.
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	origUpdateClient, origOverrides := updateClient, updateOverrides
+	t.Cleanup(func() { updateClient, updateOverrides = origUpdateClient, origOverrides })
+	updateClient, updateOverrides = "", optionOverrides{}
+	createTestSkill(t, skillsRepo, "skill-a", "3.0.0")
+	captureStdout(t, func() {
+		if err := runUpdate(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if m, err = manifest.Load(filepath.Join(home, ".aisk", "manifest.json")); err != nil {
+		t.Fatal(err)
+	}
+	got = m.Find("skill-a", "codex")
+	if len(got) != 1 || got[0].SkillVersion != "2.0.0" || !strings.HasSuffix(got[0].Source, "index.json#skill-a@2.0.0") {
+		t.Fatalf("expected the registry's 2.0.0, got %+v", got)
+	}
+
+	// Without the registry the installation is left alone.
+	if err := os.RemoveAll(regDir); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		if err := runUpdate(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if m, err = manifest.Load(filepath.Join(home, ".aisk", "manifest.json")); err != nil {
+		t.Fatal(err)
+	}
+	if got = m.Find("skill-a", "codex"); len(got) != 1 || got[0].SkillVersion != "2.0.0" {
+		t.Fatalf("an unreachable registry must not swap in the local skill, got %+v", got)
+	}
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/yorch/aisk/internal/config"
 )
@@ -15,7 +17,16 @@ Each client gets skills in its native format via dedicated adapters.`,
 	Version: config.AppVersion,
 }
 
-var assumeYes bool
+var (
+	assumeYes bool
+	lockWait  time.Duration
+	offline   bool
+)
+
+// offlineMode reports whether --offline or AISK_OFFLINE is set.
+func offlineMode() bool {
+	return offline || config.Offline()
+}
 
 // Execute runs the root command.
 func Execute() error {
@@ -24,15 +35,32 @@ func Execute() error {
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "disable interactive prompts; require explicit inputs")
+	rootCmd.PersistentFlags().DurationVar(&lockWait, "wait", 5*time.Second, "how long to wait for another aisk process to release the manifest lock")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "use only cached remote data; never contact GitHub (also AISK_OFFLINE)")
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(installCmd)
 	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(adoptCmd)
 	rootCmd.AddCommand(planCmd)
 	rootCmd.AddCommand(clientsCmd)
 	rootCmd.AddCommand(createCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(devCmd)
 	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(packCmd)
+	rootCmd.AddCommand(signCmd)
+	rootCmd.AddCommand(trustCmd)
+	rootCmd.AddCommand(policyCmd)
 	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(manifestCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(registryCmd)
+	rootCmd.AddCommand(searchCmd)
 	rootCmd.AddCommand(completionCmd)
 }
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/policy"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+// scanRequested reports whether a --scan flag, AISK_SCAN or the policy asks
+// for skills to be scanned before they are installed.
+func scanRequested(flag bool, pol *policy.Set) bool {
+	return flag || config.ScanContent() || pol.ScanContent()
+}
+
+// checkScan scans s for secrets and unsafe content, prints what it finds
+// and records the result in the audit log. Error-level findings refuse the
+// skill; warnings are only shown, including those lint suppresses, since the
+// ignore marker comes from the skill being checked.
+func checkScan(al *audit.Logger, s *skill.Skill) error {
+	findings, err := skill.ScanDir(s.Path)
+	if err != nil {
+		return err
+	}
+	report := &skill.LintReport{}
+	rules := make(map[string]int)
+	for _, f := range findings {
+		report.Results = append(report.Results, f.LintResult())
+		rules[f.Rule]++
+	}
+	errs, warns := len(report.Errors()), len(report.Warnings())
+	if len(findings) > 0 {
+		fmt.Printf("Scanning %s: %d error(s), %d warning(s)\n", s.DirName, errs, warns)
+		printLintResults(report, "  ")
+	}
+
+	event := audit.Event{
+		Action:  "scan",
+		Status:  "success",
+		Skill:   s.DirName,
+		Details: map[string]any{"errors": errs, "warnings": warns, "rules": rules, "version": s.DisplayVersion()},
+	}
+	var refusal error
+	if errs > 0 {
+		refusal = fmt.Errorf("%s failed the content scan with %d error(s)", s.DirName, errs)
+		event.Status = "error"
+		event.Error = refusal.Error()
+	}
+	al.LogEvent(event)
+	return refusal
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallScanRefusesSecrets(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SCAN", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origScan := installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan = origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origScan
+	})
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installScan = "codex", "global", false, false, "", false, true
+
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	refDir := filepath.Join(skillsRepo, "skill-a", "reference")
+	if err := os.MkdirAll(refDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(refDir, "setup.md"), []byte("Run with:\n\n    GITHUB_TOKEN=ghp_"+strings.Repeat("a", 36)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "content scan") {
+		t.Fatalf("expected the scan to refuse the skill, got %v", err)
+	}
+	if !strings.Contains(out, "reference/setup.md:3") || !strings.Contains(out, "GitHub token") {
+		t.Fatalf("expected a per-file finding, got:\n%s", out)
+	}
+	if _, statErr := os.Stat(filepath.Join(home, ".codex", "instructions.md")); !os.IsNotExist(statErr) {
+		t.Fatal("nothing should be written when the scan fails")
+	}
+
+	// The skill cannot waive its own errors with the ignore marker.
+	if err := os.WriteFile(filepath.Join(refDir, "setup.md"), []byte("Run with:\n\n    GITHUB_TOKEN=ghp_"+strings.Repeat("a", 36)+" # aisk-scan:ignore\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "content scan") {
+		t.Fatalf("expected the ignore marker not to hide the credential, got %v", err)
+	}
+
+	// Without --scan the skill installs; lint still reports it.
+	installScan = false
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil {
+		t.Fatalf("install without scanning: %v", err)
+	}
+}
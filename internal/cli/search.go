@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search local and registry skills by name or description",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSearch,
+}
+
+var (
+	searchRegistry string
+	searchJSON     bool
+)
+
+func init() {
+	searchCmd.Flags().StringVar(&searchRegistry, "registry", "", "also search a registry (URL or directory; also AISK_REGISTRY)")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "output as JSON")
+}
+
+func runSearch(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "search")
+	al.Log("command.search", "started", map[string]any{"query": args[0], "registry": searchRegistry}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.search", status, nil, retErr)
+	}()
+
+	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	if err != nil {
+		return fmt.Errorf("scanning skills: %w", err)
+	}
+	skills = appendRegistrySkills(paths, al, skills, searchRegistry)
+
+	matches := filterSkills(skills, args[0])
+	if len(matches) == 0 {
+		fmt.Printf("No skills match %q.\n", args[0])
+		return nil
+	}
+	if searchJSON {
+		return printSkillsJSON(matches)
+	}
+	return printSkillsTable(matches)
+}
+
+// filterSkills returns the skills whose name, directory or description
+// contain every word of query, ignoring case.
+func filterSkills(skills []*skill.Skill, query string) []*skill.Skill {
+	words := strings.Fields(strings.ToLower(query))
+	var matches []*skill.Skill
+	for _, s := range skills {
+		text := strings.ToLower(s.Frontmatter.Name + " " + s.DirName + " " + s.Frontmatter.Description)
+		match := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/adapter"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <skill>",
+	Short: "Show skill details, installations, and rendered client output",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runShow,
+}
+
+var (
+	showRender      string
+	showScope       string
+	showIncludeRefs bool
+)
+
+func init() {
+	showCmd.Flags().StringVar(&showRender, "render", "", "print what the adapter for this client would write")
+	showCmd.Flags().StringVar(&showScope, "scope", "global", "scope used when rendering (global or project)")
+	showCmd.Flags().BoolVar(&showIncludeRefs, "include-refs", false, "inline reference files when rendering")
+}
+
+func runShow(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "show")
+	al.Log("command.show", "started", map[string]any{
+		"args":         args,
+		"render":       showRender,
+		"scope":        showScope,
+		"include_refs": showIncludeRefs,
+	}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.show", status, nil, retErr)
+	}()
+
+	skills, err := skill.ScanLocal(paths.SkillsRepo)
+	if err != nil {
+		return fmt.Errorf("scanning skills: %w", err)
+	}
+	target := findSkillByArg(skills, args[0])
+	if target == nil {
+		return fmt.Errorf("skill %q not found", args[0])
+	}
+
+	if showRender != "" {
+		return printSkillRender(paths, target)
+	}
+
+	m, err := loadManifests(paths, manifest.ViewDefault, al)
+	if err != nil {
+		return err
+	}
+
+	printSkillDetails(target, m.Find(target.Frontmatter.Name, ""))
+	return nil
+}
+
+// findSkillByArg matches a skill by directory name or frontmatter name.
+func findSkillByArg(skills []*skill.Skill, arg string) *skill.Skill {
+	for _, s := range skills {
+		if s.DirName == arg || s.Frontmatter.Name == arg {
+			return s
+		}
+	}
+	return nil
+}
+
+func printSkillDetails(s *skill.Skill, installations []manifest.Installation) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", s.Frontmatter.Name)
+	fmt.Fprintf(w, "Version:\t%s\n", s.DisplayVersion())
+	fmt.Fprintf(w, "Directory:\t%s\n", s.DirName)
+	fmt.Fprintf(w, "Source:\t%s\n", s.Source)
+	fmt.Fprintf(w, "Path:\t%s\n", s.Path)
+	fmt.Fprintf(w, "Allowed tools:\t%s\n", joinOrNone(s.Frontmatter.AllowedTools))
+	fmt.Fprintf(w, "Dependencies:\t%s\n", joinOrNone(s.Frontmatter.Dependencies))
+	w.Flush()
+
+	if s.Frontmatter.Description != "" {
+		fmt.Println("\nDescription:")
+		for _, line := range strings.Split(s.Frontmatter.Description, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	printFileList("References", s.Path, s.ReferenceFiles)
+	printFileList("Examples", s.Path, s.ExampleFiles)
+	printFileList("Assets", s.Path, s.AssetFiles)
+
+	fmt.Println()
+	if len(installations) == 0 {
+		fmt.Println("Installed: (not installed)")
+		return
+	}
+	fmt.Println("Installed:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, inst := range installations {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", inst.ClientID, inst.Scope, inst.SkillVersion, inst.InstallPath)
+	}
+	w.Flush()
+}
+
+func printFileList(title, root string, files []string) {
+	fmt.Printf("\n%s (%d):\n", title, len(files))
+	if len(files) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range files {
+		size := "?"
+		if info, err := os.Stat(filepath.Join(root, f)); err == nil {
+			size = formatSize(info.Size())
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", f, size)
+	}
+	w.Flush()
+}
+
+func printSkillRender(paths config.Paths, s *skill.Skill) error {
+	clientID := client.ParseClientID(showRender)
+	if clientID == "" {
+		return fmt.Errorf("unknown client %q (valid: claude, gemini, codex, copilot, cursor, windsurf)", showRender)
+	}
+	adp, err := adapter.ForClient(clientID)
+	if err != nil {
+		return err
+	}
+
+	opts := adapter.InstallOpts{
+		Scope:       showScope,
+		IncludeRefs: showIncludeRefs,
+		DryRun:      true,
+	}
+
+	if r, ok := adp.(adapter.Renderer); ok {
+		content, err := r.Render(s, opts)
+		if err != nil {
+			return fmt.Errorf("rendering for %s: %w", clientID, err)
+		}
+		fmt.Print(content)
+		if !strings.HasSuffix(content, "\n") {
+			fmt.Println()
+		}
+		return nil
+	}
+
+	// Directory-based adapters link or copy the skill tree as-is.
+	reg := client.NewRegistry()
+	client.DetectAll(reg, paths.Home)
+	targetPath := resolveTargetPath(reg.Get(clientID), showScope)
+	fmt.Println(adp.Describe(s, targetPath, opts))
+	fmt.Println("SKILL.md")
+	for _, group := range [][]string{s.ReferenceFiles, s.ExampleFiles, s.AssetFiles} {
+		for _, f := range group {
+			fmt.Println(filepath.ToSlash(f))
+		}
+	}
+	return nil
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
+
+// formatSize renders a byte count in a compact human-readable form.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestRunShow_DetailsOutput(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.2.0")
+	refDir := filepath.Join(skillsRepo, "skill-a", "reference")
+	if err := os.MkdirAll(refDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(refDir, "guide.md"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Add(manifest.Installation{
+		SkillName:    "skill-a",
+		SkillVersion: "1.0.0",
+		ClientID:     "codex",
+		Scope:        "global",
+		InstalledAt:  time.Now(),
+		UpdatedAt:    time.Now(),
+		InstallPath:  filepath.Join(home, ".codex", "instructions.md"),
+	})
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	origRender := showRender
+	t.Cleanup(func() { showRender = origRender })
+	showRender = ""
+
+	out := captureStdout(t, func() {
+		if err := runShow(nil, []string{"skill-a"}); err != nil {
+			t.Fatalf("runShow error: %v", err)
+		}
+	})
+	for _, want := range []string{"1.2.0", "References (1):", "guide.md", "10 B", "codex", "instructions.md"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got: %s", want, out)
+		}
+	}
+}
+
+func TestRunShow_RenderCursor(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+
+	origRender, origScope := showRender, showScope
+	t.Cleanup(func() { showRender, showScope = origRender, origScope })
+	showRender = "cursor"
+	showScope = "project"
+
+	out := captureStdout(t, func() {
+		if err := runShow(nil, []string{"skill-a"}); err != nil {
+			t.Fatalf("runShow error: %v", err)
+		}
+	})
+	if !strings.HasPrefix(out, "---\ndescription: test\n") || !strings.Contains(out, "alwaysApply: false") {
+		t.Fatalf("unexpected cursor render output: %s", out)
+	}
+}
+
+func TestRunShow_UnknownSkill(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AISK_SKILLS_PATH", t.TempDir())
+
+	err := runShow(nil, []string{"missing"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/trust"
+)
+
+var signCmd = &cobra.Command{
+	Use:   "sign <skill|path>",
+	Short: "Sign a skill with an ed25519 key",
+	Long: `Sign the file-hash manifest of a skill (every file's path and SHA-256) and
+write the detached signature to SKILL.sig in the skill directory. Commit or
+pack SKILL.sig with the skill; anyone who trusts the public key can then
+verify it with install --require-signature.
+
+Create a key pair with "aisk trust keygen <name>". The key is read from
+--key or AISK_SIGNING_KEY. Sign again after every change to the skill.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSign,
+}
+
+var signKey string
+
+func init() {
+	signCmd.Flags().StringVar(&signKey, "key", "", "private key file (default: AISK_SIGNING_KEY)")
+}
+
+func runSign(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	keyPath := signKey
+	if keyPath == "" {
+		keyPath = os.Getenv("AISK_SIGNING_KEY")
+	}
+	al := audit.New(paths.AiskDir, "sign")
+	al.Log("command.sign", "started", map[string]any{"target": args[0], "key": keyPath}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.sign", status, nil, retErr)
+	}()
+
+	if keyPath == "" {
+		return fmt.Errorf("no signing key: pass --key or set AISK_SIGNING_KEY (create one with aisk trust keygen)")
+	}
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("reading signing key: %w", err)
+	}
+	key, err := trust.ParsePrivateKey(data, keyName(keyPath))
+	if err != nil {
+		return fmt.Errorf("%s: %w", keyPath, err)
+	}
+
+	target, err := resolveSkillOrDir(paths, args[0])
+	if err != nil {
+		return err
+	}
+	sig, err := trust.Sign(target.Path, key)
+	if err != nil {
+		return fmt.Errorf("signing %s: %w", target.DirName, err)
+	}
+	fmt.Printf("Signed %s %s with key %s (%s)\n", target.DirName, target.DisplayVersion(), key.Name, sig.KeyID)
+	fmt.Printf("Wrote %s\n", filepath.Join(target.Path, trust.SignatureFile))
+	al.Log("sign.write", "success", map[string]any{
+		"skill":   target.DirName,
+		"version": target.DisplayVersion(),
+		"key_id":  sig.KeyID,
+	}, nil)
+	return nil
+}
+
+// keyName returns the name a key file stands for: its base name without the
+// extension.
+func keyName(path string) string {
+	base := filepath.Base(path)
+	return base[:len(base)-len(filepath.Ext(base))]
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSignThenRequireSignatureOnInstall(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	keyDir := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	t.Setenv("AISK_SIGNING_KEY", "")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origKey, origOut, origAddName := signKey, trustKeygenOut, trustAddName
+	origClient, origScope, origRefs, origDryRun, origRegistry, origRequire := installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig
+	t.Cleanup(func() {
+		signKey, trustKeygenOut, trustAddName = origKey, origOut, origAddName
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig = origClient, origScope, origRefs, origDryRun, origRegistry, origRequire
+	})
+	trustKeygenOut, trustAddName = keyDir, ""
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry = "codex", "global", false, true, ""
+
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	captureStdout(t, func() {
+		if err := runTrustKeygen(nil, []string{"alice"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	install := func() error {
+		var err error
+		captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+		return err
+	}
+
+	installRequireSig = true
+	if err := install(); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Fatalf("unsigned skill: got %v", err)
+	}
+
+	signKey = filepath.Join(keyDir, "alice.key")
+	captureStdout(t, func() {
+		if err := runSign(nil, []string{"skill-a"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := install(); err == nil || !strings.Contains(err.Error(), "not trusted") {
+		t.Fatalf("untrusted key: got %v", err)
+	}
+
+	captureStdout(t, func() {
+		if err := runTrustAdd(nil, []string{filepath.Join(keyDir, "alice.pub")}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := install(); err != nil {
+		t.Fatalf("signed by a trusted key: %v", err)
+	}
+
+	// Edits after signing break the signature, required or not.
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.1")
+	installRequireSig = false
+	if err := install(); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("modified skill: got %v", err)
+	}
+}
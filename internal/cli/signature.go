@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/policy"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/trust"
+)
+
+// signatureRequired reports whether a --require-signature flag,
+// AISK_REQUIRE_SIGNATURE or the policy asks for signed skills only.
+func signatureRequired(flag bool, pol *policy.Set) bool {
+	return flag || config.RequireSignature() || pol.RequireSignature()
+}
+
+func loadTrustStore(paths config.Paths) (*trust.Store, error) {
+	store, err := trust.LoadStore(paths.TrustDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading trusted keys: %w", err)
+	}
+	return store, nil
+}
+
+// checkSignature verifies the signature of s against the trusted keys and
+// records the result in the audit log. A signature that does not match the
+// skill is always refused; an unsigned skill or an unknown key is refused
+// when require is set and otherwise only noted (with a warning for the key).
+func checkSignature(store *trust.Store, al *audit.Logger, s *skill.Skill, require bool) error {
+	res, err := trust.Verify(s.Path, store)
+	if err != nil {
+		return fmt.Errorf("verifying %s: %w", s.DirName, err)
+	}
+	details := map[string]any{
+		"result":   res.Status.String(),
+		"required": require,
+		"source":   s.SourceName(),
+		"version":  s.DisplayVersion(),
+	}
+	if res.KeyID != "" {
+		details["key_id"] = res.KeyID
+	}
+	if res.Signer != "" {
+		details["signer"] = res.Signer
+	}
+
+	var refusal error
+	switch res.Status {
+	case trust.Verified:
+	case trust.Invalid:
+		refusal = fmt.Errorf("%s has an invalid signature: %v", s.DirName, res.Err)
+	case trust.UnknownKey:
+		if require {
+			refusal = fmt.Errorf("%s is signed by key %s, which is not trusted (add it with aisk trust add)", s.DirName, res.KeyID)
+		} else {
+			fmt.Fprintf(os.Stderr, "warning: %s is signed by key %s, which is not trusted\n", s.DirName, res.KeyID)
+		}
+	case trust.Unsigned:
+		if require {
+			refusal = fmt.Errorf("%s is not signed and a signature is required", s.DirName)
+		}
+	}
+
+	event := audit.Event{Action: "signature.verify", Status: "success", Skill: s.DirName, Details: details}
+	switch {
+	case refusal != nil:
+		event.Status = "error"
+		event.Error = refusal.Error()
+	case res.Status != trust.Verified:
+		event.Status = "skipped"
+	}
+	al.LogEvent(event)
+	return refusal
+}
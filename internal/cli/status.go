@@ -22,11 +22,13 @@ var statusCmd = &cobra.Command{
 var (
 	statusJSON         bool
 	statusCheckUpdates bool
+	statusView         manifestViewFlags
 )
 
 func init() {
 	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "output as JSON")
 	statusCmd.Flags().BoolVar(&statusCheckUpdates, "check-updates", true, "check for available updates")
+	addManifestViewFlags(statusCmd, &statusView)
 }
 
 func runStatus(_ *cobra.Command, _ []string) (retErr error) {
@@ -47,14 +49,13 @@ func runStatus(_ *cobra.Command, _ []string) (retErr error) {
 		al.Log("command.status", status, nil, retErr)
 	}()
 
-	m, err := manifest.Load(paths.ManifestDB)
+	set, err := loadManifests(paths, statusView.view(), al)
 	if err != nil {
-		al.Log("manifest.load", "error", nil, err)
-		return fmt.Errorf("loading manifest: %w", err)
+		return err
 	}
-	al.Log("manifest.load", "success", map[string]any{"installations": len(m.Installations)}, nil)
+	installations := set.Installations()
 
-	if len(m.Installations) == 0 {
+	if len(installations) == 0 {
 		fmt.Println("No skills installed.")
 		al.Log("status.render", "success", map[string]any{"installations": 0}, nil)
 		return nil
@@ -63,22 +64,34 @@ func runStatus(_ *cobra.Command, _ []string) (retErr error) {
 	if statusJSON {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		al.Log("status.render", "success", map[string]any{"format": "json", "installations": len(m.Installations)}, nil)
-		return enc.Encode(m.Installations)
+		al.Log("status.render", "success", map[string]any{"format": "json", "installations": len(installations)}, nil)
+		return enc.Encode(installations)
 	}
 
-	entries := tui.BuildStatusEntries(m)
-	tui.PrintStatusTable(entries)
+	locations := set.Locations()
+	for i, loc := range locations {
+		if len(locations) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			if loc.Root == "" {
+				fmt.Println("Global:")
+			} else {
+				fmt.Printf("Project %s:\n", loc.Root)
+			}
+		}
+		tui.PrintStatusTable(tui.BuildStatusEntries(loc.Installations))
+	}
 
 	// Check for updates
 	if statusCheckUpdates {
-		checkAndPrintUpdates(paths, m, al)
+		checkAndPrintUpdates(paths, installations, al)
 	}
 
 	return nil
 }
 
-func checkAndPrintUpdates(paths config.Paths, m *manifest.Manifest, al *audit.Logger) {
+func checkAndPrintUpdates(paths config.Paths, installations []manifest.Installation, al *audit.Logger) {
 	al.Log("status.updates.check", "started", nil, nil)
 	available, err := skill.ScanLocal(paths.SkillsRepo)
 	if err != nil {
@@ -87,7 +100,7 @@ func checkAndPrintUpdates(paths config.Paths, m *manifest.Manifest, al *audit.Lo
 		return
 	}
 
-	updates := skill.CheckUpdates(m.Installations, available)
+	updates := skill.CheckUpdates(installations, available)
 	if len(updates) > 0 {
 		tui.PrintUpdateTable(updates)
 	}
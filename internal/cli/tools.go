@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/policy"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+// errNoPrompt is returned by confirmPrompt when there is no one to ask.
+var errNoPrompt = errors.New("cannot prompt for confirmation")
+
+// confirmPrompt asks a yes/no question on the terminal, defaulting to no.
+// It fails with errNoPrompt under --yes or when stdin is not a terminal.
+// Tests replace it.
+var confirmPrompt = func(question string) (bool, error) {
+	if assumeYes || !stdinIsTerminal() {
+		return false, errNoPrompt
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// stdinIsTerminal reports whether stdin is attached to a terminal.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// printToolPermissions lists the allowed-tools s requests, noting broad
+// grants.
+func printToolPermissions(s *skill.Skill) {
+	tools := s.Frontmatter.AllowedTools
+	if len(tools) == 0 {
+		return
+	}
+	fmt.Printf("%s requests tool permissions:\n", s.DirName)
+	for _, raw := range tools {
+		g, err := skill.ParseToolGrant(raw)
+		switch {
+		case err != nil:
+			fmt.Printf("  %s (malformed)\n", raw)
+		case g.Broad() != "":
+			fmt.Printf("  %s (%s)\n", raw, g.Broad())
+		default:
+			fmt.Printf("  %s\n", raw)
+		}
+	}
+}
+
+// grantedTools returns the tools to record for an installation of s. It is
+// never nil, so a skill that requests no tools is recorded as granting none
+// rather than as an installation from before tools were tracked.
+func grantedTools(s *skill.Skill) []string {
+	return append([]string{}, s.Frontmatter.AllowedTools...)
+}
+
+// toolConfirmer decides whether installing a skill over existing
+// installations may grant tools they did not have. Expansions approved by
+// a system or user policy, or by --accept-tools, pass; others are put to
+// the user, once per skill and set of tools.
+type toolConfirmer struct {
+	pol       *policy.Set
+	al        *audit.Logger
+	accept    bool
+	confirmed map[*skill.Skill][]string
+}
+
+func newToolConfirmer(pol *policy.Set, al *audit.Logger, accept bool) *toolConfirmer {
+	return &toolConfirmer{pol: pol, al: al, accept: accept, confirmed: make(map[*skill.Skill][]string)}
+}
+
+// check returns an error unless the tools s requests beyond those recorded
+// for insts are approved, accepted or confirmed. Installations recorded
+// before aisk tracked tools are taken to grant what s requests; installing
+// s records that grant for them.
+func (tc *toolConfirmer) check(s *skill.Skill, insts ...manifest.Installation) error {
+	var expanded []string
+	seen := make(map[string]bool)
+	for _, inst := range insts {
+		if inst.AllowedTools == nil {
+			continue
+		}
+		granted := append(append([]string(nil), inst.AllowedTools...), tc.confirmed[s]...)
+		for _, tool := range skill.ExpandedTools(granted, s.Frontmatter.AllowedTools) {
+			if !seen[tool] {
+				seen[tool] = true
+				expanded = append(expanded, tool)
+			}
+		}
+	}
+	if len(expanded) == 0 {
+		return nil
+	}
+
+	pending := tc.pol.UnapprovedTools(expanded)
+	details := map[string]any{
+		"expanded": expanded,
+		"version":  s.DisplayVersion(),
+	}
+	var refusal error
+	switch {
+	case len(pending) == 0:
+		details["approved_by"] = "policy"
+	case tc.accept:
+		details["approved_by"] = "flag"
+	default:
+		question := fmt.Sprintf("%s %s requests new tool permissions: %s. Allow?", s.DirName, s.DisplayVersion(), strings.Join(pending, ", "))
+		ok, err := confirmPrompt(question)
+		switch {
+		case err != nil:
+			refusal = fmt.Errorf("%s requests new tool permissions (%s); review them and rerun with --accept-tools, or approve them with approved_tools in a policy", s.DirName, strings.Join(pending, ", "))
+		case !ok:
+			refusal = fmt.Errorf("new tool permissions for %s declined", s.DirName)
+		default:
+			details["approved_by"] = "prompt"
+		}
+	}
+
+	event := audit.Event{Action: "tools.expand", Status: "success", Skill: s.DirName, Details: details}
+	if refusal != nil {
+		event.Status = "error"
+		event.Error = refusal.Error()
+	} else {
+		tc.confirmed[s] = append(tc.confirmed[s], expanded...)
+	}
+	tc.al.LogEvent(event)
+	return refusal
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestInstallConfirmsToolExpansion(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	t.Setenv("AISK_REGISTRY", "")
+	t.Setenv("AISK_REQUIRE_SIGNATURE", "")
+	t.Setenv("AISK_SYSTEM_POLICY", filepath.Join(t.TempDir(), "none.yaml"))
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origAccept := installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installAcceptTools
+	origPrompt := confirmPrompt
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installAcceptTools = origClient, origScope, origRefs, origDryRun, origRegistry, origRequire, origAccept
+		confirmPrompt = origPrompt
+	})
+	installClient, installScope, installIncludeRefs, installDryRun, installRegistry, installRequireSig, installAcceptTools = "codex", "global", false, false, "", false, false
+
+	writeSkill := func(version, tools string) {
+		t.Helper()
+		content := "---\nname: skill-a\ndescription: test\nversion: " + version + "\nallowed-tools: " + tools + "\n---\n# Skill\nUse when: test\n"
+		if err := os.MkdirAll(filepath.Join(skillsRepo, "skill-a"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(skillsRepo, "skill-a", "SKILL.md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recordedTools := func() []string {
+		t.Helper()
+		m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		insts := m.Find("skill-a", "codex")
+		if len(insts) != 1 {
+			t.Fatalf("expected one installation, got %d", len(insts))
+		}
+		return insts[0].AllowedTools
+	}
+	var prompts []string
+	answer := func(ok bool, err error) {
+		confirmPrompt = func(q string) (bool, error) {
+			prompts = append(prompts, q)
+			return ok, err
+		}
+	}
+
+	// A first install shows the tools but has nothing to compare against.
+	answer(false, errNoPrompt)
+	writeSkill("1.0.0", "Read, Bash(git status:*)")
+	var err error
+	out := captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil {
+		t.Fatalf("first install: %v", err)
+	}
+	if !strings.Contains(out, "Bash(git status:*)") || len(prompts) != 0 {
+		t.Fatalf("expected the tools listed without a prompt, got %d prompt(s):\n%s", len(prompts), out)
+	}
+
+	// New tools with no one to ask are refused.
+	writeSkill("1.1.0", "Read, Bash(git status:*), Bash")
+	out = captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "--accept-tools") {
+		t.Fatalf("expected the expansion to be refused, got %v", err)
+	}
+	if !strings.Contains(out, "any shell command") {
+		t.Fatalf("expected the broad grant to be flagged:\n%s", out)
+	}
+
+	answer(false, nil)
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "declined") {
+		t.Fatalf("expected a declined expansion, got %v", err)
+	}
+	if got := recordedTools(); len(got) != 2 {
+		t.Fatalf("refused installs must not change the record, got %q", got)
+	}
+
+	answer(true, nil)
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil {
+		t.Fatalf("confirmed install: %v", err)
+	}
+	if got := recordedTools(); len(got) != 3 || got[2] != "Bash" {
+		t.Fatalf("recorded tools = %q", got)
+	}
+
+	// A user policy approves the next expansion without asking.
+	if err := os.WriteFile(filepath.Join(home, ".aisk", "policy.yaml"), []byte("approved_tools: [\"Write(docs/*)\"]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prompts = nil
+	answer(false, errNoPrompt)
+	writeSkill("1.2.0", "Read, Bash, Write(docs/**)")
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil || len(prompts) != 0 {
+		t.Fatalf("policy-approved install: err %v, %d prompt(s)", err, len(prompts))
+	}
+
+	installAcceptTools = true
+	writeSkill("1.3.0", "Read, Bash, Write(docs/**), WebFetch")
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil || len(prompts) != 0 {
+		t.Fatalf("--accept-tools install: err %v, %d prompt(s)", err, len(prompts))
+	}
+
+	// An installation recorded before tools were tracked takes the current
+	// grant, which is recorded from then on.
+	installAcceptTools = false
+	manifestPath := filepath.Join(home, ".aisk", "manifest.json")
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := m.Find("skill-a", "codex")[0]
+	legacy.AllowedTools = nil
+	m.Add(legacy)
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	writeSkill("1.4.0", "Read, Bash, Glob")
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil || len(prompts) != 0 {
+		t.Fatalf("install over an untracked grant: err %v, %d prompt(s)", err, len(prompts))
+	}
+	if got := recordedTools(); len(got) != 3 || got[2] != "Glob" {
+		t.Fatalf("recorded tools = %q", got)
+	}
+
+	// A version requesting no tools is recorded as granting none.
+	writeSkill("1.5.0", "[]")
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err != nil {
+		t.Fatalf("install without tools: %v", err)
+	}
+	if got := recordedTools(); got == nil || len(got) != 0 {
+		t.Fatalf("recorded tools = %#v, want an empty grant", got)
+	}
+	writeSkill("1.6.0", "Read")
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "--accept-tools") {
+		t.Fatalf("expected the expansion from no tools to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/fsutil"
+	"github.com/yorch/aisk/internal/trust"
+)
+
+var trustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Manage the public keys trusted to sign skills",
+	Long: `Keys in ~/.aisk/trust/ are trusted to sign skills. install and update verify
+SKILL.sig against them; with --require-signature (or AISK_REQUIRE_SIGNATURE)
+unsigned skills and skills signed by other keys are refused.`,
+}
+
+var trustListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trusted keys",
+	Args:  cobra.NoArgs,
+	RunE:  runTrustList,
+}
+
+var trustAddCmd = &cobra.Command{
+	Use:   "add <key.pub>",
+	Short: "Trust a public key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTrustAdd,
+}
+
+var trustRemoveCmd = &cobra.Command{
+	Use:   "remove <name|key-id>",
+	Short: "Stop trusting a key",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTrustRemove,
+}
+
+var trustKeygenCmd = &cobra.Command{
+	Use:   "keygen <name>",
+	Short: "Create a signing key pair",
+	Long: `Write <name>.key (private, for aisk sign) and <name>.pub (public, for aisk
+trust add) to --out. Keep the private key secret; share the public key with
+everyone who installs your skills.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrustKeygen,
+}
+
+var (
+	trustAddName   string
+	trustKeygenOut string
+)
+
+func init() {
+	trustAddCmd.Flags().StringVar(&trustAddName, "name", "", "name to trust the key as (default: the name in the key file)")
+	trustKeygenCmd.Flags().StringVar(&trustKeygenOut, "out", ".", "directory to write the key pair to")
+	trustCmd.AddCommand(trustListCmd)
+	trustCmd.AddCommand(trustAddCmd)
+	trustCmd.AddCommand(trustRemoveCmd)
+	trustCmd.AddCommand(trustKeygenCmd)
+}
+
+func runTrustList(_ *cobra.Command, _ []string) error {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return err
+	}
+	if len(store.Keys()) == 0 {
+		fmt.Printf("No trusted keys in %s.\n", store.Dir)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKEY ID")
+	for _, k := range store.Keys() {
+		fmt.Fprintf(w, "%s\t%s\n", k.Name, k.ID)
+	}
+	return w.Flush()
+}
+
+func runTrustAdd(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "trust")
+	al.Log("command.trust.add", "started", map[string]any{"key": args[0], "name": trustAddName}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.trust.add", status, nil, retErr)
+	}()
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	key, err := trust.ParsePublicKey(data, keyName(args[0]))
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	if trustAddName != "" {
+		key.Name = trustAddName
+	}
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return err
+	}
+	if err := store.Add(key); err != nil {
+		return err
+	}
+	fmt.Printf("Trusted key %s (%s)\n", key.Name, key.ID)
+	al.Log("trust.add", "success", map[string]any{"name": key.Name, "key_id": key.ID}, nil)
+	return nil
+}
+
+func runTrustRemove(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "trust")
+	al.Log("command.trust.remove", "started", map[string]any{"key": args[0]}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.trust.remove", status, nil, retErr)
+	}()
+
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return err
+	}
+	key, err := store.Remove(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed key %s (%s)\n", key.Name, key.ID)
+	al.Log("trust.remove", "success", map[string]any{"name": key.Name, "key_id": key.ID}, nil)
+	return nil
+}
+
+func runTrustKeygen(_ *cobra.Command, args []string) (retErr error) {
+	paths, err := config.ResolvePaths()
+	if err != nil {
+		return err
+	}
+	al := audit.New(paths.AiskDir, "trust")
+	al.Log("command.trust.keygen", "started", map[string]any{"name": args[0], "out": trustKeygenOut}, nil)
+	defer func() {
+		status := "success"
+		if retErr != nil {
+			status = "error"
+		}
+		al.Log("command.trust.keygen", status, nil, retErr)
+	}()
+
+	key, err := trust.GenerateKey(args[0])
+	if err != nil {
+		return err
+	}
+	privPath := filepath.Join(trustKeygenOut, args[0]+".key")
+	pubPath := filepath.Join(trustKeygenOut, args[0]+".pub")
+	for _, p := range []string{privPath, pubPath} {
+		if _, err := os.Stat(p); err == nil {
+			return fmt.Errorf("%s already exists", p)
+		}
+	}
+	if err := os.MkdirAll(trustKeygenOut, 0o755); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFile(privPath, key.Marshal(), 0o600); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFile(pubPath, key.Public().Marshal(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote private key %s and public key %s (key id %s)\n", privPath, pubPath, key.Public().ID)
+	fmt.Printf("Sign with: aisk sign <skill> --key %s\n", privPath)
+	al.Log("trust.keygen", "success", map[string]any{"name": args[0], "key_id": key.Public().ID, "public_key": pubPath}, nil)
+	return nil
+}
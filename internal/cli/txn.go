@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/client"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/txn"
+)
+
+// recoverTransactions reverts or finishes --atomic operations that an earlier
+// aisk process left behind. Callers must hold the manifest lock, so a
+// transaction still in progress is never touched, and must load the manifest
+// afterwards.
+func recoverTransactions(paths config.Paths, al *audit.Logger) {
+	recovered, err := txn.Recover(paths.TxnDir)
+	for _, r := range recovered {
+		fmt.Fprintf(os.Stderr, "Recovered interrupted %s transaction %s (%s)\n", r.Command, r.ID, r.Action)
+		al.Log("txn.recover", "success", map[string]any{
+			"id":      r.ID,
+			"command": r.Command,
+			"action":  r.Action,
+			"paths":   len(r.Entries),
+		}, nil)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not recover interrupted transaction: %v\n", err)
+		al.Log("txn.recover", "error", nil, err)
+	}
+}
+
+// adapterOutputPath returns the file or directory an adapter writes for s.
+func adapterOutputPath(clientID client.ClientID, scope string, s *skill.Skill, targetPath string) string {
+	switch {
+	case clientID == client.Claude:
+		return filepath.Join(targetPath, s.DirName)
+	case clientID == client.Cursor:
+		return filepath.Join(targetPath, s.DirName+".mdc")
+	case clientID == client.Windsurf && scope == "project":
+		return filepath.Join(targetPath, s.DirName+".md")
+	default:
+		return targetPath
+	}
+}
+
+// trackForWrite records path in tx before it is written. When parent
+// directories do not exist yet, the topmost missing one is tracked instead so
+// rollback also removes the directories the adapter creates.
+func trackForWrite(tx *txn.Tx, path string) error {
+	for {
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		if _, err := os.Lstat(parent); !os.IsNotExist(err) {
+			break
+		}
+		path = parent
+	}
+	return tx.Track(path)
+}
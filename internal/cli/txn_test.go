@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/audit"
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/txn"
+)
+
+func TestRunInstall_AtomicRollsBackOnFailure(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+
+	for _, d := range []string{".claude", ".codex", filepath.Join(".gemini", "GEMINI.md")} {
+		if err := os.MkdirAll(filepath.Join(home, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	codexFile := filepath.Join(home, ".codex", "instructions.md")
+	if err := os.WriteFile(codexFile, []byte("my notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origClient, origScope, origDryRun, origAtomic := installClient, installScope, installDryRun, installAtomic
+	t.Cleanup(func() {
+		installClient, installScope, installDryRun, installAtomic = origClient, origScope, origDryRun, origAtomic
+	})
+	installClient = "claude,codex,gemini"
+	installScope = "global"
+	installDryRun = false
+	installAtomic = true
+
+	var err error
+	captureStdout(t, func() { err = runInstall(nil, []string{"skill-a"}) })
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(home, ".claude", "skills")); !os.IsNotExist(err) {
+		t.Errorf("claude install should be rolled back, stat err = %v", err)
+	}
+	if data, _ := os.ReadFile(codexFile); string(data) != "my notes\n" {
+		t.Errorf("codex file should be restored, got %q", data)
+	}
+	paths, _ := config.ResolvePaths()
+	m, err := manifest.Load(paths.ManifestDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Installations) != 0 {
+		t.Errorf("manifest should have no installations, got %+v", m.Installations)
+	}
+	if entries, _ := os.ReadDir(paths.TxnDir); len(entries) != 0 {
+		t.Errorf("journal should be removed, found %d entries", len(entries))
+	}
+}
+
+func TestRecoverTransactions_RevertsInterruptedInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	paths, _ := config.ResolvePaths()
+	if err := paths.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(home, "AGENTS.md")
+	tx, err := txn.Begin(paths.TxnDir, "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trackForWrite(tx, target); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(target, []byte("half written"), 0o644)
+
+	recoverTransactions(paths, audit.New(paths.AiskDir, "install"))
+
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("interrupted write should be reverted, stat err = %v", err)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yorch/aisk/internal/adapter"
@@ -24,10 +23,14 @@ var uninstallCmd = &cobra.Command{
 	RunE:  runUninstall,
 }
 
-var uninstallClient string
+var (
+	uninstallClient string
+	uninstallView   manifestViewFlags
+)
 
 func init() {
 	uninstallCmd.Flags().StringVar(&uninstallClient, "client", "", "specific client to uninstall from")
+	addManifestViewFlags(uninstallCmd, &uninstallView)
 }
 
 func runUninstall(_ *cobra.Command, args []string) (retErr error) {
@@ -51,12 +54,17 @@ func runUninstall(_ *cobra.Command, args []string) (retErr error) {
 	skillArg := args[0]
 
 	// Load manifest to find installations
-	m, err := manifest.Load(paths.ManifestDB)
+	lock, err := acquireManifestLock(paths, al)
 	if err != nil {
-		al.Log("manifest.load", "error", nil, err)
-		return fmt.Errorf("loading manifest: %w", err)
+		return err
+	}
+	defer releaseManifestLock(lock, al)
+	recoverTransactions(paths, al)
+
+	m, err := loadManifests(paths, uninstallView.view(), al)
+	if err != nil {
+		return err
 	}
-	al.Log("manifest.load", "success", map[string]any{"installations": len(m.Installations)}, nil)
 
 	installations := m.Find(skillArg, uninstallClient)
 	if len(installations) == 0 {
@@ -88,17 +96,6 @@ func runUninstall(_ *cobra.Command, args []string) (retErr error) {
 		}
 	}
 
-	lock := manifest.NewLock(paths.ManifestDB)
-	al.Log("manifest.lock", "started", map[string]any{"path": paths.ManifestDB + ".lock"}, nil)
-	if err := lock.Acquire(5 * time.Second); err != nil {
-		al.Log("manifest.lock", "error", nil, err)
-		fmt.Fprintf(os.Stderr, "warning: could not acquire lock: %v\n", err)
-	} else {
-		al.Log("manifest.lock", "success", nil, nil)
-		defer lock.Release()
-		defer al.Log("manifest.lock", "released", nil, nil)
-	}
-
 	for _, inst := range installations {
 		clientID := client.ParseClientID(inst.ClientID)
 		adp, err := adapter.ForClient(clientID)
@@ -138,7 +135,9 @@ func runUninstall(_ *cobra.Command, args []string) (retErr error) {
 			continue
 		}
 
-		m.Remove(inst.SkillName, inst.ClientID, inst.Scope)
+		if err := m.Remove(inst); err != nil {
+			return fmt.Errorf("recording removal of %s from %s: %w", inst.SkillName, inst.ClientID, err)
+		}
 		fmt.Printf("Uninstalled %q from %s\n", inst.SkillName, inst.ClientID)
 		al.LogEvent(audit.Event{
 			Action:   "uninstall.adapter.apply",
@@ -154,7 +153,7 @@ func runUninstall(_ *cobra.Command, args []string) (retErr error) {
 		al.Log("manifest.save", "error", nil, err)
 		return fmt.Errorf("saving manifest: %w", err)
 	}
-	al.Log("manifest.save", "success", map[string]any{"installations": len(m.Installations)}, nil)
+	al.Log("manifest.save", "success", map[string]any{"installations": len(m.Installations())}, nil)
 
 	// Clean up .gitignore for project-scope uninstalls
 	al.Log("gitignore.cleanup", "started", nil, nil)
@@ -164,18 +163,7 @@ func runUninstall(_ *cobra.Command, args []string) (retErr error) {
 	return nil
 }
 
-func manageGitignoreOnUninstall(m *manifest.Manifest, removed []manifest.Installation) {
-	// Collect client IDs from removed project-scope installations
-	removedClients := make(map[string]bool)
-	for _, inst := range removed {
-		if inst.Scope == "project" {
-			removedClients[inst.ClientID] = true
-		}
-	}
-	if len(removedClients) == 0 {
-		return
-	}
-
+func manageGitignoreOnUninstall(m *manifest.Set, removed []manifest.Installation) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return
@@ -185,10 +173,20 @@ func manageGitignoreOnUninstall(m *manifest.Manifest, removed []manifest.Install
 		return
 	}
 
+	// Collect client IDs from installations removed from this project
+	removedClients := make(map[string]bool)
+	for _, inst := range removed {
+		if isInstallationInProject(inst, projectRoot) {
+			removedClients[inst.ClientID] = true
+		}
+	}
+	if len(removedClients) == 0 {
+		return
+	}
+
 	// Check which clients still have project-scope installs
-	remaining := m.FindByScope("project")
 	stillUsed := make(map[string]bool)
-	for _, inst := range remaining {
+	for _, inst := range m.Installations() {
 		if isInstallationInProject(inst, projectRoot) {
 			stillUsed[inst.ClientID] = true
 		}
@@ -223,8 +221,9 @@ func isInstallationInProject(inst manifest.Installation, projectRoot string) boo
 		return false
 	}
 	if !filepath.IsAbs(inst.InstallPath) {
-		// Backward compatibility for older manifests that stored relative project paths.
-		// Assume these entries belong to the current project context.
+		// Schema v1 stored relative project paths; migration leaves them relative
+		// when the manifest is loaded outside a project. Assume these entries
+		// belong to the current project context.
 		return true
 	}
 	rel, err := filepath.Rel(projectRoot, inst.InstallPath)
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -43,3 +45,81 @@ func TestIsInstallationInProject_LegacyRelativePath(t *testing.T) {
 		t.Fatal("expected legacy relative install path to be treated as in-project")
 	}
 }
+
+func TestProjectInstallsUseProjectManifest(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	project := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	for _, dir := range []string{filepath.Join(home, ".claude"), filepath.Join(project, ".git")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(project)
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+
+	origClient, origScope, origDryRun := installClient, installScope, installDryRun
+	origStatus, origJSON, origUpdates := statusView, statusJSON, statusCheckUpdates
+	origUninstall, origUninstallClient := uninstallView, uninstallClient
+	t.Cleanup(func() {
+		installClient, installScope, installDryRun = origClient, origScope, origDryRun
+		statusView, statusJSON, statusCheckUpdates = origStatus, origJSON, origUpdates
+		uninstallView, uninstallClient = origUninstall, origUninstallClient
+	})
+	installClient, installDryRun = "claude", false
+	for _, scope := range []string{"global", "project"} {
+		installScope = scope
+		captureStdout(t, func() {
+			if err := runInstall(nil, []string{"skill-a"}); err != nil {
+				t.Fatalf("install --scope %s: %v", scope, err)
+			}
+		})
+	}
+
+	global, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(global.Installations) != 1 || global.Installations[0].Scope != "global" {
+		t.Fatalf("global manifest = %+v, want only the global install", global.Installations)
+	}
+	local, err := manifest.LoadProject(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(local.Installations) != 1 || local.Installations[0].Scope != "project" {
+		t.Fatalf("project manifest = %+v, want only the project install", local.Installations)
+	}
+
+	statusJSON, statusCheckUpdates = true, false
+	statusView = manifestViewFlags{global: true}
+	out := captureStdout(t, func() {
+		if err := runStatus(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	var listed []manifest.Installation
+	if err := json.Unmarshal([]byte(out), &listed); err != nil {
+		t.Fatalf("status output: %v\n%s", err, out)
+	}
+	if len(listed) != 1 || listed[0].Scope != "global" {
+		t.Fatalf("status --global = %+v", listed)
+	}
+
+	uninstallClient = ""
+	uninstallView = manifestViewFlags{project: true}
+	captureStdout(t, func() {
+		if err := runUninstall(nil, []string{"skill-a"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if local, _ = manifest.LoadProject(project); len(local.Installations) != 0 {
+		t.Fatalf("project manifest after uninstall --project = %+v", local.Installations)
+	}
+	if global, _ = manifest.Load(filepath.Join(home, ".aisk", "manifest.json")); len(global.Installations) != 1 {
+		t.Fatalf("uninstall --project must keep the global install, got %+v", global.Installations)
+	}
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -11,7 +12,9 @@ import (
 	"github.com/yorch/aisk/internal/client"
 	"github.com/yorch/aisk/internal/config"
 	"github.com/yorch/aisk/internal/manifest"
+	"github.com/yorch/aisk/internal/registry"
 	"github.com/yorch/aisk/internal/skill"
+	"github.com/yorch/aisk/internal/tui"
 )
 
 var updateCmd = &cobra.Command{
@@ -21,10 +24,24 @@ var updateCmd = &cobra.Command{
 	RunE:  runUpdate,
 }
 
-var updateClient string
+var (
+	updateClient        string
+	updateView          manifestViewFlags
+	updateOverrides     optionOverrides
+	updateRequireSig    bool
+	updateAcceptTools   bool
+	updateScan          bool
+	updateAcceptChanges bool
+)
 
 func init() {
 	updateCmd.Flags().StringVar(&updateClient, "client", "", "specific client to update")
+	addManifestViewFlags(updateCmd, &updateView)
+	addOptionOverrideFlags(updateCmd, &updateOverrides)
+	updateCmd.Flags().BoolVar(&updateRequireSig, "require-signature", false, "skip skills not signed by a trusted key (also AISK_REQUIRE_SIGNATURE)")
+	updateCmd.Flags().BoolVar(&updateScan, "scan", false, "scan skills for secrets and unsafe content and skip those with errors (also AISK_SCAN)")
+	updateCmd.Flags().BoolVar(&updateAcceptChanges, "accept-changes", false, "accept remote skills that were force-pushed or changed without a version bump since they were pinned")
+	updateCmd.Flags().BoolVar(&updateAcceptTools, "accept-tools", false, "accept tool permissions skills request beyond those of the installed versions")
 }
 
 func runUpdate(_ *cobra.Command, args []string) (retErr error) {
@@ -34,8 +51,14 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 	}
 	al := audit.New(paths.AiskDir, "update")
 	al.Log("command.update", "started", map[string]any{
-		"args":   args,
-		"client": updateClient,
+		"args":            args,
+		"client":          updateClient,
+		"include_refs":    updateOverrides.includeRefs,
+		"no_include_refs": updateOverrides.noIncludeRefs,
+		"require_sig":     updateRequireSig,
+		"accept_tools":    updateAcceptTools,
+		"scan":            updateScan,
+		"accept_changes":  updateAcceptChanges,
 	}, nil)
 	defer func() {
 		status := "success"
@@ -45,12 +68,17 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 		al.Log("command.update", status, nil, retErr)
 	}()
 
-	m, err := manifest.Load(paths.ManifestDB)
+	lock, err := acquireManifestLock(paths, al)
+	if err != nil {
+		return err
+	}
+	defer releaseManifestLock(lock, al)
+	recoverTransactions(paths, al)
+
+	m, err := loadManifests(paths, updateView.view(), al)
 	if err != nil {
-		al.Log("manifest.load", "error", nil, err)
-		return fmt.Errorf("loading manifest: %w", err)
+		return err
 	}
-	al.Log("manifest.load", "success", map[string]any{"installations": len(m.Installations)}, nil)
 
 	skills, err := skill.ScanLocal(paths.SkillsRepo)
 	if err != nil {
@@ -59,6 +87,26 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 	}
 	al.Log("skill.scan_local", "success", map[string]any{"path": paths.SkillsRepo, "count": len(skills)}, nil)
 
+	pol, err := loadPolicy(paths)
+	if err != nil {
+		return err
+	}
+	store, err := loadTrustStore(paths)
+	if err != nil {
+		return err
+	}
+	requireSig := signatureRequired(updateRequireSig, pol)
+	scan := scanRequested(updateScan, pol)
+	signatures := make(map[*skill.Skill]error) // signature and scan result of each skill
+	tools := newToolConfirmer(pol, al, updateAcceptTools)
+	fetchOpts, err := remoteFetchOptions(paths)
+	if err != nil {
+		return err
+	}
+	remotes := newRemoteCache(al, fetchOpts)
+	registries := newRegistrySources(paths, al)
+	pins := newPinChecker(fetchOpts, al, updateAcceptChanges)
+
 	// Build skill lookup
 	skillMap := make(map[string]*skill.Skill)
 	for _, s := range skills {
@@ -77,7 +125,7 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 			}
 		}
 	} else {
-		targets = m.Installations
+		targets = m.Installations()
 		if updateClient != "" {
 			targets = m.FindByClient(updateClient)
 		}
@@ -90,20 +138,84 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 	}
 	al.Log("update.targets.resolve", "success", map[string]any{"count": len(targets)}, nil)
 
-	lock := manifest.NewLock(paths.ManifestDB)
-	al.Log("manifest.lock", "started", map[string]any{"path": paths.ManifestDB + ".lock"}, nil)
-	if err := lock.Acquire(5 * time.Second); err != nil {
-		al.Log("manifest.lock", "error", nil, err)
-		fmt.Fprintf(os.Stderr, "warning: could not acquire lock: %v\n", err)
-	} else {
-		al.Log("manifest.lock", "success", nil, nil)
-		defer lock.Release()
-		defer al.Log("manifest.lock", "released", nil, nil)
+	type updateJob struct {
+		inst manifest.Installation
+		s    *skill.Skill
+		opts adapter.InstallOpts
 	}
-
-	updated := 0
+	var work []updateJob
+	var jobs []applyJob
+	blocked, held := 0, 0
+	var items []tui.ProgressItem
 	for _, inst := range targets {
 		s := skillMap[inst.SkillName]
+		if r, ref, ok := skill.ParseRemoteSource(inst.Source); ok {
+			if err := checkSourcePolicy(pol, al, inst.SkillName, inst.Source); err != nil {
+				fmt.Fprintf(os.Stderr, "error updating %s on %s: %v\n", inst.SkillName, inst.ClientID, err)
+				blocked++
+				continue
+			}
+			var err error
+			if s, err = remotes.get(r, ref); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: fetching %s: %v, skipping\n", inst.Source, err)
+				al.LogEvent(audit.Event{
+					Action:   "update.adapter.apply",
+					Status:   "skipped",
+					Skill:    inst.SkillName,
+					ClientID: inst.ClientID,
+					Scope:    inst.Scope,
+					Target:   inst.InstallPath,
+					Error:    err.Error(),
+				})
+				continue
+			}
+			if err := pins.check(inst, s); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v, skipping %s\n", err, inst.ClientID)
+				al.LogEvent(audit.Event{
+					Action:   "update.adapter.apply",
+					Status:   "skipped",
+					Skill:    inst.SkillName,
+					ClientID: inst.ClientID,
+					Scope:    inst.Scope,
+					Target:   inst.InstallPath,
+					Error:    err.Error(),
+				})
+				held++
+				continue
+			}
+		} else if _, _, _, ok := registry.ParseSource(inst.Source); ok {
+			// Never fall back to a local skill of the same name: that would
+			// drop the registry provenance and its digest check.
+			var err error
+			if s, err = registries.latest(inst.Source); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s was installed from a registry and cannot be updated (%v); reinstall it with --registry, skipping\n", inst.SkillName, err)
+				al.LogEvent(audit.Event{
+					Action:   "update.adapter.apply",
+					Status:   "skipped",
+					Skill:    inst.SkillName,
+					ClientID: inst.ClientID,
+					Scope:    inst.Scope,
+					Target:   inst.InstallPath,
+					Error:    err.Error(),
+				})
+				continue
+			}
+		} else if strings.HasPrefix(inst.Source, skill.ArchiveSource("")) {
+			var err error
+			if s, err = loadArchiveSource(paths, al, inst); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s was installed from a package and cannot be updated (%v); reinstall it with aisk install, skipping\n", inst.SkillName, err)
+				al.LogEvent(audit.Event{
+					Action:   "update.adapter.apply",
+					Status:   "skipped",
+					Skill:    inst.SkillName,
+					ClientID: inst.ClientID,
+					Scope:    inst.Scope,
+					Target:   inst.InstallPath,
+					Error:    err.Error(),
+				})
+				continue
+			}
+		}
 		if s == nil {
 			fmt.Fprintf(os.Stderr, "warning: skill %q not found in repo, skipping\n", inst.SkillName)
 			al.LogEvent(audit.Event{
@@ -118,6 +230,49 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 			continue
 		}
 
+		sigErr, checked := signatures[s]
+		if !checked {
+			sigErr = checkSignature(store, al, s, requireSig)
+			if sigErr == nil && scan {
+				sigErr = checkScan(al, s)
+			}
+			signatures[s] = sigErr
+		}
+		if sigErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v, skipping\n", sigErr)
+			al.LogEvent(audit.Event{
+				Action:   "update.adapter.apply",
+				Status:   "skipped",
+				Skill:    inst.SkillName,
+				ClientID: inst.ClientID,
+				Scope:    inst.Scope,
+				Target:   inst.InstallPath,
+				Error:    sigErr.Error(),
+			})
+			continue
+		}
+
+		if err := checkPolicy(pol, al, s, inst.ClientID, inst.Scope); err != nil {
+			fmt.Fprintf(os.Stderr, "error updating %s on %s: %v\n", inst.SkillName, inst.ClientID, err)
+			blocked++
+			continue
+		}
+
+		if err := tools.check(s, inst); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v, skipping %s\n", err, inst.ClientID)
+			al.LogEvent(audit.Event{
+				Action:   "update.adapter.apply",
+				Status:   "skipped",
+				Skill:    inst.SkillName,
+				ClientID: inst.ClientID,
+				Scope:    inst.Scope,
+				Target:   inst.InstallPath,
+				Error:    err.Error(),
+			})
+			held++
+			continue
+		}
+
 		clientID := client.ParseClientID(inst.ClientID)
 		adp, err := adapter.ForClient(clientID)
 		if err != nil {
@@ -134,19 +289,46 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 			continue
 		}
 
-		opts := adapter.InstallOpts{
-			Scope: inst.Scope,
-		}
+		opts := replayOptions(inst, updateOverrides)
+		work = append(work, updateJob{inst: inst, s: s, opts: opts})
+		jobs = append(jobs, applyJob{
+			key: adapterOutputPath(clientID, inst.Scope, s, inst.InstallPath),
+			run: func() error {
+				al.LogEvent(audit.Event{
+					Action:   "update.adapter.apply",
+					Status:   "started",
+					Skill:    inst.SkillName,
+					ClientID: inst.ClientID,
+					Scope:    inst.Scope,
+					Target:   inst.InstallPath,
+				})
+				return adp.Install(s, inst.InstallPath, opts)
+			},
+		})
+		items = append(items, tui.ProgressItem{
+			Label:  fmt.Sprintf("%s on %s", inst.SkillName, inst.ClientID),
+			Detail: inst.InstallPath,
+			Status: tui.StatusPending,
+		})
+	}
 
-		al.LogEvent(audit.Event{
-			Action:   "update.adapter.apply",
-			Status:   "started",
-			Skill:    inst.SkillName,
-			ClientID: inst.ClientID,
-			Scope:    inst.Scope,
-			Target:   inst.InstallPath,
+	var errs []error
+	if len(jobs) > 0 {
+		progress := startProgress("Updating", items)
+		errs = runJobs(jobs, false, func(i int, status tui.ProgressStatus, err error) {
+			detail := ""
+			if err != nil {
+				detail = err.Error()
+			}
+			progress.Set(i, status, detail)
 		})
-		if err := adp.Install(s, inst.InstallPath, opts); err != nil {
+		progress.Stop()
+	}
+
+	updated := 0
+	for i, w := range work {
+		inst, s, opts := w.inst, w.s, w.opts
+		if err := errs[i]; err != nil {
 			fmt.Fprintf(os.Stderr, "error updating %s on %s: %v\n", inst.SkillName, inst.ClientID, err)
 			al.LogEvent(audit.Event{
 				Action:   "update.adapter.apply",
@@ -160,7 +342,7 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 			continue
 		}
 
-		m.Add(manifest.Installation{
+		if err := m.Add(manifest.Installation{
 			SkillName:    inst.SkillName,
 			SkillVersion: s.DisplayVersion(),
 			ClientID:     inst.ClientID,
@@ -168,7 +350,15 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 			InstalledAt:  inst.InstalledAt,
 			UpdatedAt:    time.Now(),
 			InstallPath:  inst.InstallPath,
-		})
+			Source:       s.SourceName(),
+			ContentHash:  skillContentHash(s),
+			FilesHash:    skillFilesHash(s),
+			Options:      installOptions(opts),
+			AllowedTools: grantedTools(s),
+			Commit:       s.Commit,
+		}); err != nil {
+			return fmt.Errorf("recording %s on %s: %w", inst.SkillName, inst.ClientID, err)
+		}
 
 		fmt.Printf("Updated %q on %s (%s -> %s)\n", inst.SkillName, inst.ClientID, inst.SkillVersion, s.DisplayVersion())
 		updated++
@@ -182,6 +372,7 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 			Details: map[string]any{
 				"from_version": inst.SkillVersion,
 				"to_version":   s.DisplayVersion(),
+				"include_refs": opts.IncludeRefs,
 			},
 		})
 	}
@@ -190,8 +381,14 @@ func runUpdate(_ *cobra.Command, args []string) (retErr error) {
 		al.Log("manifest.save", "error", nil, err)
 		return fmt.Errorf("saving manifest: %w", err)
 	}
-	al.Log("manifest.save", "success", map[string]any{"installations": len(m.Installations), "updated": updated}, nil)
+	al.Log("manifest.save", "success", map[string]any{"installations": len(m.Installations()), "updated": updated}, nil)
 
 	fmt.Printf("\n%d installation(s) updated.\n", updated)
+	if blocked > 0 {
+		return fmt.Errorf("%d installation(s) blocked by policy", blocked)
+	}
+	if held > 0 {
+		return fmt.Errorf("%d installation(s) not updated because changes were not confirmed", held)
+	}
 	return nil
 }
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/manifest"
+)
+
+func TestRunUpdate_ReplaysRecordedOptions(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	createTestSkill(t, skillsRepo, "skill-a", "1.0.0")
+	refDir := filepath.Join(skillsRepo, "skill-a", "references")
+	if err := os.MkdirAll(refDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(refDir, "guide.md"), []byte("reference body"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origClient, origScope, origRefs, origDryRun := installClient, installScope, installIncludeRefs, installDryRun
+	origUpdateClient, origOverrides := updateClient, updateOverrides
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun = origClient, origScope, origRefs, origDryRun
+		updateClient, updateOverrides = origUpdateClient, origOverrides
+	})
+	installClient, installScope, installIncludeRefs, installDryRun = "codex", "global", true, false
+	captureStdout(t, func() {
+		if err := runInstall(nil, []string{"skill-a"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	instructions := filepath.Join(home, ".codex", "instructions.md")
+	update := func(o optionOverrides) string {
+		t.Helper()
+		createTestSkill(t, skillsRepo, "skill-a", "1.1.0")
+		updateClient, updateOverrides = "", o
+		captureStdout(t, func() {
+			if err := runUpdate(nil, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+		data, err := os.ReadFile(instructions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if out := update(optionOverrides{}); !strings.Contains(out, "reference body") {
+		t.Fatalf("update dropped --include-refs from the original install:\n%s", out)
+	}
+	if out := update(optionOverrides{noIncludeRefs: true}); strings.Contains(out, "reference body") {
+		t.Fatalf("--no-include-refs override ignored:\n%s", out)
+	}
+
+	m, err := manifest.Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Find("skill-a", "codex"); len(got) != 1 || got[0].Options != nil {
+		t.Fatalf("override should be recorded as default options, got %+v", got)
+	}
+}
+
+func TestRunUpdate_SharedFileKeepsEverySection(t *testing.T) {
+	home := t.TempDir()
+	skillsRepo := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AISK_SKILLS_PATH", skillsRepo)
+	t.Setenv("AISK_AUDIT_ENABLED", "false")
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	origClient, origScope, origRefs, origDryRun := installClient, installScope, installIncludeRefs, installDryRun
+	origUpdateClient, origOverrides := updateClient, updateOverrides
+	t.Cleanup(func() {
+		installClient, installScope, installIncludeRefs, installDryRun = origClient, origScope, origRefs, origDryRun
+		updateClient, updateOverrides = origUpdateClient, origOverrides
+	})
+	installClient, installScope, installIncludeRefs, installDryRun = "codex", "global", false, false
+	names := []string{"skill-a", "skill-b", "skill-c", "skill-d"}
+	for _, name := range names {
+		createTestSkill(t, skillsRepo, name, "1.0.0")
+		captureStdout(t, func() {
+			if err := runInstall(nil, []string{name}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// Every update rewrites the same instructions.md.
+	for _, name := range names {
+		createTestSkill(t, skillsRepo, name, "2.0.0")
+	}
+	updateClient, updateOverrides = "", optionOverrides{}
+	out := captureStdout(t, func() {
+		if err := runUpdate(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(out, "4 installation(s) updated") {
+		t.Fatalf("unexpected update output:\n%s", out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".codex", "instructions.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if strings.Count(string(data), "aisk:start:"+name) != 1 {
+			t.Fatalf("expected exactly one section for %s:\n%s", name, data)
+		}
+	}
+}
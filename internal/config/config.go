@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Default paths and configuration for aisk.
@@ -16,7 +17,12 @@ type Paths struct {
 	Home       string // user home directory
 	AiskDir    string // ~/.aisk/
 	CacheDir   string // ~/.aisk/cache/
-	ManifestDB string // ~/.aisk/manifest.json
+	ManifestDB string // ~/.aisk/manifest.json (global-scope installations)
+	ProjectsDB string // ~/.aisk/projects.json (projects with their own manifest)
+	TxnDir     string // ~/.aisk/txn/ (journals of in-flight --atomic operations)
+	BackupDir  string // ~/.aisk/backups/
+	HostsFile  string // ~/.aisk/hosts.json (per-host API/raw URLs and tokens)
+	TrustDir   string // ~/.aisk/trust/ (public keys trusted to sign skills)
 	SkillsRepo string // local skills repository path
 }
 
@@ -42,6 +48,11 @@ func ResolvePaths() (Paths, error) {
 		AiskDir:    aiskDir,
 		CacheDir:   filepath.Join(aiskDir, "cache"),
 		ManifestDB: filepath.Join(aiskDir, "manifest.json"),
+		ProjectsDB: filepath.Join(aiskDir, "projects.json"),
+		TxnDir:     filepath.Join(aiskDir, "txn"),
+		BackupDir:  filepath.Join(aiskDir, "backups"),
+		HostsFile:  filepath.Join(aiskDir, "hosts.json"),
+		TrustDir:   filepath.Join(aiskDir, "trust"),
 		SkillsRepo: skillsRepo,
 	}, nil
 }
@@ -55,3 +66,30 @@ func (p Paths) EnsureDirs() error {
 	}
 	return nil
 }
+
+// Offline reports whether AISK_OFFLINE asks for remote sources to be served
+// from the cache only.
+func Offline() bool {
+	return envBool("AISK_OFFLINE")
+}
+
+// RequireSignature reports whether AISK_REQUIRE_SIGNATURE asks install and
+// update to accept only skills signed by a trusted key.
+func RequireSignature() bool {
+	return envBool("AISK_REQUIRE_SIGNATURE")
+}
+
+// ScanContent reports whether AISK_SCAN asks install and update to scan
+// skills for secrets and unsafe content.
+func ScanContent() bool {
+	return envBool("AISK_SCAN")
+}
+
+func envBool(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,219 @@
+// Package diff produces line-based unified diffs between text documents.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpKind identifies the kind of a single line edit.
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// Op is one line in an edit script.
+type Op struct {
+	Kind OpKind
+	Text string
+}
+
+// DefaultContext is the number of unchanged lines shown around each change.
+const DefaultContext = 3
+
+// maxEditDistance bounds the search in Lines. The trace it keeps for
+// backtracking grows with the square of the edit distance, so inputs that
+// differ by more lines than this are diffed as one replacement instead.
+const maxEditDistance = 2000
+
+// Lines computes a minimal edit script turning a into b using Myers'
+// algorithm. When more than maxEditDistance lines would change, the script
+// deletes all of a and inserts all of b.
+func Lines(a, b []string) []Op {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD == 0 {
+		return nil
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d] holds v for diagonals -d-1..d+1 as it was before step d,
+	// which is all backtrack reads at that step.
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		if d > maxEditDistance {
+			return replaceAll(a, b)
+		}
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return nil
+}
+
+// replaceAll is the edit script that deletes every line of a, then inserts
+// every line of b.
+func replaceAll(a, b []string) []Op {
+	ops := make([]Op, 0, len(a)+len(b))
+	for _, line := range a {
+		ops = append(ops, Op{Kind: Delete, Text: line})
+	}
+	for _, line := range b {
+		ops = append(ops, Op{Kind: Insert, Text: line})
+	}
+	return ops
+}
+
+func backtrack(trace [][]int, a, b []string) []Op {
+	x, y := len(a), len(b)
+	var reversed []Op
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		window, base := trace[d], d+1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && window[base+k-1] < window[base+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := window[base+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Op{Kind: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			reversed = append(reversed, Op{Kind: Insert, Text: b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, Op{Kind: Delete, Text: a[x-1]})
+			x--
+		}
+	}
+
+	ops := make([]Op, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
+
+// Unified returns a unified diff from one text to another, or "" when they are equal.
+func Unified(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	ops := Lines(SplitLines(from), SplitLines(to))
+
+	// Line positions in a and b before each op.
+	aPos := make([]int, len(ops)+1)
+	bPos := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		switch op.Kind {
+		case Equal:
+			aPos[i+1]++
+			bPos[i+1]++
+		case Delete:
+			aPos[i+1]++
+		case Insert:
+			bPos[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	prevStop := 0
+	i := 0
+	for i < len(ops) {
+		for i < len(ops) && ops[i].Kind == Equal {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+
+		start := max(i-DefaultContext, prevStop)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].Kind != Equal {
+				end = j
+			} else if j-end > 2*DefaultContext {
+				break
+			}
+		}
+		stop := min(end+DefaultContext+1, len(ops))
+
+		aCount := aPos[stop] - aPos[start]
+		bCount := bPos[stop] - bPos[start]
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aPos[start], aCount), hunkRange(bPos[start], bCount))
+		for _, op := range ops[start:stop] {
+			switch op.Kind {
+			case Equal:
+				out.WriteString(" ")
+			case Delete:
+				out.WriteString("-")
+			case Insert:
+				out.WriteString("+")
+			}
+			out.WriteString(op.Text)
+			if !strings.HasSuffix(op.Text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		prevStop = stop
+		i = stop
+	}
+
+	return out.String()
+}
+
+func hunkRange(pos, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", pos)
+	}
+	return fmt.Sprintf("%d,%d", pos+1, count)
+}
+
+// SplitLines splits text into lines, keeping each line's terminator so a
+// missing final newline counts as a difference.
+func SplitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,138 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnified_Equal(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n"); got != "" {
+		t.Fatalf("expected empty diff, got %q", got)
+	}
+}
+
+func TestUnified_SingleChange(t *testing.T) {
+	from := "one\ntwo\nthree\n"
+	to := "one\n2\nthree\n"
+	want := "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n"
+	if got := Unified("a", "b", from, to); got != want {
+		t.Fatalf("diff mismatch:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 30; i++ {
+		line := strings.Repeat("x", i+1)
+		a = append(a, line)
+		b = append(b, line)
+	}
+	b[2] = "changed-early"
+	b[25] = "changed-late"
+
+	got := Unified("a", "b", strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n")
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Fatalf("expected 2 hunks, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,6 +1,6 @@") || !strings.Contains(got, "@@ -23,7 +23,7 @@") {
+		t.Fatalf("unexpected hunk headers:\n%s", got)
+	}
+}
+
+func TestUnified_CreateFromEmpty(t *testing.T) {
+	got := Unified("a", "b", "", "new\n")
+	if !strings.Contains(got, "@@ -0,0 +1,1 @@\n+new\n") {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+}
+
+func TestUnified_MissingTrailingNewline(t *testing.T) {
+	got := Unified("a", "b", "line", "line\n")
+	if !strings.Contains(got, "-line\n\\ No newline at end of file\n+line\n") {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+}
+
+func TestLines_MinimalScript(t *testing.T) {
+	ops := Lines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+	var kinds []OpKind
+	for _, op := range ops {
+		kinds = append(kinds, op.Kind)
+	}
+	want := []OpKind{Equal, Delete, Equal, Insert}
+	if len(kinds) != len(want) {
+		t.Fatalf("ops = %+v", ops)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("ops = %+v", ops)
+		}
+	}
+}
+
+func TestLines_ReconstructsAndStaysMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 200; i++ {
+		a, b := random(), random()
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range Lines(a, b) {
+			if op.Kind != Insert {
+				gotA = append(gotA, op.Text)
+			}
+			if op.Kind != Delete {
+				gotB = append(gotB, op.Text)
+			}
+			if op.Kind != Equal {
+				edits++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("Lines(%q, %q) does not turn one into the other", a, b)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); edits != want {
+			t.Fatalf("Lines(%q, %q) made %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestLines_LargeDistanceReplacesWhole(t *testing.T) {
+	var a, b []string
+	for i := 0; i < maxEditDistance; i++ {
+		a = append(a, fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("new %d", i))
+	}
+	ops := Lines(a, b)
+	if len(ops) != 2*maxEditDistance || ops[0].Kind != Delete || ops[len(ops)-1].Kind != Insert {
+		t.Fatalf("expected one replacement, got %d ops", len(ops))
+	}
+	out := Unified("a", "b", strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n")
+	if want := fmt.Sprintf("@@ -1,%d +1,%d @@", maxEditDistance, maxEditDistance); strings.Count(out, "@@ -") != 1 || !strings.Contains(out, want) {
+		t.Fatalf("expected a single whole-file hunk %q, got %s", want, out[:80])
+	}
+}
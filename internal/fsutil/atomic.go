@@ -0,0 +1,129 @@
+// Package fsutil provides crash-safe file replacement helpers.
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile atomically replaces path with data. The data is written to a
+// temporary file in the same directory, flushed to disk and renamed over the
+// destination, so readers see either the old or the new content, never a
+// truncated file. An existing file keeps its permission bits; new files get perm.
+// A symlinked path is written through: its target is replaced and the link
+// is kept.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	committed = true
+	syncDir(dir)
+	return nil
+}
+
+// TempSibling returns an unused path next to dest for staging a replacement.
+func TempSibling(dest string) (string, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	// Only the unique name is needed; callers create their own entry there.
+	if err := os.Remove(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Swap moves staged (a file, directory or symlink next to dest) into place.
+// Files and symlinks are renamed over each other in a single step. Rename
+// cannot replace across a directory, so in that case the old dest is first
+// moved aside and only deleted once staged is in place; if that fails the old
+// dest is restored.
+func Swap(staged, dest string) error {
+	info, err := os.Lstat(dest)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Rename(staged, dest); err != nil {
+			return err
+		}
+		syncDir(filepath.Dir(dest))
+		return nil
+	}
+
+	stagedInfo, err := os.Lstat(staged)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() && !stagedInfo.IsDir() {
+		if err := os.Rename(staged, dest); err != nil {
+			return err
+		}
+		syncDir(filepath.Dir(dest))
+		return nil
+	}
+
+	old, err := TempSibling(dest)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(dest, old); err != nil {
+		return fmt.Errorf("moving aside %s: %w", dest, err)
+	}
+	if err := os.Rename(staged, dest); err != nil {
+		if restoreErr := os.Rename(old, dest); restoreErr != nil {
+			return fmt.Errorf("%w (restoring %s failed: %v)", err, dest, restoreErr)
+		}
+		return err
+	}
+	syncDir(filepath.Dir(dest))
+	return os.RemoveAll(old)
+}
+
+// syncDir flushes a directory entry update to disk. It is best effort: some
+// platforms (Windows) do not support syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
@@ -0,0 +1,136 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFile_CreatesAndReplaces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "AGENTS.md")
+
+	if err := WriteFile(path, []byte("one"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("two"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "two" {
+		t.Fatalf("content = %q, want %q", data, "two")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected no leftover temp files, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFile_PreservesMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not preserved on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "rules.md")
+	if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("y"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestWriteFile_WritesThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared", "AGENTS.md")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "AGENTS.md")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if err := WriteFile(link, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("the symlink was replaced: %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Fatalf("target content = %q, want %q", data, "new")
+	}
+}
+
+func TestWriteFile_MissingDirFails(t *testing.T) {
+	if err := WriteFile(filepath.Join(t.TempDir(), "nope", "f"), []byte("x"), 0o644); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+}
+
+func TestSwap_ReplacesDirectoryWithSymlink(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "skill")
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dest, "SKILL.md"), []byte("old"), 0o644)
+
+	src := filepath.Join(dir, "src")
+	os.MkdirAll(src, 0o755)
+	os.WriteFile(filepath.Join(src, "SKILL.md"), []byte("new"), 0o644)
+
+	staged, err := TempSibling(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(src, staged); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := Swap(staged, dest); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dest, "SKILL.md"))
+	if string(data) != "new" {
+		t.Fatalf("content = %q, want new", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("expected only skill and src, got %d entries", len(entries))
+	}
+}
+
+func TestSwap_ReplacesSymlinkWithDirectory(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "skill")
+	if err := os.Symlink(t.TempDir(), dest); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	staged, err := TempSibling(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(staged, 0o755)
+	os.WriteFile(filepath.Join(staged, "SKILL.md"), []byte("copied"), 0o644)
+
+	if err := Swap(staged, dest); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(dest)
+	if err != nil || info.Mode()&os.ModeSymlink != 0 || !info.IsDir() {
+		t.Fatalf("expected real directory at dest, got %v %v", info, err)
+	}
+}
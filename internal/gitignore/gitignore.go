@@ -3,6 +3,8 @@ package gitignore
 import (
 	"os"
 	"strings"
+
+	"github.com/yorch/aisk/internal/fsutil"
 )
 
 const (
@@ -34,7 +36,7 @@ func EnsureEntries(gitignorePath string, entries []string) ([]string, error) {
 	allEntries := mergeEntries(existing, entries)
 	newContent := replaceManagedSection(content, allEntries)
 
-	if err := os.WriteFile(gitignorePath, []byte(newContent), 0o644); err != nil {
+	if err := fsutil.WriteFile(gitignorePath, []byte(newContent), 0o644); err != nil {
 		return nil, err
 	}
 
@@ -89,7 +91,7 @@ func RemoveEntries(gitignorePath string, entries []string) ([]string, error) {
 		newContent = replaceManagedSection(content, remainingList)
 	}
 
-	if err := os.WriteFile(gitignorePath, []byte(newContent), 0o644); err != nil {
+	if err := fsutil.WriteFile(gitignorePath, []byte(newContent), 0o644); err != nil {
 		return nil, err
 	}
 
@@ -1,15 +1,33 @@
 package manifest
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
-// Lock provides basic file-based locking for manifest access.
+// ErrLocked is returned when another process holds the manifest lock.
+var ErrLocked = errors.New("manifest is locked by another aisk process")
+
+// LockInfo describes the process holding a lock. It is written into the lock
+// file for diagnostics only; the OS advisory lock is what guards access.
+type LockInfo struct {
+	PID        int       `json:"pid"`
+	Host       string    `json:"host,omitempty"`
+	Command    string    `json:"command,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// Lock is an exclusive OS advisory lock (flock on Unix, LockFileEx on
+// Windows) on a file next to the manifest. The kernel drops the lock when the
+// holding process exits, so a crashed aisk never leaves a stale lock behind.
 type Lock struct {
 	path string
+	f    *os.File
 }
 
 // NewLock creates a lock for the given manifest path.
@@ -17,32 +35,99 @@ func NewLock(manifestPath string) *Lock {
 	return &Lock{path: manifestPath + ".lock"}
 }
 
-// Acquire tries to acquire the lock, waiting up to timeout.
+// Acquire takes the lock, waiting up to timeout for another holder to
+// release it. A zero timeout tries once.
 func (l *Lock) Acquire(timeout time.Duration) error {
+	if l.f != nil {
+		return fmt.Errorf("lock %s already held", filepath.Base(l.path))
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening lock file: %w", err)
+	}
+
 	deadline := time.Now().Add(timeout)
 	for {
-		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
-		if err == nil {
-			_ = f.Close()
-			return nil
+		ok, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("locking %s: %w", filepath.Base(l.path), err)
 		}
-
-		if time.Now().After(deadline) {
-			return fmt.Errorf("could not acquire lock %s within %v", filepath.Base(l.path), timeout)
+		if ok {
+			break
 		}
-
-		// Check if lock is stale (older than 30 seconds)
-		info, err := os.Stat(l.path)
-		if err == nil && time.Since(info.ModTime()) > 30*time.Second {
-			_ = os.Remove(l.path)
-			continue
+		if !time.Now().Before(deadline) {
+			f.Close()
+			holder := "unknown process"
+			if info, err := readLockInfo(l.path); err == nil {
+				holder = info.String()
+			}
+			return fmt.Errorf("%w (held by %s; waited %v)", ErrLocked, holder, timeout)
 		}
-
-		time.Sleep(100 * time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
 	}
+
+	l.f = f
+	l.writeInfo()
+	return nil
 }
 
-// Release removes the lock file.
+// Release unlocks and closes the lock file. The file itself is left in place:
+// removing it would let two processes lock different inodes.
 func (l *Lock) Release() {
-	_ = os.Remove(l.path)
+	if l.f == nil {
+		return
+	}
+	_ = l.f.Truncate(0)
+	_ = unlock(l.f)
+	_ = l.f.Close()
+	l.f = nil
+}
+
+func (l *Lock) writeInfo() {
+	host, _ := os.Hostname()
+	info := LockInfo{PID: os.Getpid(), Host: host, AcquiredAt: time.Now()}
+	if len(os.Args) > 1 {
+		info.Command = strings.Join(os.Args[1:], " ")
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	if err := l.f.Truncate(0); err == nil {
+		_, _ = l.f.WriteAt(data, 0)
+		_ = l.f.Sync()
+	}
+}
+
+// ReadLockInfo returns the holder metadata recorded in a manifest's lock file.
+func ReadLockInfo(manifestPath string) (LockInfo, error) {
+	return readLockInfo(manifestPath + ".lock")
+}
+
+func readLockInfo(path string) (LockInfo, error) {
+	var info LockInfo
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if len(data) == 0 {
+		return info, fmt.Errorf("no lock holder recorded")
+	}
+	err = json.Unmarshal(data, &info)
+	return info, err
+}
+
+func (i LockInfo) String() string {
+	s := fmt.Sprintf("pid %d", i.PID)
+	if i.Host != "" {
+		s += " on " + i.Host
+	}
+	if i.Command != "" {
+		s += fmt.Sprintf(" (aisk %s)", i.Command)
+	}
+	if !i.AcquiredAt.IsZero() {
+		s += " since " + i.AcquiredAt.Format(time.RFC3339)
+	}
+	return s
 }
@@ -0,0 +1,105 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLock_ExclusiveWithHolderInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+
+	first := NewLock(path)
+	if err := first.Acquire(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ReadLockInfo(path)
+	if err != nil {
+		t.Fatalf("reading lock info: %v", err)
+	}
+	if info.PID != os.Getpid() {
+		t.Errorf("lock info pid = %d, want %d", info.PID, os.Getpid())
+	}
+
+	second := NewLock(path)
+	err = second.Acquire(100 * time.Millisecond)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+
+	first.Release()
+	if err := second.Acquire(time.Second); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	second.Release()
+
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Errorf("lock file should be kept after release: %v", err)
+	}
+}
+
+func TestLock_WaitsForRelease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	held := NewLock(path)
+	if err := held.Acquire(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		held.Release()
+	}()
+
+	waiter := NewLock(path)
+	if err := waiter.Acquire(5 * time.Second); err != nil {
+		t.Fatalf("waiter should acquire once the holder releases: %v", err)
+	}
+	waiter.Release()
+}
+
+func TestLock_ConcurrentUpdatesAreSerialized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	const workers = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock := NewLock(path)
+			if err := lock.Acquire(10 * time.Second); err != nil {
+				errs <- err
+				return
+			}
+			defer lock.Release()
+
+			m, err := Load(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			m.Add(Installation{SkillName: fmt.Sprintf("skill-%d", i), ClientID: "codex", Scope: "global"})
+			errs <- m.Save()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Installations) != workers {
+		t.Fatalf("expected %d installations, got %d (lost updates)", workers, len(m.Installations))
+	}
+}
@@ -2,9 +2,12 @@ package manifest
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
 )
 
 // Installation tracks a single skill installation.
@@ -16,17 +19,44 @@ type Installation struct {
 	InstalledAt  time.Time `json:"installed_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	InstallPath  string    `json:"install_path"`
+	Source       string    `json:"source,omitempty"`       // SourceLocal, or the remote the skill came from
+	ContentHash  string    `json:"content_hash,omitempty"` // skill.DirHash of the installed skill
+	Commit       string    `json:"commit,omitempty"`       // commit a remote source was pinned at
+	FilesHash    string    `json:"files_hash,omitempty"`   // skill.FilesHash of the installed skill, hidden files included
+	Options      *Options  `json:"options,omitempty"`      // install options; nil means defaults
+	AllowedTools []string  `json:"allowed_tools"`          // allowed-tools of the installed version; nil if installed before aisk tracked tools
+}
+
+// Installation sources.
+const SourceLocal = "local"
+
+// Options records the install options used for an installation so update
+// can reproduce it.
+type Options struct {
+	IncludeRefs bool `json:"include_refs,omitempty"`
 }
 
 // Manifest holds all tracked installations.
 type Manifest struct {
+	SchemaVersion int            `json:"schema_version"`
 	Installations []Installation `json:"installations"`
-	path          string
+
+	path      string
+	root      string // project root for project manifests, "" for the global one
+	original  []byte // file contents before migration, for the backup
+	migration *MigrationReport
 }
 
-// Load reads the manifest from disk, or returns an empty manifest.
+// Load reads the manifest from disk, or returns an empty manifest. Manifests
+// written with an older schema are migrated in memory; the upgraded form is
+// persisted (after backing up the original) on the next Save.
 func Load(path string) (*Manifest, error) {
-	m := &Manifest{path: path}
+	return LoadWithEnv(path, defaultMigrationEnv())
+}
+
+// LoadWithEnv is Load with an explicit migration environment.
+func LoadWithEnv(path string, env MigrationEnv) (*Manifest, error) {
+	m := &Manifest{path: path, SchemaVersion: SchemaVersion}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -36,28 +66,60 @@ func Load(path string) (*Manifest, error) {
 		return nil, err
 	}
 
+	m.SchemaVersion = 0
 	if err := json.Unmarshal(data, m); err != nil {
 		return nil, err
 	}
+	if m.SchemaVersion == 0 {
+		m.SchemaVersion = 1
+	}
 
+	report, err := m.migrate(env)
+	if err != nil {
+		return nil, err
+	}
+	if report != nil {
+		m.migration = report
+		m.original = data
+	}
 	return m, nil
 }
 
-// Save writes the manifest to disk.
+// Save writes the manifest to disk. The first save of a migrated manifest
+// keeps a copy of the original file at BackupPath.
 func (m *Manifest) Save() error {
 	dir := filepath.Dir(m.path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(m, "", "  ")
+	if m.original != nil {
+		if backup := m.BackupPath(); !fileExists(backup) {
+			if err := fsutil.WriteFile(backup, m.original, 0o644); err != nil {
+				return fmt.Errorf("backing up manifest before migration: %w", err)
+			}
+		}
+		m.original = nil
+	}
+
+	out := m
+	if m.root != "" {
+		out = m.relativeToRoot()
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(m.path, data, 0o644)
+	return fsutil.WriteFile(m.path, data, 0o644)
 }
 
+// Path returns the file the manifest is loaded from and saved to.
+func (m *Manifest) Path() string { return m.path }
+
+// Root returns the project root of a project manifest, or "" for the global one.
+func (m *Manifest) Root() string { return m.root }
+
 // Add records a new installation, replacing any existing entry for the same skill+client+scope.
 func (m *Manifest) Add(inst Installation) {
 	m.Remove(inst.SkillName, inst.ClientID, inst.Scope)
@@ -137,3 +199,8 @@ func (m *Manifest) AllSkillNames() []string {
 	}
 	return names
 }
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,143 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yorch/aisk/internal/config"
+)
+
+// SchemaVersion is the manifest format written by this version of aisk.
+// Manifests without a schema_version field are version 1.
+const SchemaVersion = 3
+
+// MigrationEnv carries context migrations may need.
+type MigrationEnv struct {
+	// ProjectRoot resolves legacy relative project install paths. Older aisk
+	// versions stored them relative to the project they were installed in,
+	// which is assumed to be the current one.
+	ProjectRoot string
+}
+
+// migration upgrades a manifest from one schema version to the next and
+// returns a human-readable line for each change it made.
+type migration struct {
+	from        int
+	description string
+	apply       func(m *Manifest, env MigrationEnv) []string
+}
+
+// migrations must be ordered by from and cover every version below SchemaVersion.
+var migrations = []migration{
+	{from: 1, description: "absolute project install paths; record install source", apply: migrateV1},
+	{from: 2, description: "project installations move to per-project manifests", apply: migrateV2},
+}
+
+// MigrationReport describes what Load did to bring a manifest up to date.
+type MigrationReport struct {
+	From    int
+	To      int
+	Steps   []string // migration descriptions, in order
+	Changes []string
+}
+
+func migrateV1(m *Manifest, env MigrationEnv) []string {
+	var changes []string
+	for i := range m.Installations {
+		inst := &m.Installations[i]
+		label := fmt.Sprintf("%s (%s, %s)", inst.SkillName, inst.ClientID, inst.Scope)
+
+		if inst.Scope == "project" && inst.InstallPath != "" && !filepath.IsAbs(inst.InstallPath) {
+			if env.ProjectRoot == "" {
+				changes = append(changes, fmt.Sprintf("%s: left relative path %q (not inside a project)", label, inst.InstallPath))
+			} else {
+				abs := filepath.Join(env.ProjectRoot, inst.InstallPath)
+				changes = append(changes, fmt.Sprintf("%s: install path %q -> %q", label, inst.InstallPath, abs))
+				inst.InstallPath = abs
+			}
+		}
+		if inst.Source == "" {
+			// Before schema 2, install only read skills from the local repository.
+			inst.Source = SourceLocal
+			changes = append(changes, fmt.Sprintf("%s: source set to %q", label, SourceLocal))
+		}
+	}
+	return changes
+}
+
+// migrateV2 reports where project-scope entries will go. Moving them needs
+// the project manifests, so LoadSet performs the move itself; entries whose
+// project cannot be found stay in the global manifest.
+func migrateV2(m *Manifest, env MigrationEnv) []string {
+	var changes []string
+	for _, inst := range m.Installations {
+		if inst.Scope != "project" {
+			continue
+		}
+		label := fmt.Sprintf("%s (%s, %s)", inst.SkillName, inst.ClientID, inst.Scope)
+
+		root := ""
+		if within(env.ProjectRoot, inst.InstallPath) {
+			root = env.ProjectRoot
+		} else if filepath.IsAbs(inst.InstallPath) {
+			root = config.FindProjectRoot(filepath.Dir(inst.InstallPath))
+		}
+		switch {
+		case root == "":
+			changes = append(changes, fmt.Sprintf("%s: kept in the global manifest (no project found for %q)", label, inst.InstallPath))
+		case filepath.Clean(ProjectPath(root)) == filepath.Clean(m.path):
+			changes = append(changes, fmt.Sprintf("%s: kept in the global manifest (project is the home directory)", label))
+		default:
+			changes = append(changes, fmt.Sprintf("%s: moves to %s", label, ProjectPath(root)))
+		}
+	}
+	return changes
+}
+
+// migrate upgrades m in place to SchemaVersion.
+func (m *Manifest) migrate(env MigrationEnv) (*MigrationReport, error) {
+	if m.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("manifest schema version %d is newer than this aisk supports (%d); upgrade aisk", m.SchemaVersion, SchemaVersion)
+	}
+	if m.SchemaVersion == SchemaVersion {
+		return nil, nil
+	}
+
+	report := &MigrationReport{From: m.SchemaVersion, To: SchemaVersion}
+	for _, mg := range migrations {
+		if mg.from < m.SchemaVersion {
+			continue
+		}
+		if mg.from != m.SchemaVersion {
+			return nil, fmt.Errorf("no migration from manifest schema version %d", m.SchemaVersion)
+		}
+		report.Steps = append(report.Steps, fmt.Sprintf("v%d -> v%d: %s", mg.from, mg.from+1, mg.description))
+		report.Changes = append(report.Changes, mg.apply(m, env)...)
+		m.SchemaVersion = mg.from + 1
+	}
+	if m.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("no migration from manifest schema version %d", m.SchemaVersion)
+	}
+	return report, nil
+}
+
+// Migration returns what Load changed to upgrade an older manifest, or nil
+// when the file was already current.
+func (m *Manifest) Migration() *MigrationReport { return m.migration }
+
+// BackupPath is where Save keeps the pre-migration file of a migrated manifest.
+func (m *Manifest) BackupPath() string {
+	if m.migration == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s.v%d.bak", m.path, m.migration.From)
+}
+
+func defaultMigrationEnv() MigrationEnv {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return MigrationEnv{}
+	}
+	return MigrationEnv{ProjectRoot: config.FindProjectRoot(cwd)}
+}
@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const legacyManifest = `{
+  "installations": [
+    {"skill_name": "a", "skill_version": "1.0.0", "client_id": "codex", "scope": "project", "install_path": "AGENTS.md"},
+    {"skill_name": "b", "skill_version": "1.0.0", "client_id": "claude", "scope": "global", "install_path": "/home/u/.claude/skills"}
+  ]
+}`
+
+func TestLoad_MigratesLegacyManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	os.WriteFile(path, []byte(legacyManifest), 0o644)
+
+	m, err := LoadWithEnv(path, MigrationEnv{ProjectRoot: "/work/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SchemaVersion != SchemaVersion {
+		t.Errorf("schema version = %d, want %d", m.SchemaVersion, SchemaVersion)
+	}
+	report := m.Migration()
+	if report == nil || report.From != 1 || report.To != SchemaVersion {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if got := m.Installations[0].InstallPath; got != filepath.Join("/work/repo", "AGENTS.md") {
+		t.Errorf("project path not made absolute: %q", got)
+	}
+	if m.Installations[1].InstallPath != "/home/u/.claude/skills" {
+		t.Errorf("global path should be unchanged")
+	}
+	for _, inst := range m.Installations {
+		if inst.Source != SourceLocal {
+			t.Errorf("%s: source = %q, want local", inst.SkillName, inst.Source)
+		}
+	}
+
+	// Loading does not touch the file; saving writes it and keeps a backup.
+	if data, _ := os.ReadFile(path); string(data) != legacyManifest {
+		t.Fatal("Load must not rewrite the manifest")
+	}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil || string(backup) != legacyManifest {
+		t.Fatalf("backup missing or wrong: %v", err)
+	}
+	var saved map[string]any
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved["schema_version"] != float64(SchemaVersion) {
+		t.Errorf("saved schema_version = %v", saved["schema_version"])
+	}
+
+	again, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Migration() != nil {
+		t.Error("current manifest should not be migrated again")
+	}
+}
+
+func TestLoad_MigrationOutsideProjectKeepsRelativePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	os.WriteFile(path, []byte(legacyManifest), 0o644)
+
+	m, err := LoadWithEnv(path, MigrationEnv{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Installations[0].InstallPath != "AGENTS.md" {
+		t.Errorf("path should stay relative without a project root")
+	}
+	if !strings.Contains(strings.Join(m.Migration().Changes, "\n"), "left relative path") {
+		t.Errorf("report should mention the unresolved path: %v", m.Migration().Changes)
+	}
+}
+
+func TestLoad_RejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	os.WriteFile(path, []byte(`{"schema_version": 99, "installations": []}`), 0o644)
+
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected newer-schema error, got %v", err)
+	}
+}
+
+func TestLoad_NewManifestIsCurrent(t *testing.T) {
+	m, err := Load(filepath.Join(t.TempDir(), "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SchemaVersion != SchemaVersion || m.Migration() != nil {
+		t.Fatalf("new manifest should be current: %+v", m)
+	}
+}
@@ -0,0 +1,61 @@
+package manifest
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ProjectDir is the directory, relative to a project root, that holds the
+// project's manifest.
+const ProjectDir = ".aisk"
+
+// ProjectPath returns the manifest path for the project rooted at root.
+func ProjectPath(root string) string {
+	return filepath.Join(root, ProjectDir, "manifest.json")
+}
+
+// LoadProject reads the manifest of the project rooted at root, or returns an
+// empty one. Install paths are stored relative to root so the manifest keeps
+// working when the project is moved or cloned elsewhere; in memory they are
+// absolute, like those in the global manifest.
+func LoadProject(root string) (*Manifest, error) {
+	m, err := LoadWithEnv(ProjectPath(root), MigrationEnv{ProjectRoot: root})
+	if err != nil {
+		return nil, err
+	}
+	m.root = root
+	for i := range m.Installations {
+		inst := &m.Installations[i]
+		if inst.InstallPath != "" && !filepath.IsAbs(inst.InstallPath) {
+			inst.InstallPath = filepath.Join(root, filepath.FromSlash(inst.InstallPath))
+		}
+	}
+	return m, nil
+}
+
+// relativeToRoot returns a copy of m with install paths inside the project
+// root rewritten relative to it, as they are stored on disk.
+func (m *Manifest) relativeToRoot() *Manifest {
+	out := *m
+	out.Installations = make([]Installation, len(m.Installations))
+	for i, inst := range m.Installations {
+		if within(m.root, inst.InstallPath) {
+			rel, _ := filepath.Rel(m.root, inst.InstallPath)
+			inst.InstallPath = filepath.ToSlash(rel)
+		}
+		out.Installations[i] = inst
+	}
+	return &out
+}
+
+// within reports whether path is root or inside it.
+func within(root, path string) bool {
+	if root == "" || !filepath.IsAbs(path) {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,417 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yorch/aisk/internal/config"
+	"github.com/yorch/aisk/internal/fsutil"
+)
+
+// View selects which installations a Set exposes.
+type View int
+
+const (
+	ViewDefault     View = iota // global installations plus the current project's
+	ViewGlobal                  // global installations only
+	ViewProject                 // the current project's installations only
+	ViewAllProjects             // global installations plus every known project's
+)
+
+// SetConfig describes where a Set finds its manifests.
+type SetConfig struct {
+	GlobalPath  string // ~/.aisk/manifest.json
+	IndexPath   string // ~/.aisk/projects.json, the projects that have a manifest
+	ProjectRoot string // current project, "" outside one
+	View        View
+}
+
+// Set combines the global manifest with per-project manifests. Global-scope
+// installations live in the global manifest; project-scope ones live in the
+// manifest of the project containing their install path. Every manifest in a
+// Set is guarded by the global manifest lock.
+type Set struct {
+	Global *Manifest
+	// Missing lists indexed projects whose manifest no longer exists, for
+	// example because the project was moved or deleted. They are dropped from
+	// the index on Save.
+	Missing []string
+
+	cfg      SetConfig
+	projects map[string]*Manifest
+	roots    []string // load order of projects
+	dirty    map[*Manifest]bool
+	index    []string
+	moved    int
+}
+
+// Location groups the installations recorded for one place.
+type Location struct {
+	Root          string // project root, "" for global installations
+	Installations []Installation
+}
+
+// LoadSet loads the global manifest and the project manifests cfg.View needs.
+// Project-scope entries left in the global manifest by older versions of aisk
+// are moved to their project's manifest; the move is written on Save.
+func LoadSet(cfg SetConfig) (*Set, error) {
+	if cfg.View == ViewProject && cfg.ProjectRoot == "" {
+		return nil, fmt.Errorf("not inside a project")
+	}
+	global, err := Load(cfg.GlobalPath)
+	if err != nil {
+		return nil, err
+	}
+	s := &Set{
+		Global:   global,
+		cfg:      cfg,
+		projects: make(map[string]*Manifest),
+		dirty:    make(map[*Manifest]bool),
+	}
+	if s.index, err = readIndex(cfg.IndexPath); err != nil {
+		return nil, fmt.Errorf("reading project index: %w", err)
+	}
+
+	if cfg.ProjectRoot != "" {
+		if _, err := s.project(cfg.ProjectRoot); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.View == ViewAllProjects {
+		for _, root := range s.index {
+			if !fileExists(ProjectPath(root)) {
+				s.Missing = append(s.Missing, root)
+				continue
+			}
+			if _, err := s.project(root); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.moveLegacy(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Moved returns the number of legacy project entries moved out of the global
+// manifest while loading.
+func (s *Set) Moved() int { return s.moved }
+
+// Installations returns every installation in the Set's view.
+func (s *Set) Installations() []Installation {
+	var result []Installation
+	for _, loc := range s.Locations() {
+		result = append(result, loc.Installations...)
+	}
+	return result
+}
+
+// Locations returns the installations in view grouped by where they are
+// recorded: global installations first, then each project. Locations
+// without installations are omitted.
+func (s *Set) Locations() []Location {
+	var locs []Location
+	add := func(root string, inst Installation) {
+		for i := range locs {
+			if locs[i].Root == root {
+				locs[i].Installations = append(locs[i].Installations, inst)
+				return
+			}
+		}
+		locs = append(locs, Location{Root: root, Installations: []Installation{inst}})
+	}
+
+	for _, inst := range s.Global.Installations {
+		root := ""
+		if inst.Scope == "project" {
+			root = s.globalProjectRoot(inst)
+		}
+		if s.visible(root) {
+			add(root, inst)
+		}
+	}
+	for _, root := range s.roots {
+		pm := s.projects[root]
+		if pm == s.Global || !s.visible(root) {
+			continue
+		}
+		for _, inst := range pm.Installations {
+			add(root, inst)
+		}
+	}
+	sort.SliceStable(locs, func(i, j int) bool { return locs[i].Root == "" && locs[j].Root != "" })
+	return locs
+}
+
+// globalProjectRoot returns the project of a project-scope entry kept in the
+// global manifest: the home directory project, a legacy relative path (assumed
+// to be the current project), or one whose project can no longer be found.
+func (s *Set) globalProjectRoot(inst Installation) string {
+	if !filepath.IsAbs(inst.InstallPath) {
+		return s.cfg.ProjectRoot
+	}
+	if root := s.rootFor(inst.InstallPath); root != "" {
+		return root
+	}
+	return filepath.Dir(inst.InstallPath)
+}
+
+// visible reports whether installations recorded for root ("" for global
+// ones) are part of the Set's view.
+func (s *Set) visible(root string) bool {
+	switch s.cfg.View {
+	case ViewGlobal:
+		return root == ""
+	case ViewProject:
+		return root != "" && root == s.cfg.ProjectRoot
+	case ViewAllProjects:
+		return true
+	}
+	return root == "" || root == s.cfg.ProjectRoot
+}
+
+// Find returns installations in view matching the skill name, optionally
+// filtered by client.
+func (s *Set) Find(skillName, clientID string) []Installation {
+	var result []Installation
+	for _, inst := range s.Installations() {
+		if inst.SkillName == skillName && (clientID == "" || inst.ClientID == clientID) {
+			result = append(result, inst)
+		}
+	}
+	return result
+}
+
+// FindByClient returns all installations in view for a client.
+func (s *Set) FindByClient(clientID string) []Installation {
+	var result []Installation
+	for _, inst := range s.Installations() {
+		if inst.ClientID == clientID {
+			result = append(result, inst)
+		}
+	}
+	return result
+}
+
+// Add records an installation in the manifest that owns it, replacing any
+// existing entry for the same skill, client and scope there.
+func (s *Set) Add(inst Installation) error {
+	m, err := s.owner(inst)
+	if err != nil {
+		return err
+	}
+	m.Add(inst)
+	s.dirty[m] = true
+	return nil
+}
+
+// Remove deletes an installation from the manifest that owns it.
+func (s *Set) Remove(inst Installation) error {
+	m, err := s.owner(inst)
+	if err != nil {
+		return err
+	}
+	filtered := m.Installations[:0]
+	for _, existing := range m.Installations {
+		if existing.SkillName == inst.SkillName && existing.ClientID == inst.ClientID &&
+			existing.Scope == inst.Scope && existing.InstallPath == inst.InstallPath {
+			continue
+		}
+		filtered = append(filtered, existing)
+	}
+	m.Installations = filtered
+	s.dirty[m] = true
+	return nil
+}
+
+// Files returns the files Save will write, so callers can journal them first.
+func (s *Set) Files() []string {
+	files := []string{s.Global.path}
+	for _, root := range s.roots {
+		if pm := s.projects[root]; pm != s.Global && s.dirty[pm] {
+			files = append(files, pm.path)
+		}
+	}
+	return append(files, s.cfg.IndexPath)
+}
+
+// Save writes the global manifest, every modified project manifest and, when
+// it changed, the project index.
+func (s *Set) Save() error {
+	if err := s.Global.Save(); err != nil {
+		return err
+	}
+	for _, root := range s.roots {
+		pm := s.projects[root]
+		if pm == s.Global || !s.dirty[pm] {
+			continue
+		}
+		if err := pm.Save(); err != nil {
+			return fmt.Errorf("saving %s: %w", pm.path, err)
+		}
+	}
+	return s.saveIndex()
+}
+
+// owner returns the manifest an installation belongs to, loading its
+// project's manifest if needed.
+func (s *Set) owner(inst Installation) (*Manifest, error) {
+	if inst.Scope != "project" || !filepath.IsAbs(inst.InstallPath) {
+		return s.Global, nil
+	}
+	root := s.rootFor(inst.InstallPath)
+	if root == "" {
+		return s.Global, nil
+	}
+	return s.project(root)
+}
+
+// rootFor finds the project an install path belongs to: the current project
+// or an already loaded one if they contain it, otherwise the nearest project
+// root above it.
+func (s *Set) rootFor(path string) string {
+	if within(s.cfg.ProjectRoot, path) {
+		return s.cfg.ProjectRoot
+	}
+	best := ""
+	for _, root := range s.roots {
+		if within(root, path) && len(root) > len(best) {
+			best = root
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return config.FindProjectRoot(filepath.Dir(path))
+}
+
+func (s *Set) project(root string) (*Manifest, error) {
+	if pm, ok := s.projects[root]; ok {
+		return pm, nil
+	}
+	var pm *Manifest
+	if filepath.Clean(ProjectPath(root)) == filepath.Clean(s.Global.path) {
+		// A project rooted at the home directory shares the global manifest.
+		pm = s.Global
+	} else {
+		var err error
+		if pm, err = LoadProject(root); err != nil {
+			return nil, fmt.Errorf("loading project manifest %s: %w", ProjectPath(root), err)
+		}
+	}
+	s.projects[root] = pm
+	s.roots = append(s.roots, root)
+	return pm, nil
+}
+
+// moveLegacy moves project-scope entries recorded in the global manifest by
+// older versions of aisk into their project's manifest. An entry already
+// present in the project manifest wins over the legacy one.
+func (s *Set) moveLegacy() error {
+	kept := s.Global.Installations[:0]
+	for _, inst := range s.Global.Installations {
+		pm, err := s.owner(inst)
+		if err != nil {
+			return err
+		}
+		if pm == s.Global {
+			kept = append(kept, inst)
+			continue
+		}
+		if !hasScope(pm.Find(inst.SkillName, inst.ClientID), inst.Scope) {
+			pm.Installations = append(pm.Installations, inst)
+		}
+		s.dirty[pm] = true
+		s.dirty[s.Global] = true
+		s.moved++
+	}
+	s.Global.Installations = kept
+	return nil
+}
+
+func hasScope(insts []Installation, scope string) bool {
+	for _, inst := range insts {
+		if inst.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type projectIndex struct {
+	Projects []string `json:"projects"`
+}
+
+func readIndex(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var idx projectIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, err
+	}
+	return idx.Projects, nil
+}
+
+// saveIndex records which projects have installations, dropping those that
+// no longer do or whose manifest has disappeared.
+func (s *Set) saveIndex() error {
+	set := make(map[string]bool)
+	for _, root := range s.index {
+		set[root] = true
+	}
+	for _, root := range s.Missing {
+		delete(set, root)
+	}
+	for _, root := range s.roots {
+		if s.projects[root] == s.Global {
+			continue
+		}
+		if len(s.projects[root].Installations) > 0 {
+			set[root] = true
+		} else {
+			delete(set, root)
+		}
+	}
+
+	roots := make([]string, 0, len(set))
+	for root := range set {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	if equalStrings(roots, s.index) {
+		return nil
+	}
+	data, err := json.MarshalIndent(projectIndex{Projects: roots}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.cfg.IndexPath), 0o755); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFile(s.cfg.IndexPath, data, 0o644); err != nil {
+		return fmt.Errorf("saving project index: %w", err)
+	}
+	s.index = roots
+	return nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,207 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func setConfig(home, project string, view View) SetConfig {
+	return SetConfig{
+		GlobalPath:  filepath.Join(home, ".aisk", "manifest.json"),
+		IndexPath:   filepath.Join(home, ".aisk", "projects.json"),
+		ProjectRoot: project,
+		View:        view,
+	}
+}
+
+func TestSet_RoutesInstallationsByScope(t *testing.T) {
+	home, project := t.TempDir(), newProject(t)
+
+	s, err := LoadSet(setConfig(home, project, ViewDefault))
+	if err != nil {
+		t.Fatal(err)
+	}
+	global := Installation{SkillName: "a", ClientID: "claude", Scope: "global", InstallPath: filepath.Join(home, ".claude", "skills")}
+	local := Installation{SkillName: "a", ClientID: "codex", Scope: "project", InstallPath: filepath.Join(project, "AGENTS.md")}
+	for _, inst := range []Installation{global, local} {
+		if err := s.Add(inst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := Load(filepath.Join(home, ".aisk", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Installations) != 1 || g.Installations[0].Scope != "global" {
+		t.Fatalf("global manifest = %+v, want only the global installation", g.Installations)
+	}
+
+	// Project manifests store paths relative to the project root.
+	data, err := os.ReadFile(ProjectPath(project))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw Manifest
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.Installations) != 1 || raw.Installations[0].InstallPath != "AGENTS.md" {
+		t.Fatalf("project manifest = %+v, want relative AGENTS.md", raw.Installations)
+	}
+
+	idx, err := readIndex(filepath.Join(home, ".aisk", "projects.json"))
+	if err != nil || len(idx) != 1 || idx[0] != project {
+		t.Fatalf("index = %v, %v; want [%s]", idx, err, project)
+	}
+}
+
+func TestSet_Views(t *testing.T) {
+	home, project, other := t.TempDir(), newProject(t), newProject(t)
+
+	for _, inst := range []Installation{
+		{SkillName: "g", ClientID: "claude", Scope: "global", InstallPath: "/g"},
+		{SkillName: "p", ClientID: "codex", Scope: "project", InstallPath: filepath.Join(project, "AGENTS.md")},
+		{SkillName: "o", ClientID: "codex", Scope: "project", InstallPath: filepath.Join(other, "AGENTS.md")},
+	} {
+		s, err := LoadSet(setConfig(home, "", ViewDefault))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Add(inst); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		view View
+		want []string
+	}{
+		{ViewDefault, []string{"g", "p"}},
+		{ViewGlobal, []string{"g"}},
+		{ViewProject, []string{"p"}},
+		{ViewAllProjects, []string{"g", "p", "o"}},
+	}
+	for _, tt := range tests {
+		s, err := LoadSet(setConfig(home, project, tt.view))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, inst := range s.Installations() {
+			got = append(got, inst.SkillName)
+		}
+		if !equalStrings(got, tt.want) {
+			t.Errorf("view %d: installations = %v, want %v", tt.view, got, tt.want)
+		}
+	}
+
+	if _, err := LoadSet(setConfig(home, "", ViewProject)); err == nil {
+		t.Fatal("expected --project outside a project to fail")
+	}
+}
+
+func TestSet_SurvivesProjectMove(t *testing.T) {
+	home, project := t.TempDir(), newProject(t)
+	s, err := LoadSet(setConfig(home, project, ViewDefault))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Installation{SkillName: "p", ClientID: "codex", Scope: "project", InstallPath: filepath.Join(project, "AGENTS.md")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Rename(project, moved); err != nil {
+		t.Fatal(err)
+	}
+	s, err = LoadSet(setConfig(home, moved, ViewProject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.Installations()
+	if len(got) != 1 || got[0].InstallPath != filepath.Join(moved, "AGENTS.md") {
+		t.Fatalf("installations after move = %+v", got)
+	}
+
+	// The old location is reported and dropped from the index.
+	s, err = LoadSet(setConfig(home, moved, ViewAllProjects))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Missing) != 1 || s.Missing[0] != project {
+		t.Fatalf("missing = %v, want [%s]", s.Missing, project)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	idx, _ := readIndex(filepath.Join(home, ".aisk", "projects.json"))
+	if len(idx) != 1 || idx[0] != moved {
+		t.Fatalf("index = %v, want [%s]", idx, moved)
+	}
+}
+
+func TestSet_MovesLegacyProjectEntries(t *testing.T) {
+	home, project := t.TempDir(), newProject(t)
+	globalPath := filepath.Join(home, ".aisk", "manifest.json")
+	if err := os.MkdirAll(filepath.Dir(globalPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"schema_version":2,"installations":[` +
+		`{"skill_name":"g","client_id":"claude","scope":"global","install_path":"/g","source":"local"},` +
+		`{"skill_name":"p","client_id":"codex","scope":"project","install_path":` + quote(filepath.Join(project, "AGENTS.md")) + `,"source":"local"}]}`
+	if err := os.WriteFile(globalPath, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSet(setConfig(home, "", ViewDefault))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Moved() != 1 {
+		t.Fatalf("moved = %d, want 1", s.Moved())
+	}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	g, _ := Load(globalPath)
+	if len(g.Installations) != 1 || g.Installations[0].SkillName != "g" {
+		t.Fatalf("global manifest = %+v, want only g", g.Installations)
+	}
+	p, err := LoadProject(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Installations) != 1 || p.Installations[0].SkillName != "p" {
+		t.Fatalf("project manifest = %+v, want p", p.Installations)
+	}
+	if _, err := os.Stat(globalPath + ".v2.bak"); err != nil {
+		t.Fatalf("expected backup of the v2 manifest: %v", err)
+	}
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,328 @@
+// Package policy evaluates organisation guardrails for installs: which
+// clients, scopes and sources skills may be installed from and to, whether
+// they must be signed or scanned, how large they may be, and which
+// allowed-tools entries they may not request.
+//
+// Policies are YAML files at three levels: system-wide, per user
+// (~/.aisk/policy.yaml) and per project (<root>/.aisk/policy.yaml). Every
+// level that exists must permit an install, so a lower level can only
+// tighten what a higher one allows:
+//
+//	clients:
+//	  allow: [claude, codex]
+//	scopes:
+//	  deny: [global]
+//	sources:
+//	  allow: ["local", "github.com/acme/*", "https://skills.acme.com/*"]
+//	require_signature: true
+//	max_skill_size: 2MB
+//	denied_tools: ["Bash", "WebFetch"]
+//	approved_tools: ["Bash(git:*)", "Read*"]
+//	scan_content: true
+//
+// Patterns may use * to match any run of characters and are compared
+// case-insensitively. approved_tools pre-approves tool grants that would
+// otherwise need confirmation when a skill's allowed-tools expand; it is only
+// honoured in system and user policies, so a repository cannot approve
+// grants for itself.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of user and project policy files.
+const FileName = "policy.yaml"
+
+// Level is where a policy file applies.
+type Level string
+
+const (
+	LevelSystem  Level = "system"
+	LevelUser    Level = "user"
+	LevelProject Level = "project"
+)
+
+// File names a policy file and its level.
+type File struct {
+	Path  string
+	Level Level
+}
+
+// Policy is one policy file.
+type Policy struct {
+	Path             string   `yaml:"-"`
+	Level            Level    `yaml:"-"`
+	Clients          Rule     `yaml:"clients"`
+	Scopes           Rule     `yaml:"scopes"`
+	Sources          Rule     `yaml:"sources"`
+	RequireSignature bool     `yaml:"require_signature"`
+	MaxSkillSize     ByteSize `yaml:"max_skill_size"`
+	DeniedTools      []string `yaml:"denied_tools"`
+	ApprovedTools    []string `yaml:"approved_tools"`
+	ScanContent      bool     `yaml:"scan_content"`
+}
+
+// Rule is an allow and deny list of patterns. An empty allow list allows
+// everything the deny list does not name.
+type Rule struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// ByteSize is a size in bytes, written in policy files as a number or with a
+// KB, MB or GB suffix (powers of 1024).
+type ByteSize int64
+
+// UnmarshalYAML parses "512", "512KB", "2MB" or "1GB".
+func (b *ByteSize) UnmarshalYAML(n *yaml.Node) error {
+	s := strings.ToUpper(strings.TrimSpace(n.Value))
+	mult := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if rest, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, mult = strings.TrimSpace(rest), unit.mult
+			break
+		}
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 0 {
+		return fmt.Errorf("line %d: invalid size %q", n.Line, n.Value)
+	}
+	*b = ByteSize(v * mult)
+	return nil
+}
+
+// SystemPath returns the system-wide policy file: AISK_SYSTEM_POLICY, or
+// /etc/aisk/policy.yaml (%ProgramData%\aisk\policy.yaml on Windows).
+func SystemPath() string {
+	if p := os.Getenv("AISK_SYSTEM_POLICY"); p != "" {
+		return p
+	}
+	if runtime.GOOS == "windows" {
+		dir := os.Getenv("ProgramData")
+		if dir == "" {
+			dir = `C:\ProgramData`
+		}
+		return filepath.Join(dir, "aisk", FileName)
+	}
+	return filepath.Join("/etc", "aisk", FileName)
+}
+
+// Set is the policies that apply to one invocation, most general first.
+type Set struct {
+	Policies []*Policy
+}
+
+// Load reads the given policy files, skipping those that do not exist.
+func Load(files ...File) (*Set, error) {
+	set := &Set{}
+	for _, f := range files {
+		path := f.Path
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		p := &Policy{}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parsing policy %s: %w", path, err)
+		}
+		p.Path, p.Level = path, f.Level
+		set.Policies = append(set.Policies, p)
+	}
+	return set, nil
+}
+
+// RequireSignature reports whether any policy requires signed skills.
+func (s *Set) RequireSignature() bool {
+	for _, p := range s.Policies {
+		if p.RequireSignature {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanContent reports whether any policy asks for skills to be scanned for
+// secrets and unsafe content before they are installed.
+func (s *Set) ScanContent() bool {
+	for _, p := range s.Policies {
+		if p.ScanContent {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitsSize reports whether any policy sets max_skill_size, so callers
+// only measure skills when the size matters.
+func (s *Set) LimitsSize() bool {
+	for _, p := range s.Policies {
+		if p.MaxSkillSize > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// UnapprovedTools returns the entries of tools that no system or user
+// policy lists in approved_tools.
+func (s *Set) UnapprovedTools(tools []string) []string {
+	var unapproved []string
+	for _, tool := range tools {
+		approved := false
+		for _, p := range s.Policies {
+			if p.Level == LevelProject {
+				continue
+			}
+			if _, ok := matchAny(p.ApprovedTools, tool); ok {
+				approved = true
+				break
+			}
+		}
+		if !approved {
+			unapproved = append(unapproved, tool)
+		}
+	}
+	return unapproved
+}
+
+// Request describes one install of a skill to a client.
+type Request struct {
+	Skill        string
+	Client       string // client ID, e.g. "claude"
+	Scope        string // "global" or "project"
+	Source       string // skill.Skill.SourceName(), e.g. "local" or "github.com/o/r@main"
+	Size         int64  // total size of the skill's files
+	AllowedTools []string
+}
+
+// Violation is a policy rule that blocks a Request.
+type Violation struct {
+	Path    string // policy file
+	Rule    string // e.g. "clients.deny"
+	Message string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("blocked by policy %s (%s): %s", v.Path, v.Rule, v.Message)
+}
+
+// Check returns the first rule in s that blocks r, or nil.
+func (s *Set) Check(r Request) *Violation {
+	for _, p := range s.Policies {
+		if v := p.check(r); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// CheckSource returns the first sources rule in s that blocks installing
+// from source, or nil. It lets a source be refused before it is fetched.
+func (s *Set) CheckSource(source string) *Violation {
+	for _, p := range s.Policies {
+		if v := p.checkRule("sources", p.Sources, source); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p *Policy) check(r Request) *Violation {
+	violation := func(rule, format string, args ...any) *Violation {
+		return &Violation{Path: p.Path, Rule: rule, Message: fmt.Sprintf(format, args...)}
+	}
+	for _, c := range []struct {
+		field string
+		rule  Rule
+		value string
+	}{
+		{"clients", p.Clients, r.Client},
+		{"scopes", p.Scopes, r.Scope},
+		{"sources", p.Sources, r.Source},
+	} {
+		if v := p.checkRule(c.field, c.rule, c.value); v != nil {
+			return v
+		}
+	}
+	if p.MaxSkillSize > 0 && r.Size > int64(p.MaxSkillSize) {
+		return violation("max_skill_size", "%s is %d bytes, over the limit of %d", r.Skill, r.Size, p.MaxSkillSize)
+	}
+	for _, tool := range r.AllowedTools {
+		if pattern, ok := matchAny(p.DeniedTools, tool); ok {
+			return violation("denied_tools", "%s requests tool %q, which matches %q", r.Skill, tool, pattern)
+		}
+	}
+	return nil
+}
+
+// checkRule returns the violation of an allow/deny rule by value, or nil.
+func (p *Policy) checkRule(field string, rule Rule, value string) *Violation {
+	if pattern, ok := matchAny(rule.Deny, value); ok {
+		return &Violation{Path: p.Path, Rule: field + ".deny", Message: fmt.Sprintf("%s %q matches %q", singular(field), value, pattern)}
+	}
+	if len(rule.Allow) > 0 {
+		if _, ok := matchAny(rule.Allow, value); !ok {
+			return &Violation{Path: p.Path, Rule: field + ".allow", Message: fmt.Sprintf("%s %q is not in %s", singular(field), value, strings.Join(rule.Allow, ", "))}
+		}
+	}
+	return nil
+}
+
+func singular(field string) string {
+	return strings.TrimSuffix(field, "s")
+}
+
+// matchAny returns the first pattern matching value.
+func matchAny(patterns []string, value string) (string, bool) {
+	for _, p := range patterns {
+		if Match(p, value) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Match reports whether value matches pattern, where * matches any run of
+// characters, ignoring case.
+func Match(pattern, value string) bool {
+	pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return strings.HasSuffix(value, last)
+}
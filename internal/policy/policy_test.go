@@ -0,0 +1,141 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"claude", "claude", true},
+		{"claude", "Claude", true},
+		{"claude", "codex", false},
+		{"github.com/acme/*", "github.com/acme/skills@main", true},
+		{"github.com/acme/*", "github.com/acme-evil/skills@main", false},
+		{"*.example.com/*", "ghe.example.com/o/r@v1", true},
+		{"Bash(*)", "Bash(git:*)", true},
+		{"Bash(*)", "Bash", false},
+		{"a*b*c", "abc", true},
+		{"a*a", "a", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	system := writePolicy(t, `
+clients:
+  deny: [windsurf]
+sources:
+  allow: [local, "github.com/acme/*"]
+max_skill_size: 1KB
+denied_tools: ["Bash"]
+`)
+	project := writePolicy(t, `
+scopes:
+  allow: [project]
+require_signature: true
+`)
+	set, err := Load(
+		File{Path: system, Level: LevelSystem},
+		File{Path: filepath.Join(t.TempDir(), "missing.yaml"), Level: LevelUser},
+		File{Path: project, Level: LevelProject},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Policies) != 2 || !set.RequireSignature() {
+		t.Fatalf("loaded %d policies, require signature %v", len(set.Policies), set.RequireSignature())
+	}
+
+	ok := Request{Skill: "s", Client: "claude", Scope: "project", Source: "local", Size: 100, AllowedTools: []string{"Read", "Bash(git:*)"}}
+	if v := set.Check(ok); v != nil {
+		t.Fatalf("allowed request blocked: %v", v)
+	}
+
+	tests := []struct {
+		name   string
+		change func(r *Request)
+		path   string
+		rule   string
+	}{
+		{"denied client", func(r *Request) { r.Client = "windsurf" }, system, "clients.deny"},
+		{"unlisted source", func(r *Request) { r.Source = "github.com/other/skills@main" }, system, "sources.allow"},
+		{"too large", func(r *Request) { r.Size = 2048 }, system, "max_skill_size"},
+		{"denied tool", func(r *Request) { r.AllowedTools = []string{"bash"} }, system, "denied_tools"},
+		{"project scope only", func(r *Request) { r.Scope = "global" }, project, "scopes.allow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ok
+			tt.change(&r)
+			v := set.Check(r)
+			if v == nil || v.Path != tt.path || v.Rule != tt.rule {
+				t.Fatalf("Check = %v, want %s in %s", v, tt.rule, tt.path)
+			}
+		})
+	}
+}
+
+func TestCheckSource(t *testing.T) {
+	set, err := Load(File{Path: writePolicy(t, `
+clients:
+  allow: [claude]
+sources:
+  allow: ["github.com/acme/*"]
+  deny: ["github.com/acme/untrusted@*"]
+`), Level: LevelUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := set.CheckSource("github.com/acme/skills@main"); v != nil {
+		t.Fatalf("allowed source refused: %v", v)
+	}
+	if v := set.CheckSource("github.com/acme/untrusted@main"); v == nil || v.Rule != "sources.deny" {
+		t.Fatalf("expected sources.deny, got %v", v)
+	}
+	if v := set.CheckSource("github.com/evil/skills@main"); v == nil || v.Rule != "sources.allow" {
+		t.Fatalf("expected sources.allow, got %v", v)
+	}
+}
+
+func TestLoad_RejectsUnknownFields(t *testing.T) {
+	if _, err := Load(File{Path: writePolicy(t, "client:\n  allow: [claude]\n")}); err == nil {
+		t.Fatal("expected an error for a misspelled field")
+	}
+	if _, err := Load(File{Path: writePolicy(t, "max_skill_size: lots\n")}); err == nil {
+		t.Fatal("expected an error for an invalid size")
+	}
+	if set, err := Load(File{Path: writePolicy(t, "")}); err != nil || len(set.Policies) != 1 {
+		t.Fatalf("empty policy: %v", err)
+	}
+}
+
+func TestUnapprovedTools(t *testing.T) {
+	user := writePolicy(t, "approved_tools: [\"Bash(git:*)\", \"Read*\"]\n")
+	project := writePolicy(t, "approved_tools: [\"*\"]\n")
+	set, err := Load(File{Path: user, Level: LevelUser}, File{Path: project, Level: LevelProject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := set.UnapprovedTools([]string{"Bash(git:*)", "Read(src/**)", "Bash", "WebFetch"})
+	if len(got) != 2 || got[0] != "Bash" || got[1] != "WebFetch" {
+		t.Fatalf("UnapprovedTools = %q; project approvals must not count", got)
+	}
+}
@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+// BuildOptions controls Build.
+type BuildOptions struct {
+	// Force replaces an archive already published for the same skill
+	// version with different contents instead of failing.
+	Force bool
+	// Now stamps the index; zero means time.Now.
+	Now time.Time
+}
+
+// BuildResult reports what Build did.
+type BuildResult struct {
+	Index     Index
+	Added     []Entry  // archives written or replaced
+	Unchanged []Entry  // versions already in the registry with the same contents
+	Skipped   []string // skill directories without a version
+}
+
+// Build packs every versioned skill in skillsDir into outDir and writes
+// outDir/index.json. Versions already listed in an existing index are kept,
+// so the registry accumulates history; republishing a version with
+// different contents fails unless opts.Force is set.
+func Build(skillsDir, outDir string, opts BuildOptions) (*BuildResult, error) {
+	skills, err := skill.ScanLocal(skillsDir)
+	if err != nil {
+		return nil, fmt.Errorf("scanning skills: %w", err)
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].DirName < skills[j].DirName })
+
+	idx := Index{Version: FormatVersion}
+	indexPath := filepath.Join(outDir, IndexFile)
+	if data, err := os.ReadFile(indexPath); err == nil {
+		existing, err := parseIndex(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing existing %s: %w", indexPath, err)
+		}
+		idx.Skills = existing.Skills
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	byKey := make(map[string]int, len(idx.Skills))
+	for i, e := range idx.Skills {
+		byKey[e.Dir+"@"+e.Version] = i
+	}
+
+	result := &BuildResult{}
+	for _, s := range skills {
+		if s.Version == "" {
+			result.Skipped = append(result.Skipped, s.DirName)
+			continue
+		}
+		if err := skill.ValidateName(s.DirName); err != nil {
+			return nil, fmt.Errorf("%s: directory %w", s.DirName, err)
+		}
+
+		var buf bytes.Buffer
+		if err := skill.PackSkill(s, &buf); err != nil {
+			return nil, fmt.Errorf("packing %s: %w", s.DirName, err)
+		}
+		sum := sha256.Sum256(buf.Bytes())
+		entry := Entry{
+			Name:         s.Frontmatter.Name,
+			Dir:          s.DirName,
+			Version:      s.Version,
+			Description:  s.Frontmatter.Description,
+			AllowedTools: s.Frontmatter.AllowedTools,
+			URL:          path.Join("skills", s.DirName, skill.ArchiveName(s)),
+			SHA256:       hex.EncodeToString(sum[:]),
+			Size:         int64(buf.Len()),
+		}
+
+		key := entry.Dir + "@" + entry.Version
+		if i, ok := byKey[key]; ok {
+			if idx.Skills[i].SHA256 == entry.SHA256 {
+				idx.Skills[i] = entry
+				result.Unchanged = append(result.Unchanged, entry)
+				continue
+			}
+			if !opts.Force {
+				return nil, fmt.Errorf("%s %s is already published with different contents; bump its version or use --force", entry.Dir, entry.Version)
+			}
+		}
+
+		archive := filepath.Join(outDir, filepath.FromSlash(entry.URL))
+		if err := os.MkdirAll(filepath.Dir(archive), 0o755); err != nil {
+			return nil, err
+		}
+		if err := fsutil.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", archive, err)
+		}
+		if i, ok := byKey[key]; ok {
+			idx.Skills[i] = entry
+		} else {
+			byKey[key] = len(idx.Skills)
+			idx.Skills = append(idx.Skills, entry)
+		}
+		result.Added = append(result.Added, entry)
+	}
+
+	sort.SliceStable(idx.Skills, func(i, j int) bool {
+		a, b := idx.Skills[i], idx.Skills[j]
+		if a.Dir != b.Dir {
+			return a.Dir < b.Dir
+		}
+		return skill.CompareVersions(a.Version, b.Version) > 0
+	})
+	idx.GeneratedAt = opts.Now
+	if idx.GeneratedAt.IsZero() {
+		idx.GeneratedAt = time.Now()
+	}
+	idx.GeneratedAt = idx.GeneratedAt.UTC().Truncate(time.Second)
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := fsutil.WriteFile(indexPath, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", indexPath, err)
+	}
+	result.Index = idx
+	return result, nil
+}
@@ -0,0 +1,405 @@
+// Package registry reads and builds static skill registries: an index.json
+// listing skills, their versions, archive URLs and SHA-256 digests, served
+// with the archives from any static web host or a local directory. It gives
+// a skill catalogue without GitHub API access.
+//
+// A registry directory looks like:
+//
+//	index.json
+//	skills/<dir>/<dir>-<version>.tar.gz
+//
+// Archive URLs in the index are relative to the index unless absolute.
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
+	"github.com/yorch/aisk/internal/skill"
+	"gopkg.in/yaml.v3"
+)
+
+// IndexFile is the index name inside a registry directory.
+const IndexFile = "index.json"
+
+// FormatVersion is the index layout written by Build.
+const FormatVersion = 1
+
+// Index lists every published version of every skill in a registry.
+type Index struct {
+	Version     int       `json:"version" yaml:"version"`
+	GeneratedAt time.Time `json:"generated_at" yaml:"generated_at"`
+	Skills      []Entry   `json:"skills" yaml:"skills"`
+}
+
+// Entry is one version of one skill.
+type Entry struct {
+	Name         string   `json:"name" yaml:"name"`
+	Dir          string   `json:"dir" yaml:"dir"` // directory name, also the install key
+	Version      string   `json:"version" yaml:"version"`
+	Description  string   `json:"description,omitempty" yaml:"description,omitempty"`
+	AllowedTools []string `json:"allowed_tools,omitempty" yaml:"allowed_tools,omitempty"`
+	URL          string   `json:"url" yaml:"url"`       // archive, relative to the index or absolute
+	SHA256       string   `json:"sha256" yaml:"sha256"` // hex digest of the archive
+	Size         int64    `json:"size,omitempty" yaml:"size,omitempty"`
+}
+
+// Options controls how a registry is read.
+type Options struct {
+	// CacheDir keeps a copy of each remote index and the extracted archives.
+	CacheDir string
+	// Offline uses only CacheDir and fails with skill.ErrOffline otherwise.
+	Offline bool
+}
+
+// Registry is a loaded index and where it came from.
+type Registry struct {
+	Location string // index URL, or absolute path of a local index
+	Index    Index
+	opts     Options
+}
+
+// IndexLocation returns the index URL or path for a registry location: an
+// http(s) URL or local path naming either the index itself (.json, .yaml,
+// .yml) or the directory holding index.json.
+func IndexLocation(location string) (string, error) {
+	if isURL(location) {
+		if hasIndexExt(location) {
+			return location, nil
+		}
+		return strings.TrimSuffix(location, "/") + "/" + IndexFile, nil
+	}
+	abs, err := filepath.Abs(location)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(abs); err == nil && info.IsDir() {
+		return filepath.Join(abs, IndexFile), nil
+	}
+	return abs, nil
+}
+
+// Load reads the registry at location. A remote index is copied into the
+// cache so it can be read offline.
+func Load(location string, opts Options) (*Registry, error) {
+	index, err := IndexLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{Location: index, opts: opts}
+
+	var data []byte
+	switch {
+	case !isURL(index):
+		data, err = os.ReadFile(index)
+	case opts.Offline:
+		data, err = os.ReadFile(filepath.Join(r.cacheDir(), IndexFile))
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("%s: %w", index, skill.ErrOffline)
+		}
+	default:
+		data, err = download(index)
+		if err == nil && opts.CacheDir != "" {
+			if err := os.MkdirAll(r.cacheDir(), 0o755); err == nil {
+				// Best effort: a failed copy only costs offline access.
+				_ = fsutil.WriteFile(filepath.Join(r.cacheDir(), IndexFile), data, 0o644)
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading registry index: %w", err)
+	}
+
+	idx, err := parseIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", index, err)
+	}
+	r.Index = *idx
+	return r, nil
+}
+
+// parseIndex decodes an index in JSON or YAML.
+func parseIndex(data []byte) (*Index, error) {
+	var idx Index
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(data, &idx)
+	} else {
+		err = yaml.Unmarshal(data, &idx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if idx.Version < 1 || idx.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported index version %d (this aisk reads up to %d)", idx.Version, FormatVersion)
+	}
+	for i, e := range idx.Skills {
+		if e.Name == "" || e.Dir == "" || e.Version == "" || e.URL == "" || e.SHA256 == "" {
+			return nil, fmt.Errorf("skill %d: name, dir, version, url and sha256 are required", i+1)
+		}
+		if err := skill.ValidateName(e.Dir); err != nil {
+			return nil, fmt.Errorf("skill %d: %w", i+1, err)
+		}
+	}
+	return &idx, nil
+}
+
+// Latest returns the newest version of each skill, sorted by name.
+func (r *Registry) Latest() []Entry {
+	newest := make(map[string]Entry)
+	for _, e := range r.Index.Skills {
+		if cur, ok := newest[e.Dir]; !ok || skill.CompareVersions(e.Version, cur.Version) > 0 {
+			newest[e.Dir] = e
+		}
+	}
+	entries := make([]Entry, 0, len(newest))
+	for _, e := range newest {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Find returns the entry for a skill name or directory at version, or the
+// newest version when version is empty.
+func (r *Registry) Find(name, version string) (Entry, error) {
+	var found *Entry
+	for i, e := range r.Index.Skills {
+		if e.Name != name && e.Dir != name {
+			continue
+		}
+		if version != "" && e.Version == version {
+			return e, nil
+		}
+		if version == "" && (found == nil || skill.CompareVersions(e.Version, found.Version) > 0) {
+			found = &r.Index.Skills[i]
+		}
+	}
+	if found == nil {
+		if version != "" {
+			return Entry{}, fmt.Errorf("skill %s@%s not found in registry %s", name, version, r.Location)
+		}
+		return Entry{}, fmt.Errorf("skill %q not found in registry %s", name, r.Location)
+	}
+	return *found, nil
+}
+
+// Skills returns the newest version of each skill as metadata-only skills,
+// for listing.
+func (r *Registry) Skills() []*skill.Skill {
+	var skills []*skill.Skill
+	for _, e := range r.Latest() {
+		skills = append(skills, &skill.Skill{
+			Frontmatter: skill.Frontmatter{
+				Name:         e.Name,
+				Description:  e.Description,
+				Version:      e.Version,
+				AllowedTools: e.AllowedTools,
+			},
+			DirName: e.Dir,
+			Source:  skill.SourceRegistry,
+			Origin:  r.Source(e),
+		})
+	}
+	return skills
+}
+
+// Source returns the key installations of e record as their source, e.g.
+// "https://skills.example.com/index.json#my-skill@1.2.0".
+func (r *Registry) Source(e Entry) string {
+	return r.Location + "#" + e.Dir + "@" + e.Version
+}
+
+// ParseSource splits a key written by Source into the index location, skill
+// directory and version. It fails for sources that are not from a registry.
+func ParseSource(source string) (location, dir, version string, ok bool) {
+	i := strings.LastIndex(source, "#")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	dir, version, ok = strings.Cut(source[i+1:], "@")
+	if !ok || dir == "" || version == "" {
+		return "", "", "", false
+	}
+	return source[:i], dir, version, true
+}
+
+// ArchiveURL resolves e.URL against the index location. A remote index may
+// only name http(s) URLs or references relative to itself; absolute local
+// paths are accepted from local indexes alone, so a downloaded index cannot
+// point aisk at files on this machine.
+func (r *Registry) ArchiveURL(e Entry) (string, error) {
+	if isURL(e.URL) {
+		return e.URL, nil
+	}
+	if isURL(r.Location) {
+		base, err := neturl.Parse(r.Location)
+		if err != nil {
+			return "", err
+		}
+		ref, err := neturl.Parse(e.URL)
+		if err != nil || ref.Scheme != "" || ref.Host != "" || strings.HasPrefix(e.URL, "/") || strings.HasPrefix(e.URL, `\`) || filepath.VolumeName(e.URL) != "" {
+			return "", fmt.Errorf("archive %q of a remote index must be an http(s) URL or relative to the index", e.URL)
+		}
+		return base.ResolveReference(ref).String(), nil
+	}
+	if filepath.IsAbs(e.URL) {
+		return e.URL, nil
+	}
+	rel := path.Clean(e.URL)
+	if strings.HasPrefix(rel, "../") || rel == ".." {
+		return "", fmt.Errorf("archive %q is outside the registry directory", e.URL)
+	}
+	return filepath.Join(filepath.Dir(r.Location), filepath.FromSlash(rel)), nil
+}
+
+// Fetch returns e extracted into its cache entry, downloading and verifying
+// the archive against the index digest unless the entry already holds it.
+func (r *Registry) Fetch(e Entry) (*skill.Skill, error) {
+	source := r.Source(e)
+	dest := filepath.Join(r.cacheDir(), e.Dir+"@"+neturl.PathEscape(e.Version))
+
+	cached, err := skill.LoadCacheEntry(dest)
+	if err == nil {
+		err = skill.VerifyCacheEntry(cached)
+	}
+	if err == nil && cached.Meta.Archive == e.SHA256 {
+		return r.load(dest, e)
+	}
+
+	archiveURL, err := r.ArchiveURL(e)
+	if err != nil {
+		return nil, err
+	}
+	if r.opts.Offline && isURL(archiveURL) {
+		return nil, fmt.Errorf("%s: %w", source, skill.ErrOffline)
+	}
+	var data []byte
+	if isURL(archiveURL) {
+		data, err = download(archiveURL)
+	} else {
+		data, err = os.ReadFile(archiveURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", source, err)
+	}
+	if err := verifyArchive(data, e); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return nil, err
+	}
+	staged, err := fsutil.TempSibling(dest)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staged)
+	if err := os.Mkdir(staged, 0o755); err != nil {
+		return nil, err
+	}
+	if err := skill.ExtractArchive(bytes.NewReader(data), staged); err != nil {
+		return nil, fmt.Errorf("extracting %s: %w", source, err)
+	}
+	// Archives packed before the manifest existed have none; the index
+	// digest already covers them.
+	if _, err := skill.VerifyPackManifest(staged); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if _, err := skill.LoadLocal(staged); err != nil {
+		return nil, fmt.Errorf("%s: archive has no valid SKILL.md: %w", source, err)
+	}
+
+	digest, err := skill.DirHash(staged)
+	if err != nil {
+		return nil, err
+	}
+	meta := skill.CacheMeta{
+		Source:    source,
+		Ref:       e.Version,
+		Archive:   e.SHA256,
+		FetchedAt: time.Now().UTC(),
+		Digest:    digest,
+	}
+	if err := skill.WriteCacheMeta(staged, meta); err != nil {
+		return nil, err
+	}
+	if err := fsutil.Swap(staged, dest); err != nil {
+		return nil, fmt.Errorf("replacing cached %s: %w", source, err)
+	}
+	return r.load(dest, e)
+}
+
+func (r *Registry) load(dir string, e Entry) (*skill.Skill, error) {
+	s, err := skill.LoadLocal(dir)
+	if err != nil {
+		return nil, err
+	}
+	s.DirName = e.Dir
+	s.Source = skill.SourceRegistry
+	s.Origin = r.Source(e)
+	return s, nil
+}
+
+// cacheDir holds this registry's index copy and extracted archives.
+func (r *Registry) cacheDir() string {
+	sum := sha256.Sum256([]byte(r.Location))
+	return filepath.Join(r.opts.CacheDir, "registry", hex.EncodeToString(sum[:6]))
+}
+
+// verifyArchive checks data against the size and digest in e.
+func verifyArchive(data []byte, e Entry) error {
+	if e.Size > 0 && int64(len(data)) != e.Size {
+		return fmt.Errorf("archive is %d bytes, index says %d", len(data), e.Size)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, strings.TrimPrefix(e.SHA256, "sha256:")) {
+		return fmt.Errorf("archive digest %s does not match the index (%s)", got, e.SHA256)
+	}
+	return nil
+}
+
+func download(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "aisk/0.1.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func hasIndexExt(s string) bool {
+	switch strings.ToLower(path.Ext(s)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yorch/aisk/internal/skill"
+)
+
+func writeSkill(t *testing.T, repo, dir, version, body string) {
+	t.Helper()
+	path := filepath.Join(repo, dir)
+	if err := os.MkdirAll(filepath.Join(path, "reference"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	md := "---\nname: " + dir + "\ndescription: d\nversion: " + version + "\n---\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(path, "SKILL.md"), []byte(md), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "reference", "guide.md"), []byte("guide"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuild_AccumulatesVersions(t *testing.T) {
+	repo, out := t.TempDir(), t.TempDir()
+	writeSkill(t, repo, "alpha", "1.0.0", "# v1")
+	writeSkill(t, repo, "unversioned", "", "# x")
+
+	res, err := Build(repo, out, BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Added) != 1 || len(res.Skipped) != 1 || res.Skipped[0] != "unversioned" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	// Rebuilding unchanged contents is a no-op with identical archives.
+	res, err = Build(repo, out, BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Added) != 0 || len(res.Unchanged) != 1 {
+		t.Fatalf("rebuild should leave the archive alone, got %+v", res)
+	}
+
+	// Changing contents without a version bump is refused.
+	writeSkill(t, repo, "alpha", "1.0.0", "# changed")
+	if _, err := Build(repo, out, BuildOptions{}); err == nil || !strings.Contains(err.Error(), "bump its version") {
+		t.Fatalf("expected a republish error, got %v", err)
+	}
+
+	writeSkill(t, repo, "alpha", "1.1.0", "# v1.1")
+	res, err = Build(repo, out, BuildOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Index.Skills) != 2 || res.Index.Skills[0].Version != "1.1.0" {
+		t.Fatalf("expected both versions, newest first, got %+v", res.Index.Skills)
+	}
+}
+
+func TestLoadAndFetch_LocalDirectory(t *testing.T) {
+	repo, out := t.TempDir(), t.TempDir()
+	writeSkill(t, repo, "alpha", "1.0.0", "# v1")
+	if _, err := Build(repo, out, BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	writeSkill(t, repo, "alpha", "1.2.0", "# v1.2")
+	if _, err := Build(repo, out, BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Load(out, Options{CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := r.Latest(); len(latest) != 1 || latest[0].Version != "1.2.0" {
+		t.Fatalf("unexpected latest %+v", latest)
+	}
+	e, err := r.Find("alpha", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := r.Fetch(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Source != skill.SourceRegistry || s.Version != "1.0.0" || len(s.ReferenceFiles) != 1 || !strings.HasSuffix(s.SourceName(), "#alpha@1.0.0") {
+		t.Fatalf("unexpected skill %+v", s)
+	}
+	if _, err := r.Find("alpha", "9.9.9"); err == nil {
+		t.Fatal("expected an error for a missing version")
+	}
+}
+
+func TestFetch_HTTPVerifiesDigestAndWorksOffline(t *testing.T) {
+	repo, out := t.TempDir(), t.TempDir()
+	writeSkill(t, repo, "alpha", "1.0.0", "# v1")
+	if _, err := Build(repo, out, BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.FileServer(http.Dir(out)))
+	defer srv.Close()
+
+	cache := t.TempDir()
+	r, err := Load(srv.URL+"/", Options{CacheDir: cache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Location != srv.URL+"/index.json" {
+		t.Fatalf("unexpected index location %q", r.Location)
+	}
+	e, _ := r.Find("alpha", "")
+	if _, err := r.Fetch(e); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := e
+	tampered.Version = "1.0.1" // new cache entry, same archive
+	tampered.SHA256 = strings.Repeat("0", 64)
+	if _, err := r.Fetch(tampered); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected a digest mismatch, got %v", err)
+	}
+
+	srv.Close()
+	offline, err := Load(srv.URL+"/", Options{CacheDir: cache, Offline: true})
+	if err != nil {
+		t.Fatalf("offline load should use the cached index: %v", err)
+	}
+	if _, err := offline.Fetch(e); err != nil {
+		t.Fatalf("offline fetch should use the cache entry: %v", err)
+	}
+	if _, err := offline.Fetch(tampered); !errors.Is(err, skill.ErrOffline) {
+		t.Fatalf("expected ErrOffline, got %v", err)
+	}
+	if _, err := Load("http://example.invalid/reg", Options{CacheDir: cache, Offline: true}); !errors.Is(err, skill.ErrOffline) {
+		t.Fatalf("expected ErrOffline for an uncached index, got %v", err)
+	}
+}
+
+func TestParseIndex_YAMLAndVersion(t *testing.T) {
+	yml := "version: 1\nskills:\n  - name: alpha\n    dir: alpha\n    version: 1.0.0\n    url: skills/alpha/alpha-1.0.0.tar.gz\n    sha256: abc\n"
+	idx, err := parseIndex([]byte(yml))
+	if err != nil || len(idx.Skills) != 1 || idx.Skills[0].URL != "skills/alpha/alpha-1.0.0.tar.gz" {
+		t.Fatalf("unexpected YAML parse %+v, %v", idx, err)
+	}
+	if _, err := parseIndex([]byte(`{"version": 2, "skills": []}`)); err == nil {
+		t.Fatal("expected an error for a newer format")
+	}
+	if _, err := parseIndex([]byte(`{"version": 1, "skills": [{"name": "x", "dir": "../x", "version": "1", "url": "u", "sha256": "s"}]}`)); err == nil {
+		t.Fatal("expected an error for an unsafe directory name")
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	r := &Registry{Location: "https://skills.example.com/index.json"}
+	location, dir, version, ok := ParseSource(r.Source(Entry{Dir: "review", Version: "1.2.0"}))
+	if !ok || location != r.Location || dir != "review" || version != "1.2.0" {
+		t.Fatalf("ParseSource = %q, %q, %q, %v", location, dir, version, ok)
+	}
+	for _, source := range []string{"local", "github.com/o/r@main", "archive:/tmp/x-1.0.0.tar.gz", "#x@1", "index.json#x"} {
+		if _, _, _, ok := ParseSource(source); ok {
+			t.Errorf("ParseSource(%q) should fail", source)
+		}
+	}
+}
+
+func TestArchiveURL(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "index.json")
+	abs := filepath.Join(t.TempDir(), "x-1.0.0.tar.gz")
+	tests := []struct {
+		location, url, want string
+	}{
+		{"https://skills.example.com/r/index.json", "x-1.0.0.tar.gz", "https://skills.example.com/r/x-1.0.0.tar.gz"},
+		{"https://skills.example.com/r/index.json", "/etc/x.tar.gz", ""},
+		{"https://skills.example.com/r/index.json", "https://cdn.example.com/x.tar.gz", "https://cdn.example.com/x.tar.gz"},
+		{"https://skills.example.com/r/index.json", "file:///etc/passwd", ""},
+		{"https://skills.example.com/r/index.json", "//evil.example.com/x.tar.gz", ""},
+		{"https://skills.example.com/r/index.json", `C:\Users\me\x.tar.gz`, ""},
+		{local, "archives/x.tar.gz", filepath.Join(filepath.Dir(local), "archives", "x.tar.gz")},
+		{local, abs, abs},
+		{local, "https://cdn.example.com/x.tar.gz", "https://cdn.example.com/x.tar.gz"},
+		{local, "../x.tar.gz", ""},
+	}
+	for _, tt := range tests {
+		r := &Registry{Location: tt.location}
+		got, err := r.ArchiveURL(Entry{URL: tt.url})
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ArchiveURL(%q from %q) = %q, want an error", tt.url, tt.location, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ArchiveURL(%q from %q) = %q, %v, want %q", tt.url, tt.location, got, err, tt.want)
+		}
+	}
+
+	// An absolute local path from a remote index is refused.
+	r := &Registry{Location: "https://skills.example.com/r/index.json"}
+	if got, err := r.ArchiveURL(Entry{URL: abs}); err == nil {
+		t.Errorf("ArchiveURL(%q) from a remote index = %q, want an error", abs, got)
+	}
+}
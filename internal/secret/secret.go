@@ -0,0 +1,127 @@
+// Package secret recognises credentials: the field names the audit log
+// redacts and the token formats the skill scanner reports.
+package secret
+
+import (
+	"regexp"
+	"strings"
+)
+
+// keyNames are regular expressions for field names that hold credentials.
+var keyNames = []string{
+	`token`,
+	`secret`,
+	`password`,
+	`passwd`,
+	`authorization`,
+	`api[_-]?key`,
+	`access[_-]?key`,
+	`private[_-]?key`,
+	`credential`,
+}
+
+// KeyPattern matches any of the credential field names. It has one
+// capturing group and no anchors, so it can be embedded in other patterns.
+var KeyPattern = `(` + strings.Join(keyNames, `|`) + `)`
+
+var keyPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(keyNames))
+	for i, name := range keyNames {
+		patterns[i] = regexp.MustCompile(`(?i)` + name)
+	}
+	return patterns
+}()
+
+// IsSensitiveKey reports whether a field name suggests a credential value.
+func IsSensitiveKey(k string) bool {
+	for _, p := range keyPatterns {
+		if p.MatchString(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Format is a recognisable credential format.
+type Format struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Certain is set for formats specific enough that a match is almost
+	// surely a real credential; others are only likely.
+	Certain bool
+}
+
+// Formats are the credential formats Find looks for, most specific first.
+var Formats = []Format{
+	{"private key", regexp.MustCompile(`-----BEGIN (?:(?:RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY(?: BLOCK)?-----`), true},
+	{"AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), true},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`), true},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`), true},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), true},
+	{"Anthropic API key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`), true},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{32,}`), true},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), true},
+	{"Stripe key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{20,}`), true},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), false},
+	{"bearer token", regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]{16,}=*)`), false},
+	{"hard-coded credential", regexp.MustCompile(`(?i)\b[A-Za-z0-9_]*` + KeyPattern + `[A-Za-z0-9_]*["']?\s*[:=]\s*["']([^"'\s]{8,})["']`), false},
+}
+
+// Match is a credential found in a line of text.
+type Match struct {
+	Format *Format
+	Value  string // the credential itself
+	Column int    // 1-based byte offset of the match
+}
+
+// Find returns the first credential in line, or nil. Placeholder values
+// such as "<your-token>", "${TOKEN}" or "changeme" are ignored.
+func Find(line string) *Match {
+	for i := range Formats {
+		f := &Formats[i]
+		for _, loc := range f.Pattern.FindAllStringSubmatchIndex(line, -1) {
+			// The value is the last capturing group of patterns that have one.
+			start, end := loc[0], loc[1]
+			if n := len(loc); n > 2 && loc[n-2] >= 0 {
+				start, end = loc[n-2], loc[n-1]
+			}
+			value := line[start:end]
+			if !f.Certain && isPlaceholder(value) {
+				continue
+			}
+			return &Match{Format: f, Value: value, Column: loc[0] + 1}
+		}
+	}
+	return nil
+}
+
+var placeholderWords = []string{"example", "your", "changeme", "placeholder", "redacted", "dummy", "xxxx", "****", "...", "<", "${", "{{", "$("}
+
+func isPlaceholder(v string) bool {
+	lower := strings.ToLower(v)
+	for _, w := range placeholderWords {
+		if strings.Contains(lower, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask shortens a credential to its first four characters, so reports can
+// point at it without repeating it.
+func Mask(v string) string {
+	if len(v) <= 4 {
+		return "****"
+	}
+	return v[:4] + "****"
+}
+
+// Redact replaces every certain credential in s with [REDACTED].
+func Redact(s string) string {
+	for _, f := range Formats {
+		if f.Certain {
+			s = f.Pattern.ReplaceAllString(s, "[REDACTED]")
+		}
+	}
+	return s
+}
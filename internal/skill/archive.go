@@ -0,0 +1,457 @@
+package skill
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
+)
+
+// PackManifestFile is written at the root of every archive PackSkill
+// produces. Like every aisk metadata file it is ignored by FileDigests.
+const PackManifestFile = ".aisk-pack.json"
+
+// ErrNotRegular reports a symlink or special file in a skill directory.
+// What such a file points at would escape every digest, so skills may only
+// contain regular files.
+var ErrNotRegular = errors.New("skills may only contain regular files")
+
+// metadataPrefix starts the names of the metadata files aisk keeps at the
+// root of a skill directory (PackManifestFile, the cache metadata).
+const metadataPrefix = ".aisk-"
+
+// PackFormatVersion is the PackManifest layout written by PackSkill.
+const PackFormatVersion = 1
+
+// PackManifest lists the files of a packed skill with their digests, so an
+// extracted archive can be checked file by file.
+type PackManifest struct {
+	Format  int        `json:"format"`
+	Name    string     `json:"name"`
+	Dir     string     `json:"dir"`
+	Version string     `json:"version"`
+	Files   []PackFile `json:"files"`
+}
+
+// PackFile is one file in a PackManifest.
+type PackFile struct {
+	Path   string `json:"path"` // slash-separated, relative to the skill directory
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// ArchiveName returns the file name PackSkill output is stored under,
+// "<dir>-<version>.tar.gz".
+func ArchiveName(s *Skill) string {
+	return archivePrefix(s) + ".tar.gz"
+}
+
+func archivePrefix(s *Skill) string {
+	return s.DirName + "-" + s.DisplayVersion()
+}
+
+// PackSkill writes s as a gzipped tarball with entries under
+// "<dir>-<version>/" and a PackManifestFile of every file's digest. It packs
+// the files FileDigests lists, and zeroes timestamps and ownership and sorts
+// entries, so packing the same contents twice gives byte-identical archives.
+func PackSkill(s *Skill, w io.Writer) error {
+	root, err := filepath.EvalSymlinks(s.Path)
+	if err != nil {
+		return err
+	}
+	files, err := FileDigests(root)
+	if err != nil {
+		return err
+	}
+
+	manifest := PackManifest{Format: PackFormatVersion, Name: s.Frontmatter.Name, Dir: s.DirName, Version: s.Version, Files: files}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	prefix := archivePrefix(s)
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	epoch := time.Unix(0, 0).UTC()
+	dirs := make(map[string]bool)
+	addDir := func(name string) error {
+		if dirs[name] {
+			return nil
+		}
+		dirs[name] = true
+		return tw.WriteHeader(&tar.Header{Name: name + "/", Typeflag: tar.TypeDir, Mode: 0o755, ModTime: epoch, Format: tar.FormatPAX})
+	}
+	if err := addDir(prefix); err != nil {
+		return err
+	}
+	hdr := &tar.Header{Name: prefix + "/" + PackManifestFile, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(manifestData)), ModTime: epoch, Format: tar.FormatPAX}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestData); err != nil {
+		return err
+	}
+	for _, f := range files {
+		rel := f.Path
+		// Parent directories first, so extractors that need them are happy.
+		parts := strings.Split(rel, "/")
+		for i := 1; i < len(parts); i++ {
+			if err := addDir(prefix + "/" + strings.Join(parts[:i], "/")); err != nil {
+				return err
+			}
+		}
+		if err := packFile(tw, filepath.Join(root, filepath.FromSlash(rel)), prefix+"/"+rel, epoch); err != nil {
+			return fmt.Errorf("packing %s: %w", rel, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// walkSkill calls fn for each non-directory entry under root that an install
+// copies or links: hidden ones included, except .git directories and aisk's
+// own metadata at the root. rel is slash-separated.
+func walkSkill(root string, fn func(p, rel string, d fs.DirEntry) error) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case d.IsDir():
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		case !strings.Contains(rel, "/") && strings.HasPrefix(rel, metadataPrefix):
+			return nil
+		}
+		return fn(p, rel, d)
+	})
+}
+
+// skillFiles returns the sorted, slash-separated paths of the files under
+// root that walkSkill visits. Symlinks and other special files are refused,
+// since what they point at would escape every digest; the error wraps
+// ErrNotRegular.
+func skillFiles(root string) ([]string, error) {
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	var files []string
+	err := walkSkill(root, func(_, rel string, d fs.DirEntry) error {
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			return fmt.Errorf("%s is a symlink: %w", rel, ErrNotRegular)
+		case !d.Type().IsRegular():
+			return fmt.Errorf("%s is a special file: %w", rel, ErrNotRegular)
+		}
+		files = append(files, rel)
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// DirSize returns the total size of the files under dir that walkSkill
+// visits. Unlike FileDigests it accepts symlinks: a link to a file counts as
+// the file it points at, and links to anything else count as nothing.
+func DirSize(dir string) (int64, error) {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	var size int64
+	err := walkSkill(dir, func(p, _ string, d fs.DirEntry) error {
+		info, err := os.Stat(p)
+		if err != nil {
+			if d.Type()&fs.ModeSymlink != 0 {
+				return nil // dangling link
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// FileDigests returns the SHA-256 digest and size of every file of the skill
+// directory dir, as listed by skillFiles, sorted by path. It fails when the
+// directory holds a symlink.
+func FileDigests(dir string) ([]PackFile, error) {
+	paths, err := skillFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]PackFile, 0, len(paths))
+	for _, rel := range paths {
+		sum, size, err := fileDigest(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, PackFile{Path: rel, SHA256: sum, Size: size})
+	}
+	return files, nil
+}
+
+// FilesHash returns a "sha256:<hex>" digest over FileDigests(dir): unlike
+// DirHash it covers hidden files, so it changes whenever any file an install
+// copies does.
+func FilesHash(dir string) (string, error) {
+	files, err := FileDigests(dir)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, f := range files {
+		fmt.Fprintf(h, "%s\x00%d\x00%s\n", f.Path, f.Size, f.SHA256)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fileDigest(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// VerifyPackManifest checks the skill extracted at dir against its
+// PackManifestFile: the same set of files with the same digests, so any
+// file the manifest does not list, hidden or not, is an error. The error
+// wraps fs.ErrNotExist when dir has no manifest.
+func VerifyPackManifest(dir string) (*PackManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest PackManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", PackManifestFile, err)
+	}
+	if manifest.Format < 1 || manifest.Format > PackFormatVersion {
+		return nil, fmt.Errorf("unsupported package format %d", manifest.Format)
+	}
+
+	files, err := FileDigests(dir)
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[string]PackFile, len(manifest.Files))
+	for _, f := range manifest.Files {
+		listed[f.Path] = f
+	}
+	for _, got := range files {
+		want, ok := listed[got.Path]
+		if !ok {
+			return nil, fmt.Errorf("%s is not listed in the package manifest", got.Path)
+		}
+		delete(listed, got.Path)
+		if got != want {
+			return nil, fmt.Errorf("%s does not match the package manifest", got.Path)
+		}
+	}
+	for rel := range listed {
+		return nil, fmt.Errorf("%s is listed in the package manifest but missing", rel)
+	}
+	return &manifest, nil
+}
+
+// ArchiveSource returns the key installations from a package file record as
+// their source.
+func ArchiveSource(path string) string {
+	return "archive:" + path
+}
+
+// LoadArchive extracts a package written by PackSkill into
+// cacheDir/archives/<dir>@<version>, after checking every file against the
+// embedded manifest. An intact entry extracted from the same archive is
+// reused.
+func LoadArchive(path, cacheDir string) (*Skill, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	archives := filepath.Join(cacheDir, "archives")
+	if err := os.MkdirAll(archives, 0o755); err != nil {
+		return nil, err
+	}
+	// A hidden staging name, so an interrupted extraction shows up as a
+	// leftover in ListCache.
+	staged, err := os.MkdirTemp(archives, ".extract-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staged)
+
+	if err := ExtractArchive(bytes.NewReader(data), staged); err != nil {
+		return nil, err
+	}
+	manifest, err := VerifyPackManifest(staged)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s is not an aisk package (no %s); create it with aisk pack", filepath.Base(path), PackManifestFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if err := ValidateName(manifest.Dir); err != nil {
+		return nil, fmt.Errorf("%s: package directory %w", filepath.Base(path), err)
+	}
+	s, err := LoadLocal(staged)
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading SKILL.md: %w", filepath.Base(path), err)
+	}
+	if s.Version != manifest.Version {
+		return nil, fmt.Errorf("%s: SKILL.md version %q does not match the package manifest (%q)", filepath.Base(path), s.Version, manifest.Version)
+	}
+
+	dest := filepath.Join(archives, manifest.Dir+"@"+url.PathEscape(s.DisplayVersion()))
+	source := ArchiveSource(path)
+	cached, err := LoadCacheEntry(dest)
+	if err == nil {
+		err = VerifyCacheEntry(cached)
+	}
+	if err != nil || cached.Meta.Archive != digest || cached.Meta.Source != source {
+		hash, err := DirHash(staged)
+		if err != nil {
+			return nil, err
+		}
+		meta := CacheMeta{Source: source, Ref: manifest.Version, Archive: digest, FetchedAt: time.Now().UTC(), Digest: hash}
+		if err := WriteCacheMeta(staged, meta); err != nil {
+			return nil, err
+		}
+		if err := fsutil.Swap(staged, dest); err != nil {
+			return nil, fmt.Errorf("replacing cached %s: %w", filepath.Base(dest), err)
+		}
+	}
+
+	s, err = LoadLocal(dest)
+	if err != nil {
+		return nil, err
+	}
+	s.DirName = manifest.Dir
+	s.Source = SourceArchive
+	s.Origin = source
+	return s, nil
+}
+
+func packFile(tw *tar.Writer, src, name string, modTime time.Time) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	mode := int64(0o644)
+	if info.Mode()&0o111 != 0 {
+		mode = 0o755
+	}
+	hdr := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: mode, Size: info.Size(), ModTime: modTime, Format: tar.FormatPAX}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ExtractArchive extracts a gzipped tarball into destDir, dropping the
+// archive's single top-level directory. Only regular files and directories
+// are extracted; entries escaping destDir are rejected.
+func ExtractArchive(r io.Reader, destDir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("reading tarball: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading tarball: %w", err)
+		}
+		_, rel, _ := strings.Cut(strings.TrimPrefix(hdr.Name, "./"), "/")
+		rel = path.Clean(rel)
+		if rel == "." || rel == "" {
+			continue
+		}
+		if strings.HasPrefix(rel, "../") || rel == ".." || path.IsAbs(rel) {
+			return fmt.Errorf("tarball entry %q escapes the skill directory", hdr.Name)
+		}
+		dest := filepath.Join(destDir, filepath.FromSlash(rel))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+				return err
+			}
+			mode := os.FileMode(0o644)
+			if hdr.Mode&0o111 != 0 {
+				mode = 0o755
+			}
+			f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("extracting %s: %w", rel, err)
+			}
+		}
+	}
+	return nil
+}
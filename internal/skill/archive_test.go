@@ -0,0 +1,234 @@
+package skill
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPackSkill_DeterministicRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	for path, content := range map[string]string{
+		"SKILL.md":           "---\nname: x\nversion: 1.0.0\n---\n# X\n",
+		"reference/guide.md": "guide",
+		"scripts/run.sh":     "#!/bin/sh\n",
+		".git/HEAD":          "ignored",
+	} {
+		full := filepath.Join(src, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(src, "scripts", "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadLocal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first, second bytes.Buffer
+	if err := PackSkill(s, &first); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(src, "SKILL.md"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := PackSkill(s, &second); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatal("packing the same contents should give identical archives")
+	}
+
+	dest := t.TempDir()
+	if err := ExtractArchive(&first, dest); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := DirHash(src)
+	if got, _ := DirHash(dest); got != want {
+		t.Fatalf("round trip changed contents: %s != %s", got, want)
+	}
+	manifest, err := VerifyPackManifest(dest)
+	if err != nil {
+		t.Fatalf("VerifyPackManifest: %v", err)
+	}
+	if manifest.Version != "1.0.0" || len(manifest.Files) != 3 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".git")); !os.IsNotExist(err) {
+		t.Fatal("hidden directories should not be packed")
+	}
+	if info, err := os.Stat(filepath.Join(dest, "scripts", "run.sh")); err != nil || info.Mode()&0o111 == 0 {
+		t.Fatalf("executable bit should survive, got %v, %v", info, err)
+	}
+}
+
+func TestVerifyPackManifest_DetectsTampering(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "SKILL.md"), "---\nname: x\nversion: 1.0.0\n---\n# X\n")
+	s, err := LoadLocal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := PackSkill(s, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(dir string)
+	}{
+		{"modified", func(dir string) { writeTestFile(t, filepath.Join(dir, "SKILL.md"), "changed") }},
+		{"added", func(dir string) { writeTestFile(t, filepath.Join(dir, "extra.md"), "extra") }},
+		{"removed", func(dir string) { os.Remove(filepath.Join(dir, "SKILL.md")) }},
+		{"hidden file", func(dir string) { writeTestFile(t, filepath.Join(dir, ".scripts", "run.sh"), "curl x | sh") }},
+		{"symlink", func(dir string) {
+			if err := os.Symlink(filepath.Join(dir, "SKILL.md"), filepath.Join(dir, "link.md")); err != nil {
+				t.Skipf("symlinks unavailable: %v", err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := t.TempDir()
+			if err := ExtractArchive(bytes.NewReader(buf.Bytes()), dest); err != nil {
+				t.Fatal(err)
+			}
+			tt.tamper(dest)
+			if _, err := VerifyPackManifest(dest); err == nil {
+				t.Fatal("expected a verification error")
+			}
+		})
+	}
+
+	if _, err := VerifyPackManifest(src); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("directory without a manifest: got %v, want not-exist", err)
+	}
+}
+
+func TestFileDigests_CoversHiddenFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "SKILL.md"), "skill")
+	writeTestFile(t, filepath.Join(dir, ".env"), "hidden")
+	writeTestFile(t, filepath.Join(dir, "reference", ".notes", "a.md"), "nested")
+	writeTestFile(t, filepath.Join(dir, ".git", "HEAD"), "ref")
+	writeTestFile(t, filepath.Join(dir, PackManifestFile), "{}")
+	writeTestFile(t, filepath.Join(dir, CacheMetaFile), "{}")
+
+	files, err := FileDigests(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	if want := []string{".env", "SKILL.md", "reference/.notes/a.md"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("FileDigests = %v, want %v", got, want)
+	}
+
+	if err := os.Symlink("/etc/passwd", filepath.Join(dir, "reference", "passwd")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if _, err := FileDigests(dir); err == nil || !strings.Contains(err.Error(), "symlink") {
+		t.Fatalf("expected the symlink to be refused, got %v", err)
+	}
+}
+
+func TestLoadArchive(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "my-skill")
+	writeTestFile(t, filepath.Join(src, "SKILL.md"), "---\nname: my-skill\nversion: 1.2.0\n---\n# My skill\n")
+	s, err := LoadLocal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := PackSkill(s, &buf); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), ArchiveName(s))
+	if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(archive) != "my-skill-1.2.0.tar.gz" {
+		t.Fatalf("ArchiveName = %s", filepath.Base(archive))
+	}
+
+	cacheDir := t.TempDir()
+	got, err := LoadArchive(archive, cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DirName != "my-skill" || got.Version != "1.2.0" || got.Source != SourceArchive {
+		t.Fatalf("LoadArchive = %+v", got)
+	}
+	if want := filepath.Join(cacheDir, "archives", "my-skill@1.2.0"); got.Path != want {
+		t.Fatalf("Path = %s, want %s", got.Path, want)
+	}
+	if _, err := LoadArchive(archive, cacheDir); err != nil {
+		t.Fatalf("reloading: %v", err)
+	}
+
+	// A plain tarball without a manifest is refused.
+	var raw bytes.Buffer
+	gz := gzip.NewWriter(&raw)
+	tw := tar.NewWriter(gz)
+	body := []byte("---\nname: my-skill\n---\n# My skill\n")
+	tw.WriteHeader(&tar.Header{Name: "my-skill/SKILL.md", Mode: 0o644, Size: int64(len(body))})
+	tw.Write(body)
+	tw.Close()
+	gz.Close()
+	plain := filepath.Join(t.TempDir(), "plain.tar.gz")
+	if err := os.WriteFile(plain, raw.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadArchive(plain, cacheDir); err == nil {
+		t.Fatal("expected an error for an archive without a manifest")
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDirSize_FollowsFileSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "SKILL.md"), "12345")
+	writeTestFile(t, filepath.Join(dir, ".env"), "123")
+	writeTestFile(t, filepath.Join(dir, ".git", "HEAD"), "ignored")
+	shared := filepath.Join(t.TempDir(), "common.md")
+	writeTestFile(t, shared, "1234567")
+	if err := os.Symlink(shared, filepath.Join(dir, "common.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := DirSize(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 15 {
+		t.Fatalf("DirSize = %d, want 15", size)
+	}
+}
@@ -0,0 +1,204 @@
+package skill
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRef is the branch fetched when a remote source names no ref.
+const DefaultRef = "main"
+
+// CacheMetaFile records where a cache entry came from. It is hidden so
+// DirHash ignores it.
+const CacheMetaFile = ".aisk-cache.json"
+
+// CacheMeta describes one downloaded skill in the remote cache.
+type CacheMeta struct {
+	Source    string    `json:"source"` // RemoteSource key, also recorded in the manifest
+	Host      string    `json:"host,omitempty"`
+	Owner     string    `json:"owner"`
+	Repo      string    `json:"repo"`
+	Ref       string    `json:"ref"`
+	Commit    string    `json:"commit,omitempty"`  // commit the ref pointed at
+	Archive   string    `json:"archive,omitempty"` // sha256 of the registry archive it was extracted from
+	FetchedAt time.Time `json:"fetched_at"`
+	Digest    string    `json:"digest"` // DirHash of the entry when it was downloaded
+}
+
+// CacheEntry is a directory in the remote cache. Entries left by older
+// versions of aisk or by interrupted downloads have no metadata; their
+// Meta.Source is empty.
+type CacheEntry struct {
+	Path string
+	Meta CacheMeta
+	Size int64
+}
+
+// RemoteSource returns the key identifying a remote skill at a ref, e.g.
+// "github.com/owner/repo@main" or "ghe.example.com/owner/repo@v1".
+// Installations of remote skills record it as their source.
+func RemoteSource(r RepoRef, ref string) string {
+	if ref == "" {
+		ref = DefaultRef
+	}
+	return fmt.Sprintf("%s@%s", r, ref)
+}
+
+// ParseRemoteSource splits a RemoteSource key into the repository and ref.
+// It fails for local, registry and archive sources.
+func ParseRemoteSource(source string) (RepoRef, string, bool) {
+	if strings.HasPrefix(source, ArchiveSource("")) || strings.Contains(source, "#") {
+		return RepoRef{}, "", false
+	}
+	repo, ref, ok := strings.Cut(source, "@")
+	if !ok || ref == "" {
+		return RepoRef{}, "", false
+	}
+	r, ok := ParseRepoURL(repo)
+	if !ok {
+		return RepoRef{}, "", false
+	}
+	return r, ref, true
+}
+
+// CachePath returns the cache directory for a remote skill at a ref. A port
+// in the host becomes "_" so the path is valid on Windows.
+func CachePath(cacheDir string, r RepoRef, ref string) string {
+	if ref == "" {
+		ref = DefaultRef
+	}
+	host := strings.ReplaceAll(r.HostName(), ":", "_")
+	return filepath.Join(cacheDir, host, r.Owner, r.Repo+"@"+url.PathEscape(ref))
+}
+
+// ListCache returns the entries in cacheDir sorted by path.
+func ListCache(cacheDir string) ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(cacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == cacheDir {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if !d.IsDir() || path == cacheDir {
+			return nil
+		}
+		entry := CacheEntry{Path: path}
+		switch {
+		case fileExists(filepath.Join(path, CacheMetaFile)):
+			data, err := os.ReadFile(filepath.Join(path, CacheMetaFile))
+			if err != nil {
+				return err
+			}
+			// Unreadable metadata leaves Source empty, so the entry is
+			// treated like one without metadata.
+			_ = json.Unmarshal(data, &entry.Meta)
+		case strings.HasPrefix(d.Name(), "."), fileExists(filepath.Join(path, "SKILL.md")):
+			// Staging directory of an interrupted download, or an entry
+			// from before the cache recorded metadata.
+		default:
+			return nil
+		}
+		if entry.Size, err = treeSize(path); err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// VerifyCacheEntry checks that an entry has metadata, a parseable SKILL.md
+// and the contents it was downloaded with.
+func VerifyCacheEntry(e CacheEntry) error {
+	if e.Meta.Source == "" {
+		return fmt.Errorf("no cache metadata (incomplete download or older aisk)")
+	}
+	data, err := os.ReadFile(filepath.Join(e.Path, "SKILL.md"))
+	if err != nil {
+		return fmt.Errorf("reading SKILL.md: %w", err)
+	}
+	if _, _, err := ParseFrontmatter(string(data)); err != nil {
+		return fmt.Errorf("parsing SKILL.md: %w", err)
+	}
+	digest, err := DirHash(e.Path)
+	if err != nil {
+		return err
+	}
+	if digest != e.Meta.Digest {
+		return fmt.Errorf("contents changed since download")
+	}
+	return nil
+}
+
+// RemoveCacheEntry deletes an entry and any parent directories it leaves
+// empty, up to cacheDir.
+func RemoveCacheEntry(cacheDir string, e CacheEntry) error {
+	if err := os.RemoveAll(e.Path); err != nil {
+		return err
+	}
+	root := filepath.Clean(cacheDir)
+	for dir := filepath.Dir(e.Path); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break // not empty
+		}
+	}
+	return nil
+}
+
+// LoadCacheEntry reads the entry at dir and its metadata.
+func LoadCacheEntry(dir string) (CacheEntry, error) {
+	e := CacheEntry{Path: dir}
+	data, err := os.ReadFile(filepath.Join(dir, CacheMetaFile))
+	if err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(data, &e.Meta); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// WriteCacheMeta records meta as the metadata of the entry at dir.
+func WriteCacheMeta(dir string, meta CacheMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, CacheMetaFile), data, 0o644)
+}
+
+func treeSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,136 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeCacheEntry(t *testing.T, cacheDir, owner, repo, ref string) CacheEntry {
+	t.Helper()
+	r := RepoRef{Owner: owner, Repo: repo}
+	dir := CachePath(cacheDir, r, ref)
+	if err := os.MkdirAll(filepath.Join(dir, "references"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: "+repo+"\n---\n# Body\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "references", "guide.md"), []byte("guide"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := DirHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := CacheMeta{Source: RemoteSource(r, ref), Owner: owner, Repo: repo, Ref: ref, FetchedAt: time.Now(), Digest: digest}
+	if err := WriteCacheMeta(dir, meta); err != nil {
+		t.Fatal(err)
+	}
+	return CacheEntry{Path: dir, Meta: meta}
+}
+
+func TestCachePath_IncludesRef(t *testing.T) {
+	r := RepoRef{Owner: "o", Repo: "r"}
+	main := CachePath("/c", r, "")
+	tag := CachePath("/c", r, "v1.2.0")
+	branch := CachePath("/c", r, "feature/x")
+	if main == tag || filepath.Base(main) != "r@main" || filepath.Base(branch) != "r@feature%2Fx" {
+		t.Fatalf("unexpected cache paths %q %q %q", main, tag, branch)
+	}
+	if got := RemoteSource(r, ""); got != "github.com/o/r@main" {
+		t.Fatalf("RemoteSource = %q", got)
+	}
+
+	ghe := RepoRef{Host: "ghe.example.com:8443", Owner: "o", Repo: "r"}
+	if got := RemoteSource(ghe, "v1"); got != "ghe.example.com:8443/o/r@v1" {
+		t.Fatalf("RemoteSource = %q", got)
+	}
+	if got := CachePath("/c", ghe, "v1"); got != filepath.Join("/c", "ghe.example.com_8443", "o", "r@v1") {
+		t.Fatalf("CachePath = %q", got)
+	}
+}
+
+func TestListCache_FindsEntriesAndLeftovers(t *testing.T) {
+	cacheDir := t.TempDir()
+	writeCacheEntry(t, cacheDir, "o", "a", "main")
+	writeCacheEntry(t, cacheDir, "o", "a", "v2")
+	// Layout used before cache keys included the ref.
+	legacy := filepath.Join(cacheDir, "old-repo")
+	if err := os.MkdirAll(legacy, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "SKILL.md"), []byte("---\nname: old\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Staging directory of an interrupted download.
+	staged := filepath.Join(cacheDir, "github.com", "o", ".b@main.tmp-123")
+	if err := os.MkdirAll(staged, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ListCache(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %+v", entries)
+	}
+	sources := make(map[string]int)
+	for _, e := range entries {
+		sources[e.Meta.Source]++
+		if e.Meta.Source != "" && e.Size == 0 {
+			t.Fatalf("expected size for %s", e.Path)
+		}
+	}
+	if sources["github.com/o/a@main"] != 1 || sources["github.com/o/a@v2"] != 1 || sources[""] != 2 {
+		t.Fatalf("unexpected sources %v", sources)
+	}
+}
+
+func TestListCache_MissingDir(t *testing.T) {
+	entries, err := ListCache(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty cache, got %v, %v", entries, err)
+	}
+}
+
+func TestVerifyCacheEntry(t *testing.T) {
+	cacheDir := t.TempDir()
+	e := writeCacheEntry(t, cacheDir, "o", "a", "main")
+	if err := VerifyCacheEntry(e); err != nil {
+		t.Fatalf("fresh entry should verify: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(e.Path, "references", "guide.md"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyCacheEntry(e); err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Fatalf("expected changed-contents error, got %v", err)
+	}
+	if err := VerifyCacheEntry(CacheEntry{Path: e.Path}); err == nil {
+		t.Fatal("entry without metadata should fail verification")
+	}
+}
+
+func TestRemoveCacheEntry_PrunesEmptyParents(t *testing.T) {
+	cacheDir := t.TempDir()
+	a := writeCacheEntry(t, cacheDir, "o", "a", "main")
+	b := writeCacheEntry(t, cacheDir, "p", "b", "main")
+	if err := RemoveCacheEntry(cacheDir, a); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "github.com", "o")); !os.IsNotExist(err) {
+		t.Fatalf("expected empty owner directory to be removed, got %v", err)
+	}
+	if _, err := os.Stat(b.Path); err != nil {
+		t.Fatalf("other entry should remain: %v", err)
+	}
+	if err := RemoveCacheEntry(cacheDir, b); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cacheDir); err != nil {
+		t.Fatalf("cache dir itself must remain: %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package skill
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleFormat names a client rule format that can be converted into a skill.
+type RuleFormat string
+
+const (
+	RuleCursor   RuleFormat = "cursor"   // .mdc with description/globs/alwaysApply
+	RuleWindsurf RuleFormat = "windsurf" // .windsurf/rules/*.md or global_rules.md
+	RuleCopilot  RuleFormat = "copilot"  // copilot-instructions.md or *.instructions.md
+	RuleClaude   RuleFormat = "claude"   // CLAUDE.md section
+)
+
+// ParseRuleFormat converts a string to a RuleFormat, or "" if unsupported.
+func ParseRuleFormat(s string) RuleFormat {
+	switch f := RuleFormat(strings.ToLower(s)); f {
+	case RuleCursor, RuleWindsurf, RuleCopilot, RuleClaude:
+		return f
+	}
+	return ""
+}
+
+// ruleFrontmatter is the union of frontmatter keys used by client rule files.
+type ruleFrontmatter struct {
+	Description string `yaml:"description"`
+	Globs       any    `yaml:"globs"`       // Cursor/Windsurf: comma-separated string or list
+	AlwaysApply bool   `yaml:"alwaysApply"` // Cursor
+	Trigger     string `yaml:"trigger"`     // Windsurf: always_on, glob, model_decision, manual
+	ApplyTo     string `yaml:"applyTo"`     // Copilot *.instructions.md
+}
+
+// ConvertRule turns the content of a client rule file into skill frontmatter
+// and a markdown body. When section is non-empty, only the level-1 markdown
+// section with that heading (or the aisk-managed section with that name) is
+// converted. name becomes the skill name; Description falls back to the first
+// blockquote or paragraph of the body.
+func ConvertRule(format RuleFormat, content, name, section string) (Frontmatter, string, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var rfm ruleFrontmatter
+	body := content
+	if strings.HasPrefix(content, "---\n") {
+		rest := content[4:]
+		idx := strings.Index(rest, "\n---")
+		if idx < 0 {
+			return Frontmatter{}, "", fmt.Errorf("missing closing frontmatter delimiter")
+		}
+		if err := yaml.Unmarshal([]byte(rest[:idx]), &rfm); err != nil {
+			return Frontmatter{}, "", fmt.Errorf("parsing rule frontmatter: %w", err)
+		}
+		body = strings.TrimLeft(rest[idx+4:], "\n")
+	}
+
+	if section != "" {
+		sec, ok := extractRuleSection(body, section)
+		if !ok {
+			return Frontmatter{}, "", fmt.Errorf("section %q not found", section)
+		}
+		body = sec
+	} else {
+		body = stripSectionMarkers(body)
+	}
+	if strings.TrimSpace(body) == "" {
+		return Frontmatter{}, "", fmt.Errorf("rule has no content")
+	}
+
+	fm := Frontmatter{
+		Name:        name,
+		Description: strings.TrimSpace(rfm.Description),
+		Version:     "0.1.0",
+		Globs:       parseGlobs(rfm.Globs),
+		AlwaysApply: rfm.AlwaysApply,
+	}
+	switch format {
+	case RuleWindsurf:
+		fm.AlwaysApply = rfm.Trigger == "always_on"
+	case RuleCopilot:
+		if rfm.ApplyTo != "" {
+			fm.Globs = parseGlobs(rfm.ApplyTo)
+		}
+	}
+
+	body, quoted := takeBlockquote(body)
+	if fm.Description == "" {
+		fm.Description = quoted
+	}
+	if fm.Description == "" {
+		fm.Description = firstParagraph(body)
+	}
+
+	if !strings.HasPrefix(strings.TrimLeft(body, "\n"), "# ") {
+		body = "# " + kebabToTitle(name) + "\n\n" + strings.TrimLeft(body, "\n")
+	}
+	body = strings.TrimRight(body, "\n") + "\n"
+	return fm, body, nil
+}
+
+// WriteConverted creates parentDir/<fm.Name> with a SKILL.md built from fm and
+// body, plus a README.md, mirroring Scaffold.
+func WriteConverted(parentDir string, fm Frontmatter, body string) (string, error) {
+	if err := ValidateName(fm.Name); err != nil {
+		return "", fmt.Errorf("invalid skill name: %w", err)
+	}
+	skillDir := filepath.Join(parentDir, fm.Name)
+	if _, err := os.Stat(skillDir); err == nil {
+		return "", fmt.Errorf("directory already exists: %s", skillDir)
+	}
+
+	header, err := yaml.Marshal(struct {
+		Name        string   `yaml:"name"`
+		Description string   `yaml:"description"`
+		Version     string   `yaml:"version,omitempty"`
+		Globs       []string `yaml:"globs,omitempty"`
+		AlwaysApply bool     `yaml:"always-apply,omitempty"`
+	}{fm.Name, fm.Description, fm.Version, fm.Globs, fm.AlwaysApply})
+	if err != nil {
+		return "", fmt.Errorf("encoding frontmatter: %w", err)
+	}
+
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		return "", fmt.Errorf("creating directory %s: %w", skillDir, err)
+	}
+	skillMD := "---\n" + string(header) + "---\n" + body
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skillMD), 0o644); err != nil {
+		cleanup(skillDir)
+		return "", fmt.Errorf("writing SKILL.md: %w", err)
+	}
+	data := struct{ Title string }{kebabToTitle(fm.Name)}
+	if err := writeTemplate(filepath.Join(skillDir, "README.md"), readmeTemplate, data); err != nil {
+		cleanup(skillDir)
+		return "", fmt.Errorf("writing README.md: %w", err)
+	}
+	return skillDir, nil
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SuggestName derives a kebab-case skill name from a file name or heading.
+func SuggestName(s string) string {
+	s = filepath.Base(s)
+	for _, ext := range []string{".mdc", ".md", ".instructions"} {
+		s = strings.TrimSuffix(s, ext)
+	}
+	s = nonNameChars.ReplaceAllString(strings.ToLower(s), "-")
+	s = strings.Trim(s, "-")
+	if len(s) > maxNameLen {
+		s = strings.TrimRight(s[:maxNameLen], "-")
+	}
+	return s
+}
+
+func parseGlobs(v any) []string {
+	var raw []string
+	switch g := v.(type) {
+	case string:
+		raw = strings.Split(g, ",")
+	case []any:
+		for _, item := range g {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+	var globs []string
+	for _, g := range raw {
+		if g = strings.TrimSpace(g); g != "" {
+			globs = append(globs, g)
+		}
+	}
+	return globs
+}
+
+// extractRuleSection returns the level-1 section titled heading, or the body of
+// the aisk-managed section with that name.
+func extractRuleSection(content, heading string) (string, bool) {
+	start := "<!-- aisk:start:" + heading + " -->"
+	if i := strings.Index(content, start); i >= 0 {
+		rest := content[i+len(start):]
+		if j := strings.Index(rest, "<!-- aisk:end:"+heading+" -->"); j >= 0 {
+			return strings.Trim(rest[:j], "\n"), true
+		}
+	}
+
+	var b strings.Builder
+	found, inFence := false, false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "# ") {
+			if found {
+				break
+			}
+			found = strings.EqualFold(strings.TrimSpace(line[2:]), heading)
+		}
+		if found {
+			b.WriteString(line)
+		}
+	}
+	return b.String(), found
+}
+
+func stripSectionMarkers(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "<!-- aisk:start:") || strings.HasPrefix(trimmed, "<!-- aisk:end:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// takeBlockquote removes the "> description" block that aisk's markdown
+// adapters write right after the heading and returns it as plain text.
+func takeBlockquote(body string) (string, string) {
+	lines := strings.Split(body, "\n")
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], "# ") {
+		i++
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	start := i
+	var quoted []string
+	for i < len(lines) && strings.HasPrefix(lines[i], ">") {
+		quoted = append(quoted, strings.TrimSpace(strings.TrimPrefix(lines[i], ">")))
+		i++
+	}
+	if len(quoted) == 0 {
+		return body, ""
+	}
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	rest := append(append([]string{}, lines[:start]...), lines[i:]...)
+	return strings.Join(rest, "\n"), strings.TrimSpace(strings.Join(quoted, " "))
+}
+
+func firstParagraph(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") || strings.HasPrefix(line, "<!--") {
+			continue
+		}
+		line = strings.TrimLeft(line, "-*> ")
+		if utf8.RuneCountInString(line) > 200 {
+			line = string([]rune(line)[:197]) + "..."
+		}
+		return line
+	}
+	return ""
+}
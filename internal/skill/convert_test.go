@@ -0,0 +1,128 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestConvertRule_Cursor(t *testing.T) {
+	content := "---\ndescription: Enforce TS style\nglobs: src/**/*.ts, lib/*.ts\nalwaysApply: true\n---\n\nUse strict mode.\n"
+	fm, body, err := ConvertRule(RuleCursor, content, "ts-style", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Description != "Enforce TS style" || !fm.AlwaysApply {
+		t.Errorf("unexpected frontmatter: %+v", fm)
+	}
+	if want := []string{"src/**/*.ts", "lib/*.ts"}; !reflect.DeepEqual(fm.Globs, want) {
+		t.Errorf("globs = %v, want %v", fm.Globs, want)
+	}
+	if body != "# Ts Style\n\nUse strict mode.\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestConvertRule_WindsurfAndCopilot(t *testing.T) {
+	fm, _, err := ConvertRule(RuleWindsurf, "---\ntrigger: always_on\nglobs:\n  - \"*.go\"\n---\n# Go\n\nRun gofmt.\n", "go-rules", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fm.AlwaysApply || !reflect.DeepEqual(fm.Globs, []string{"*.go"}) || fm.Description != "Run gofmt." {
+		t.Errorf("unexpected windsurf frontmatter: %+v", fm)
+	}
+
+	fm, _, err = ConvertRule(RuleCopilot, "---\napplyTo: \"**/*.py\"\n---\nPrefer pathlib.\n", "python", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fm.Globs, []string{"**/*.py"}) {
+		t.Errorf("copilot applyTo not mapped: %+v", fm)
+	}
+}
+
+func TestConvertRule_ClaudeSection(t *testing.T) {
+	content := "# Project\n\nGeneral notes.\n\n# Testing\n\n> Testing conventions\n\nUse table tests.\n```\n# not a heading\n```\n\n# Other\n\nx\n"
+	fm, body, err := ConvertRule(RuleClaude, content, "testing", "Testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Description != "Testing conventions" {
+		t.Errorf("description = %q", fm.Description)
+	}
+	if strings.Contains(body, "Other") || strings.Contains(body, "> Testing") || !strings.Contains(body, "# not a heading") {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	if _, _, err := ConvertRule(RuleClaude, content, "missing", "Missing"); err == nil {
+		t.Error("expected error for missing section")
+	}
+}
+
+func TestConvertRule_ManagedSection(t *testing.T) {
+	content := "<!-- aisk:start:five-whys -->\n# five-whys\n\n> Root cause analysis\n\nAsk why.\n<!-- aisk:end:five-whys -->\n"
+	fm, body, err := ConvertRule(RuleCopilot, content, "five-whys", "five-whys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Description != "Root cause analysis" || body != "# five-whys\n\nAsk why.\n" {
+		t.Errorf("unexpected result %+v %q", fm, body)
+	}
+}
+
+func TestWriteConverted(t *testing.T) {
+	dir := t.TempDir()
+	fm := Frontmatter{Name: "ts-style", Description: "Enforce: TS style", Version: "0.1.0", Globs: []string{"*.ts"}, AlwaysApply: true}
+	skillDir, err := WriteConverted(dir, fm, "# Ts Style\n\nUse when: editing TS\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, fm) || body != "# Ts Style\n\nUse when: editing TS\n" {
+		t.Errorf("round trip mismatch: %+v %q", got, body)
+	}
+	if _, err := os.Stat(filepath.Join(skillDir, "README.md")); err != nil {
+		t.Errorf("README.md not written: %v", err)
+	}
+
+	if _, err := WriteConverted(dir, fm, "x"); err == nil {
+		t.Error("expected error when directory exists")
+	}
+}
+
+func TestSuggestName(t *testing.T) {
+	for in, want := range map[string]string{
+		"/x/.cursor/rules/TS_Style.mdc":   "ts-style",
+		"react.instructions.md":           "react",
+		"Testing Conventions":             "testing-conventions",
+		".github/copilot-instructions.md": "copilot-instructions",
+	} {
+		if got := SuggestName(in); got != want {
+			t.Errorf("SuggestName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFirstParagraph_TruncatesOnRuneBoundary(t *testing.T) {
+	got := firstParagraph("# Título\n\n" + strings.Repeat("é", 150) + strings.Repeat("日本", 50) + "\n")
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncation split a character: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n != 200 || !strings.HasSuffix(got, "日本日...") {
+		t.Fatalf("got %d characters ending %q", n, got[len(got)-12:])
+	}
+	if short := strings.Repeat("ü", 200); firstParagraph(short) != short {
+		t.Fatal("200 characters should not be truncated")
+	}
+}
@@ -0,0 +1,35 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirHash_StableAndContentSensitive(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("a"), 0o644)
+	os.MkdirAll(filepath.Join(dir, "reference"), 0o755)
+	os.WriteFile(filepath.Join(dir, "reference", "r.md"), []byte("b"), 0o644)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0o755)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("x"), 0o644)
+
+	h1, err := DirHash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(h1, "sha256:") {
+		t.Fatalf("unexpected hash format %q", h1)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("y"), 0o644)
+	if h2, _ := DirHash(dir); h2 != h1 {
+		t.Error("hidden files should not affect the hash")
+	}
+
+	os.WriteFile(filepath.Join(dir, "reference", "r.md"), []byte("c"), 0o644)
+	if h3, _ := DirHash(dir); h3 == h1 {
+		t.Error("content change should change the hash")
+	}
+}
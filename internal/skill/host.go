@@ -0,0 +1,246 @@
+package skill
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultHost is the host of repository references that name none.
+const DefaultHost = "github.com"
+
+// RepoRef names a repository on a GitHub-compatible host.
+type RepoRef struct {
+	Host  string // DefaultHost when empty
+	Owner string
+	Repo  string
+}
+
+// HostName returns r.Host, or DefaultHost when it is empty.
+func (r RepoRef) HostName() string {
+	if r.Host == "" {
+		return DefaultHost
+	}
+	return strings.ToLower(r.Host)
+}
+
+// String returns "host/owner/repo".
+func (r RepoRef) String() string {
+	return r.HostName() + "/" + r.Owner + "/" + r.Repo
+}
+
+// Host is how aisk reaches one GitHub-compatible server. Fields left empty
+// in the hosts file take their defaults from ResolveHost.
+type Host struct {
+	Name     string `json:"-"`
+	APIURL   string `json:"api_url,omitempty"`   // REST API base, e.g. https://ghe.example.com/api/v3
+	RawURL   string `json:"raw_url,omitempty"`   // raw file base, e.g. https://ghe.example.com/raw
+	TokenEnv string `json:"token_env,omitempty"` // environment variable holding the token
+}
+
+// LoadHosts reads the per-host settings at path, keyed by host name. A
+// missing file means no overrides.
+func LoadHosts(path string) (map[string]Host, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var hosts map[string]Host
+	if err := json.Unmarshal(data, &hosts); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	normalized := make(map[string]Host, len(hosts))
+	for name, h := range hosts {
+		normalized[strings.ToLower(name)] = h
+	}
+	return normalized, nil
+}
+
+// ResolveHost returns the settings for name: the entry in hosts, with unset
+// URLs defaulting to api.github.com / raw.githubusercontent.com for
+// github.com and to the GitHub Enterprise Server layout (https://<host>/api/v3
+// and https://<host>/raw) for any other host.
+func ResolveHost(hosts map[string]Host, name string) Host {
+	name = strings.ToLower(name)
+	if name == "" {
+		name = DefaultHost
+	}
+	h := hosts[name]
+	h.Name = name
+	if h.APIURL == "" {
+		h.APIURL = "https://" + name + "/api/v3"
+		if name == DefaultHost {
+			h.APIURL = "https://api.github.com"
+		}
+	}
+	if h.RawURL == "" {
+		h.RawURL = "https://" + name + "/raw"
+		if name == DefaultHost {
+			h.RawURL = "https://raw.githubusercontent.com"
+		}
+	}
+	h.APIURL = strings.TrimSuffix(h.APIURL, "/")
+	h.RawURL = strings.TrimSuffix(h.RawURL, "/")
+	return h
+}
+
+// Token returns the token to send to h and where it came from, trying in
+// order: the host's token_env, GITHUB_TOKEN / GH_TOKEN for github.com or
+// GH_ENTERPRISE_TOKEN / GITHUB_ENTERPRISE_TOKEN for other hosts, the gh CLI's
+// hosts.yml, and ~/.netrc. Tokens are never shared between hosts.
+func (h Host) Token() (token, source string) {
+	envs := []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	if h.Name == DefaultHost {
+		envs = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	}
+	if h.TokenEnv != "" {
+		envs = append([]string{h.TokenEnv}, envs...)
+	}
+	for _, env := range envs {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return v, "$" + env
+		}
+	}
+	if path := ghHostsFile(); path != "" {
+		if v := ghToken(path, h.Name); v != "" {
+			return v, path
+		}
+	}
+	if path := netrcFile(); path != "" {
+		if v := netrcToken(path, h.Name, apiHostName(h.APIURL)); v != "" {
+			return v, path
+		}
+	}
+	return "", ""
+}
+
+// tokenHint suggests how to authenticate to h, for rate limit errors.
+func (h Host) tokenHint() string {
+	if h.Name == DefaultHost {
+		return "set GITHUB_TOKEN or run `gh auth login` for a higher limit"
+	}
+	return fmt.Sprintf("set GH_ENTERPRISE_TOKEN or run `gh auth login --hostname %s` for a higher limit", h.Name)
+}
+
+// headers returns the request headers for h.
+func (h Host) headers() http.Header {
+	hdr := http.Header{}
+	hdr.Set("Accept", "application/vnd.github.v3+json")
+	hdr.Set("User-Agent", "aisk/0.1.0")
+	if token, _ := h.Token(); token != "" {
+		hdr.Set("Authorization", "Bearer "+token)
+	}
+	return hdr
+}
+
+// ghHostsFile returns the gh CLI's hosts.yml path.
+func ghHostsFile() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI", "hosts.yml")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+// ghToken reads the oauth_token of host from a gh CLI hosts.yml. Tokens gh
+// keeps in the system keyring are not visible here.
+func ghToken(path, host string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if yaml.Unmarshal(data, &hosts) != nil {
+		return ""
+	}
+	for name, h := range hosts {
+		if strings.EqualFold(name, host) {
+			return h.OAuthToken
+		}
+	}
+	return ""
+}
+
+func netrcFile() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(home, name)
+}
+
+// netrcToken returns the password of the first machine entry in a netrc file
+// matching one of names. The "default" entry is ignored so a token is only
+// sent to a host it was written down for.
+func netrcToken(path string, names ...string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(data))
+	var machine string
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				machine = fields[i]
+			}
+		case "default":
+			machine = ""
+		case "macdef":
+			// Macro bodies run to the next blank line, which Fields cannot
+			// see; stop rather than misread them.
+			return ""
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				for _, name := range names {
+					if machine != "" && strings.EqualFold(machine, name) {
+						return fields[i]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// apiHostName returns the host name of an API base URL, e.g. api.github.com.
+func apiHostName(apiURL string) string {
+	u, err := neturl.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
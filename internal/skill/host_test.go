@@ -0,0 +1,110 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveHost_Defaults(t *testing.T) {
+	gh := ResolveHost(nil, "")
+	if gh.Name != "github.com" || gh.APIURL != "https://api.github.com" || gh.RawURL != "https://raw.githubusercontent.com" {
+		t.Fatalf("unexpected github.com settings %+v", gh)
+	}
+	ghe := ResolveHost(nil, "GHE.example.com")
+	if ghe.APIURL != "https://ghe.example.com/api/v3" || ghe.RawURL != "https://ghe.example.com/raw" {
+		t.Fatalf("unexpected GHES defaults %+v", ghe)
+	}
+	proxied := ResolveHost(map[string]Host{"ghe.example.com": {APIURL: "https://proxy/api/"}}, "ghe.example.com")
+	if proxied.APIURL != "https://proxy/api" || proxied.RawURL != "https://ghe.example.com/raw" {
+		t.Fatalf("override should replace only the URLs it sets, got %+v", proxied)
+	}
+}
+
+func TestLoadHosts(t *testing.T) {
+	dir := t.TempDir()
+	if hosts, err := LoadHosts(filepath.Join(dir, "missing.json")); err != nil || hosts != nil {
+		t.Fatalf("missing file should mean no overrides, got %v, %v", hosts, err)
+	}
+	path := filepath.Join(dir, "hosts.json")
+	if err := os.WriteFile(path, []byte(`{"GHE.example.com": {"api_url": "https://x/api", "token_env": "GHE_TOKEN"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := LoadHosts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := hosts["ghe.example.com"]; h.APIURL != "https://x/api" || h.TokenEnv != "GHE_TOKEN" {
+		t.Fatalf("unexpected hosts %+v", hosts)
+	}
+	if err := os.WriteFile(path, []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadHosts(path); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
+func TestHostToken_Precedence(t *testing.T) {
+	isolateCredentials(t)
+	dir := t.TempDir()
+	t.Setenv("GH_CONFIG_DIR", dir)
+	t.Setenv("NETRC", filepath.Join(dir, "netrc"))
+	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte("github.com:\n    oauth_token: gh-cli\n    user: me\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	netrc := "machine other.example.com login me password wrong\n" +
+		"machine ghe.example.com login me password from-netrc\n" +
+		"default login anon password leaked\n"
+	if err := os.WriteFile(filepath.Join(dir, "netrc"), []byte(netrc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ghe := ResolveHost(map[string]Host{"ghe.example.com": {TokenEnv: "GHE_TOKEN"}}, "ghe.example.com")
+	check := func(h Host, want string) {
+		t.Helper()
+		if got, source := h.Token(); got != want {
+			t.Fatalf("%s token = %q (from %s), want %q", h.Name, got, source, want)
+		}
+	}
+
+	check(ghe, "from-netrc")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "enterprise")
+	check(ghe, "enterprise")
+	t.Setenv("GHE_TOKEN", "configured")
+	check(ghe, "configured")
+
+	gh := ResolveHost(nil, "github.com")
+	check(gh, "gh-cli")
+	t.Setenv("GITHUB_TOKEN", "env")
+	check(gh, "env")
+
+	// Neither the github.com token nor the netrc default reaches other hosts.
+	check(ResolveHost(nil, "unknown.example.com"), "enterprise")
+	t.Setenv("GH_ENTERPRISE_TOKEN", "")
+	check(ResolveHost(nil, "unknown.example.com"), "")
+}
+
+func TestFetchRemoteList_EnterpriseHost(t *testing.T) {
+	gh := &fakeGitHub{commit: "abc1234", files: map[string]string{"alpha/SKILL.md": skillMD("alpha")}}
+	srv := gh.start(t)
+	isolateCredentials(t)
+	t.Setenv("GITHUB_TOKEN", "public")
+	t.Setenv("GHE_TOKEN", "secret")
+
+	hosts := map[string]Host{"ghe.example.com": {APIURL: srv.URL + "/api", RawURL: srv.URL + "/raw", TokenEnv: "GHE_TOKEN"}}
+	r, ok := ParseRepoURL("ghe.example.com/o/r")
+	if !ok {
+		t.Fatal("host-qualified reference should parse")
+	}
+	skills, err := FetchRemoteList(r, FetchOptions{CacheDir: t.TempDir(), Hosts: hosts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 1 || skills[0].Frontmatter.Name != "alpha" {
+		t.Fatalf("unexpected skills %+v", skills)
+	}
+	if gh.auth != "Bearer secret" {
+		t.Fatalf("expected the host's token, got %q", gh.auth)
+	}
+}
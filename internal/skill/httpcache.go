@@ -0,0 +1,244 @@
+package skill
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
+)
+
+// FetchOptions controls how remote skills are fetched.
+type FetchOptions struct {
+	// CacheDir holds downloaded skills and, under http/, copies of GitHub
+	// responses that are revalidated with ETag / Last-Modified. Empty
+	// disables caching.
+	CacheDir string
+	// Offline serves everything from CacheDir and fails with ErrOffline
+	// instead of using the network when something is not cached.
+	Offline bool
+	// Hosts holds per-host API and raw URLs and token settings, keyed by
+	// host name (see LoadHosts). Hosts not listed use ResolveHost defaults.
+	Hosts map[string]Host
+}
+
+// ErrOffline is wrapped by errors for data that is not cached in offline mode.
+var ErrOffline = errors.New("not available offline")
+
+// RateLimitError reports an exhausted GitHub rate limit that resets later
+// than aisk is willing to wait.
+type RateLimitError struct {
+	Host          string
+	Reset         time.Time
+	Authenticated bool
+}
+
+func (e *RateLimitError) Error() string {
+	msg := fmt.Sprintf("%s API rate limit exceeded; it resets at %s (in %s)",
+		e.Host, e.Reset.Local().Format("15:04:05"), time.Until(e.Reset).Round(time.Second))
+	if !e.Authenticated {
+		msg += "; " + ResolveHost(nil, e.Host).tokenHint()
+	}
+	return msg
+}
+
+const (
+	// maxRateLimitWait is the longest aisk sleeps for a rate limit to reset
+	// before giving up with a RateLimitError.
+	maxRateLimitWait = time.Minute
+	maxAttempts      = 3
+)
+
+// sleep is replaced in tests.
+var sleep = time.Sleep
+
+// HTTPCacheDir returns the directory holding cached GitHub responses.
+func HTTPCacheDir(cacheDir string) string {
+	return filepath.Join(cacheDir, "http")
+}
+
+// HTTPCacheSize returns the size of the cached GitHub responses, 0 when
+// there are none.
+func HTTPCacheSize(cacheDir string) (int64, error) {
+	size, err := treeSize(HTTPCacheDir(cacheDir))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// cachedResponse is the metadata stored next to a cached response body.
+type cachedResponse struct {
+	URL          string    `json:"url"`
+	Accept       string    `json:"accept,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// fetcher issues requests to one host through the HTTP cache.
+type fetcher struct {
+	client *http.Client
+	host   Host
+	opts   FetchOptions
+}
+
+func newFetcher(host string, opts FetchOptions) *fetcher {
+	return &fetcher{client: newGitHubClient(), host: ResolveHost(opts.Hosts, host), opts: opts}
+}
+
+// get returns the body of url. A cached copy is revalidated with
+// If-None-Match / If-Modified-Since, so unchanged data is not downloaded
+// again (and, for the API, does not count against the rate limit). Offline,
+// only the cached copy is used.
+func (f *fetcher) get(url, accept string) ([]byte, error) {
+	base := f.cachePath(url, accept)
+	meta, body, cached := f.readCached(base)
+	if f.opts.Offline {
+		if !cached {
+			return nil, fmt.Errorf("%s: %w", url, ErrOffline)
+		}
+		return body, nil
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = f.host.headers()
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if cached {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	resp, err := f.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		return body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("GitHub returned %d for %s", resp.StatusCode, url)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if base != "" && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		// Caching is best effort; a failed write only costs a download.
+		_ = f.writeCached(base, cachedResponse{
+			URL:          url,
+			Accept:       accept,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now().UTC(),
+		}, data)
+	}
+	return data, nil
+}
+
+// do sends req, waiting out short rate limits. The caller closes the body.
+func (f *fetcher) do(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := f.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if !rateLimited(resp) {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		wait, reset := rateLimitWait(resp, time.Now())
+		if wait > maxRateLimitWait || attempt == maxAttempts {
+			return nil, &RateLimitError{Host: f.host.Name, Reset: reset, Authenticated: req.Header.Get("Authorization") != ""}
+		}
+		sleep(wait)
+	}
+}
+
+// rateLimited reports whether GitHub refused a request because of a primary
+// or secondary rate limit.
+func rateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden &&
+		(resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != "")
+}
+
+// rateLimitWait returns how long to wait before retrying, from Retry-After or
+// X-RateLimit-Reset, and when the limit resets.
+func rateLimitWait(resp *http.Response, now time.Time) (time.Duration, time.Time) {
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		wait := time.Duration(secs) * time.Second
+		return wait, now.Add(wait)
+	}
+	if unix, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset := time.Unix(unix, 0)
+		return max(reset.Sub(now), 0), reset
+	}
+	// Secondary limits without a hint: GitHub asks clients to wait a minute.
+	return time.Minute, now.Add(time.Minute)
+}
+
+// cachePath returns the path, without extension, of the cached response for
+// url and accept, or "" when caching is disabled.
+func (f *fetcher) cachePath(url, accept string) string {
+	if f.opts.CacheDir == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(accept + " " + url))
+	return filepath.Join(HTTPCacheDir(f.opts.CacheDir), hex.EncodeToString(sum[:]))
+}
+
+func (f *fetcher) readCached(base string) (cachedResponse, []byte, bool) {
+	var meta cachedResponse
+	if base == "" {
+		return meta, nil, false
+	}
+	data, err := os.ReadFile(base + ".json")
+	if err != nil || json.Unmarshal(data, &meta) != nil {
+		return meta, nil, false
+	}
+	body, err := os.ReadFile(base + ".body")
+	if err != nil {
+		return meta, nil, false
+	}
+	return meta, body, true
+}
+
+func (f *fetcher) writeCached(base string, meta cachedResponse, body []byte) error {
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The body goes first so metadata never describes a missing body.
+	if err := fsutil.WriteFile(base+".body", body, 0o644); err != nil {
+		return err
+	}
+	return fsutil.WriteFile(base+".json", data, 0o644)
+}
@@ -24,43 +24,50 @@ func ScanLocal(repoPath string) ([]*Skill, error) {
 			continue
 		}
 
-		skillDir := filepath.Join(repoPath, entry.Name())
-		skillFile := filepath.Join(skillDir, "SKILL.md")
-
-		data, err := os.ReadFile(skillFile)
+		s, err := LoadLocal(filepath.Join(repoPath, entry.Name()))
 		if err != nil {
-			continue // no SKILL.md, not a skill directory
+			continue // no SKILL.md or malformed, not a skill directory
 		}
 
-		fm, body, err := ParseFrontmatter(string(data))
-		if err != nil {
-			continue // malformed SKILL.md, skip
-		}
+		skills = append(skills, s)
+	}
 
-		s := &Skill{
-			Frontmatter:  fm,
-			DirName:      entry.Name(),
-			Path:         skillDir,
-			Source:       SourceLocal,
-			MarkdownBody: body,
-		}
+	return skills, nil
+}
 
-		// Discover reference files (check both singular and plural)
-		s.ReferenceFiles = discoverFiles(skillDir, "reference")
-		if len(s.ReferenceFiles) == 0 {
-			s.ReferenceFiles = discoverFiles(skillDir, "references")
-		}
+// LoadLocal reads a single skill directory containing a SKILL.md file.
+func LoadLocal(skillDir string) (*Skill, error) {
+	data, err := os.ReadFile(filepath.Join(skillDir, "SKILL.md"))
+	if err != nil {
+		return nil, err
+	}
 
-		// Discover example files
-		s.ExampleFiles = discoverFiles(skillDir, "examples")
+	fm, body, err := ParseFrontmatter(string(data))
+	if err != nil {
+		return nil, err
+	}
 
-		// Discover asset files
-		s.AssetFiles = discoverFiles(skillDir, "assets")
+	s := &Skill{
+		Frontmatter:  fm,
+		DirName:      filepath.Base(skillDir),
+		Path:         skillDir,
+		Source:       SourceLocal,
+		MarkdownBody: body,
+	}
 
-		skills = append(skills, s)
+	// Discover reference files (check both singular and plural)
+	s.ReferenceFiles = discoverFiles(skillDir, "reference")
+	if len(s.ReferenceFiles) == 0 {
+		s.ReferenceFiles = discoverFiles(skillDir, "references")
 	}
 
-	return skills, nil
+	// Discover example files
+	s.ExampleFiles = discoverFiles(skillDir, "examples")
+
+	// Discover asset files
+	s.AssetFiles = discoverFiles(skillDir, "assets")
+
+	return s, nil
 }
 
 // discoverFiles lists files recursively under a subdirectory, returning relative paths.
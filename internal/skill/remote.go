@@ -2,85 +2,203 @@ package skill
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
 )
 
-// GitHubContent represents a file entry from the GitHub API.
-type GitHubContent struct {
-	Name        string `json:"name"`
-	Path        string `json:"path"`
-	Type        string `json:"type"` // "file" or "dir"
-	DownloadURL string `json:"download_url"`
+// listWorkers bounds concurrent SKILL.md downloads while listing a repo.
+const listWorkers = 8
+
+// errNotFound is returned for a 404 from GitHub.
+var errNotFound = errors.New("not found")
+
+// gitTree is the response of the git trees API.
+type gitTree struct {
+	SHA       string         `json:"sha"`
+	Tree      []gitTreeEntry `json:"tree"`
+	Truncated bool           `json:"truncated"`
 }
 
-// FetchRemoteList fetches available skills from a GitHub repository.
-func FetchRemoteList(owner, repo string) ([]*Skill, error) {
-	client := newGitHubClient()
+type gitTreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"` // "blob" or "tree"
+}
+
+// FetchRemoteList fetches available skills from a GitHub repository: every
+// top-level directory with a SKILL.md. The repository tree is read with a
+// single API request; the SKILL.md files are then downloaded in parallel from
+// the host's raw endpoint, which does not count against the API rate limit.
+// Responses are cached under opts.CacheDir and revalidated on the next call.
+func FetchRemoteList(r RepoRef, opts FetchOptions) ([]*Skill, error) {
+	f := newFetcher(r.HostName(), opts)
 
-	// List top-level directories
-	entries, err := listContents(client, owner, repo, "")
+	tree, err := fetchTree(f, r, DefaultRef)
 	if err != nil {
 		return nil, fmt.Errorf("listing repo contents: %w", err)
 	}
+	dirs := skillDirs(tree)
+
+	skills := make([]*Skill, len(dirs))
+	errs := make([]error, len(dirs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, listWorkers)
+	for i, dir := range dirs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			content, err := fetchFile(f, r, DefaultRef, dir+"/SKILL.md")
+			if errors.Is(err, errNotFound) {
+				return // no SKILL.md, not a skill
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("fetching %s/SKILL.md: %w", dir, err)
+				return
+			}
+			fm, body, err := ParseFrontmatter(content)
+			if err != nil {
+				return
+			}
+			skills[i] = &Skill{
+				Frontmatter:  fm,
+				DirName:      dir,
+				Source:       SourceRemote,
+				MarkdownBody: body,
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
 
-	var skills []*Skill
-	for _, entry := range entries {
-		if entry.Type != "dir" {
-			continue
-		}
-		if strings.HasPrefix(entry.Name, ".") {
-			continue
-		}
-
-		// Check for SKILL.md
-		skillContent, err := fetchFile(client, owner, repo, entry.Name+"/SKILL.md")
-		if err != nil {
-			continue // no SKILL.md, not a skill
+	result := skills[:0]
+	for _, s := range skills {
+		if s != nil {
+			result = append(result, s)
 		}
+	}
+	return result, nil
+}
 
-		fm, body, err := ParseFrontmatter(skillContent)
-		if err != nil {
-			continue
+// skillDirs returns the sorted, non-hidden top-level directories of tree
+// that contain a SKILL.md. A truncated tree may not list every SKILL.md, so
+// then every top-level directory is a candidate.
+func skillDirs(tree *gitTree) []string {
+	var dirs []string
+	for _, e := range tree.Tree {
+		dir, file, nested := strings.Cut(e.Path, "/")
+		switch {
+		case strings.HasPrefix(dir, "."):
+		case e.Type == "blob" && nested && file == "SKILL.md":
+			dirs = append(dirs, dir)
+		case tree.Truncated && e.Type == "tree" && !nested:
+			dirs = append(dirs, dir)
 		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
 
-		skills = append(skills, &Skill{
-			Frontmatter:  fm,
-			DirName:      entry.Name,
-			Source:       SourceRemote,
-			MarkdownBody: body,
-		})
+// FetchRemoteSkill downloads a skill from GitHub at ref (DefaultRef when
+// empty) into its cache entry under opts.CacheDir. The commit ref points at
+// is looked up first (a conditional request that is free when unchanged); if
+// the cache entry already holds that commit and is intact, it is used as is.
+// Otherwise the tarball is staged next to the entry and swapped in whole, so
+// files removed upstream do not linger from an earlier download. Offline, the
+// cache entry is used if present.
+func FetchRemoteSkill(r RepoRef, ref string, opts FetchOptions) (*Skill, error) {
+	if ref == "" {
+		ref = DefaultRef
+	}
+	source := RemoteSource(r, ref)
+	destDir := CachePath(opts.CacheDir, r, ref)
+	cached, cacheErr := LoadCacheEntry(destDir)
+	if cacheErr == nil {
+		cacheErr = VerifyCacheEntry(cached)
 	}
 
-	return skills, nil
-}
+	if opts.Offline {
+		if cacheErr != nil {
+			return nil, fmt.Errorf("%s: %w", source, ErrOffline)
+		}
+		return loadRemoteSkill(destDir, r.Repo, cached.Meta)
+	}
 
-// FetchRemoteSkill downloads a skill from GitHub to the local cache directory.
-func FetchRemoteSkill(owner, repo, cacheDir string) (*Skill, error) {
-	client := newGitHubClient()
+	f := newFetcher(r.HostName(), opts)
+	commit, err := fetchCommit(f, r, ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", source, err)
+	}
+	if cacheErr == nil && cached.Meta.Commit == commit {
+		return loadRemoteSkill(destDir, r.Repo, cached.Meta)
+	}
 
-	destDir := filepath.Join(cacheDir, repo)
-	if err := os.MkdirAll(destDir, 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(destDir), 0o755); err != nil {
+		return nil, err
+	}
+	staged, err := fsutil.TempSibling(destDir)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staged)
+	if err := os.Mkdir(staged, 0o755); err != nil {
 		return nil, err
 	}
 
-	// Download all files recursively
-	if err := downloadDir(client, owner, repo, "", destDir); err != nil {
+	// One tarball request per ref instead of one contents call per directory.
+	if err := downloadTarball(f, r, commit, staged); err != nil {
 		return nil, fmt.Errorf("downloading skill: %w", err)
 	}
 
-	// Parse the downloaded SKILL.md
-	skillFile := filepath.Join(destDir, "SKILL.md")
-	data, err := os.ReadFile(skillFile)
+	data, err := os.ReadFile(filepath.Join(staged, "SKILL.md"))
 	if err != nil {
 		return nil, fmt.Errorf("reading SKILL.md: %w", err)
 	}
+	if _, _, err := ParseFrontmatter(string(data)); err != nil {
+		return nil, fmt.Errorf("parsing SKILL.md: %w", err)
+	}
 
+	digest, err := DirHash(staged)
+	if err != nil {
+		return nil, err
+	}
+	meta := CacheMeta{
+		Source:    source,
+		Host:      r.HostName(),
+		Owner:     r.Owner,
+		Repo:      r.Repo,
+		Ref:       ref,
+		Commit:    commit,
+		FetchedAt: time.Now().UTC(),
+		Digest:    digest,
+	}
+	if err := WriteCacheMeta(staged, meta); err != nil {
+		return nil, err
+	}
+	if err := fsutil.Swap(staged, destDir); err != nil {
+		return nil, fmt.Errorf("replacing cached %s: %w", meta.Source, err)
+	}
+	return loadRemoteSkill(destDir, r.Repo, meta)
+}
+
+// loadRemoteSkill reads a downloaded skill from its cache entry.
+func loadRemoteSkill(dir, repo string, meta CacheMeta) (*Skill, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		return nil, fmt.Errorf("reading SKILL.md: %w", err)
+	}
 	fm, body, err := ParseFrontmatter(string(data))
 	if err != nil {
 		return nil, fmt.Errorf("parsing SKILL.md: %w", err)
@@ -89,146 +207,161 @@ func FetchRemoteSkill(owner, repo, cacheDir string) (*Skill, error) {
 	s := &Skill{
 		Frontmatter:  fm,
 		DirName:      repo,
-		Path:         destDir,
+		Path:         dir,
 		Source:       SourceRemote,
+		Origin:       meta.Source,
+		Commit:       meta.Commit,
 		MarkdownBody: body,
 	}
 
-	s.ReferenceFiles = discoverFiles(destDir, "reference")
+	s.ReferenceFiles = discoverFiles(dir, "reference")
 	if len(s.ReferenceFiles) == 0 {
-		s.ReferenceFiles = discoverFiles(destDir, "references")
+		s.ReferenceFiles = discoverFiles(dir, "references")
 	}
-	s.ExampleFiles = discoverFiles(destDir, "examples")
-	s.AssetFiles = discoverFiles(destDir, "assets")
+	s.ExampleFiles = discoverFiles(dir, "examples")
+	s.AssetFiles = discoverFiles(dir, "assets")
 
 	return s, nil
 }
 
-// ParseRepoURL extracts owner/repo from "github.com/owner/repo" format.
-func ParseRepoURL(url string) (owner, repo string, ok bool) {
+// ParseRepoURL parses a repository reference: "owner/repo" on github.com,
+// or host-qualified as "host/owner/repo", optionally with a scheme and a
+// ".git" suffix. The first element is taken as a host when it contains a dot
+// or a port, or is "localhost".
+func ParseRepoURL(url string) (RepoRef, bool) {
 	url = strings.TrimPrefix(url, "https://")
 	url = strings.TrimPrefix(url, "http://")
 	url = strings.TrimSuffix(url, "/")
 	url = strings.TrimSuffix(url, ".git")
 
 	parts := strings.Split(url, "/")
-	if len(parts) < 3 || parts[0] != "github.com" {
-		return "", "", false
+	var r RepoRef
+	switch {
+	case len(parts) >= 3 && isHostName(parts[0]):
+		r = RepoRef{Host: strings.ToLower(parts[0]), Owner: parts[1], Repo: parts[2]}
+	case len(parts) == 2 && !isHostName(parts[0]):
+		r = RepoRef{Host: DefaultHost, Owner: parts[0], Repo: parts[1]}
+	default:
+		return RepoRef{}, false
+	}
+	if r.Owner == "" || r.Repo == "" {
+		return RepoRef{}, false
 	}
-	return parts[1], parts[2], true
+	return r, true
 }
 
-func newGitHubClient() *http.Client {
-	return &http.Client{Timeout: 30 * time.Second}
+func isHostName(s string) bool {
+	return strings.ContainsAny(s, ".:") || s == "localhost"
 }
 
-func githubHeaders() http.Header {
-	h := http.Header{}
-	h.Set("Accept", "application/vnd.github.v3+json")
-	h.Set("User-Agent", "aisk/0.1.0")
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		h.Set("Authorization", "Bearer "+token)
-	}
-	return h
+func newGitHubClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
 }
 
-func listContents(client *http.Client, owner, repo, path string) ([]GitHubContent, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, path)
-	req, err := http.NewRequest("GET", url, nil)
+// fetchTree reads the whole repository tree at ref in one request.
+func fetchTree(f *fetcher, r RepoRef, ref string) (*gitTree, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s?recursive=1", f.host.APIURL, r.Owner, r.Repo, neturl.PathEscape(ref))
+	data, err := f.get(url, "")
 	if err != nil {
 		return nil, err
 	}
-	req.Header = githubHeaders()
-
-	resp, err := client.Do(req)
-	if err != nil {
+	var tree gitTree
+	if err := json.Unmarshal(data, &tree); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return &tree, nil
+}
 
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("GitHub API returned %d", resp.StatusCode)
-	}
+// CommitRelation is how a commit relates to an earlier one.
+type CommitRelation int
 
-	var entries []GitHubContent
-	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
-		return nil, err
+const (
+	CommitSame       CommitRelation = iota // the same commit
+	CommitDescendant                       // a descendant: history was only added to
+	CommitRewritten                        // not a descendant: history was rewritten, e.g. by a force-push
+)
+
+func (c CommitRelation) String() string {
+	switch c {
+	case CommitSame:
+		return "same"
+	case CommitDescendant:
+		return "descendant"
+	default:
+		return "rewritten"
 	}
-	return entries, nil
 }
 
-func fetchFile(client *http.Client, owner, repo, path string) (string, error) {
-	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/main/%s", owner, repo, path)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", err
+// CompareCommits reports how head relates to base in repository r. A base
+// commit GitHub no longer knows counts as rewritten.
+func CompareCommits(r RepoRef, base, head string, opts FetchOptions) (CommitRelation, error) {
+	if base == head {
+		return CommitSame, nil
 	}
-	req.Header.Set("User-Agent", "aisk/0.1.0")
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+	f := newFetcher(r.HostName(), opts)
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s", f.host.APIURL, r.Owner, r.Repo, neturl.PathEscape(base), neturl.PathEscape(head))
+	data, err := f.get(url, "application/vnd.github+json")
+	if errors.Is(err, errNotFound) {
+		return CommitRewritten, nil
 	}
-
-	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return 0, fmt.Errorf("comparing %s with %s: %w", head, base, err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("status %d for %s", resp.StatusCode, path)
+	var cmp struct {
+		Status string `json:"status"`
 	}
+	if err := json.Unmarshal(data, &cmp); err != nil {
+		return 0, fmt.Errorf("parsing comparison of %s with %s: %w", head, base, err)
+	}
+	switch cmp.Status {
+	case "identical":
+		return CommitSame, nil
+	case "ahead":
+		return CommitDescendant, nil
+	case "behind", "diverged":
+		return CommitRewritten, nil
+	}
+	return 0, fmt.Errorf("unexpected comparison status %q for %s", cmp.Status, head)
+}
 
-	data, err := io.ReadAll(resp.Body)
+// fetchCommit returns the full SHA of the commit ref points at.
+func fetchCommit(f *fetcher, r RepoRef, ref string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", f.host.APIURL, r.Owner, r.Repo, neturl.PathEscape(ref))
+	data, err := f.get(url, "application/vnd.github.sha")
 	if err != nil {
 		return "", err
 	}
-	return string(data), nil
+	return strings.TrimSpace(string(data)), nil
 }
 
-func downloadDir(client *http.Client, owner, repo, path, destDir string) error {
-	entries, err := listContents(client, owner, repo, path)
+func fetchFile(f *fetcher, r RepoRef, ref, path string) (string, error) {
+	url := fmt.Sprintf("%s/%s/%s/%s/%s", f.host.RawURL, r.Owner, r.Repo, ref, path)
+	data, err := f.get(url, "")
 	if err != nil {
-		return err
-	}
-
-	for _, entry := range entries {
-		if entry.Type == "dir" {
-			subDir := filepath.Join(destDir, entry.Name)
-			if err := os.MkdirAll(subDir, 0o755); err != nil {
-				return err
-			}
-			if err := downloadDir(client, owner, repo, entry.Path, subDir); err != nil {
-				return err
-			}
-		} else if entry.DownloadURL != "" {
-			content, err := downloadURL(client, entry.DownloadURL)
-			if err != nil {
-				return fmt.Errorf("downloading %s: %w", entry.Path, err)
-			}
-			dest := filepath.Join(destDir, entry.Name)
-			if err := os.WriteFile(dest, content, 0o644); err != nil {
-				return err
-			}
-		}
+		return "", err
 	}
-	return nil
+	return string(data), nil
 }
 
-func downloadURL(client *http.Client, url string) ([]byte, error) {
+// downloadTarball extracts the repository at ref into destDir, dropping the
+// archive's top-level "<owner>-<repo>-<sha>" directory (see ExtractArchive).
+// Tarballs are not kept in the HTTP cache: the extracted cache entry serves
+// that role.
+func downloadTarball(f *fetcher, r RepoRef, ref, destDir string) error {
+	url := fmt.Sprintf("%s/repos/%s/%s/tarball/%s", f.host.APIURL, r.Owner, r.Repo, neturl.PathEscape(ref))
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("User-Agent", "aisk/0.1.0")
-	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+		return err
 	}
-
-	resp, err := client.Do(req)
+	req.Header = f.host.headers()
+	resp, err := f.do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub returned %d for %s", resp.StatusCode, url)
+	}
 
-	return io.ReadAll(resp.Body)
+	return ExtractArchive(resp.Body, destDir)
 }
@@ -1,34 +1,405 @@
 package skill
 
-import "testing"
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
 
 func TestParseRepoURL(t *testing.T) {
 	tests := []struct {
-		input     string
-		wantOwner string
-		wantRepo  string
-		wantOK    bool
+		input  string
+		want   RepoRef
+		wantOK bool
 	}{
-		{"github.com/user/repo", "user", "repo", true},
-		{"https://github.com/user/repo", "user", "repo", true},
-		{"https://github.com/user/repo.git", "user", "repo", true},
-		{"https://github.com/user/repo/", "user", "repo", true},
-		{"gitlab.com/user/repo", "", "", false},
-		{"not-a-url", "", "", false},
-		{"github.com/user", "", "", false},
+		{"github.com/user/repo", RepoRef{"github.com", "user", "repo"}, true},
+		{"https://github.com/user/repo", RepoRef{"github.com", "user", "repo"}, true},
+		{"https://github.com/user/repo.git", RepoRef{"github.com", "user", "repo"}, true},
+		{"https://github.com/user/repo/", RepoRef{"github.com", "user", "repo"}, true},
+		{"user/repo", RepoRef{"github.com", "user", "repo"}, true},
+		{"GHE.example.com/team/skills", RepoRef{"ghe.example.com", "team", "skills"}, true},
+		{"https://localhost:8080/team/skills.git", RepoRef{"localhost:8080", "team", "skills"}, true},
+		{"not-a-url", RepoRef{}, false},
+		{"github.com/user", RepoRef{}, false},
+		{"user/repo/extra", RepoRef{}, false},
+		{"github.com//repo", RepoRef{}, false},
 	}
 
 	for _, tt := range tests {
-		owner, repo, ok := ParseRepoURL(tt.input)
+		got, ok := ParseRepoURL(tt.input)
 		if ok != tt.wantOK {
 			t.Errorf("ParseRepoURL(%q) ok = %v, want %v", tt.input, ok, tt.wantOK)
 			continue
 		}
-		if ok {
-			if owner != tt.wantOwner || repo != tt.wantRepo {
-				t.Errorf("ParseRepoURL(%q) = (%q, %q), want (%q, %q)",
-					tt.input, owner, repo, tt.wantOwner, tt.wantRepo)
+		if ok && got != tt.want {
+			t.Errorf("ParseRepoURL(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// fakeGitHub serves the commits, trees, tarball and raw endpoints for one
+// repository. Responses carry an ETag derived from the commit, so unchanged
+// data revalidates with 304.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	files    map[string]string // path -> contents of the repository at main
+	commit   string
+	apiCalls atomic.Int32
+	tarballs atomic.Int32
+	served   atomic.Int32      // 200 responses
+	auth     string            // Authorization of the last tree request
+	compare  map[string]string // "base...head" -> comparison status; missing pairs are 404
+}
+
+func (f *fakeGitHub) set(commit string, change func(files map[string]string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commit = commit
+	if change != nil {
+		change(f.files)
+	}
+}
+
+func (f *fakeGitHub) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	// notModified answers a conditional request for unchanged data.
+	notModified := func(w http.ResponseWriter, r *http.Request, etag string) bool {
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+		f.served.Add(1)
+		return false
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/repos/o/r/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		f.apiCalls.Add(1)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Header.Get("Accept") != "application/vnd.github.sha" {
+			t.Errorf("unexpected Accept %q", r.Header.Get("Accept"))
+		}
+		if !notModified(w, r, `"`+f.commit+`"`) {
+			_, _ = w.Write([]byte(f.commit))
+		}
+	})
+	mux.HandleFunc("/api/repos/o/r/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		f.apiCalls.Add(1)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.auth = r.Header.Get("Authorization")
+		if r.URL.Query().Get("recursive") != "1" {
+			t.Errorf("tree request should be recursive: %s", r.URL)
+		}
+		if notModified(w, r, `"tree-`+f.commit+`"`) {
+			return
+		}
+		tree := gitTree{SHA: f.commit}
+		dirs := make(map[string]bool)
+		for p := range f.files {
+			if dir, _, nested := strings.Cut(p, "/"); nested && !dirs[dir] {
+				dirs[dir] = true
+				tree.Tree = append(tree.Tree, gitTreeEntry{Path: dir, Type: "tree"})
 			}
+			tree.Tree = append(tree.Tree, gitTreeEntry{Path: p, Type: "blob"})
+		}
+		_ = json.NewEncoder(w).Encode(tree)
+	})
+	mux.HandleFunc("/api/repos/o/r/tarball/", func(w http.ResponseWriter, r *http.Request) {
+		f.apiCalls.Add(1)
+		f.tarballs.Add(1)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if got := strings.TrimPrefix(r.URL.Path, "/api/repos/o/r/tarball/"); got != f.commit {
+			t.Errorf("tarball requested for %q, want the resolved commit %q", got, f.commit)
+		}
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		top := "o-r-" + f.commit + "/"
+		_ = tw.WriteHeader(&tar.Header{Name: top, Typeflag: tar.TypeDir, Mode: 0o755})
+		for p, content := range f.files {
+			_ = tw.WriteHeader(&tar.Header{Name: top + p, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))})
+			_, _ = tw.Write([]byte(content))
+		}
+		_ = tw.Close()
+		_ = gz.Close()
+	})
+	mux.HandleFunc("/raw/o/r/main/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		content, ok := f.files[strings.TrimPrefix(r.URL.Path, "/raw/o/r/main/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if !notModified(w, r, `"`+f.commit+`-`+r.URL.Path+`"`) {
+			_, _ = w.Write([]byte(content))
+		}
+	})
+	mux.HandleFunc("/api/repos/o/r/compare/", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		status, ok := f.compare[strings.TrimPrefix(r.URL.Path, "/api/repos/o/r/compare/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": status})
+	})
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected API request %s", r.URL)
+		http.NotFound(w, r)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testHosts points github.com at url and hides any real credentials.
+func testHosts(t *testing.T, url string) map[string]Host {
+	t.Helper()
+	isolateCredentials(t)
+	return map[string]Host{DefaultHost: {APIURL: url + "/api", RawURL: url + "/raw"}}
+}
+
+func isolateCredentials(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("GH_CONFIG_DIR", dir)
+	t.Setenv("NETRC", filepath.Join(dir, "netrc"))
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(env, "")
+	}
+}
+
+var testRepo = RepoRef{Owner: "o", Repo: "r"}
+
+func skillMD(name string) string {
+	return "---\nname: " + name + "\ndescription: d\nversion: 1.0.0\n---\n# " + name + "\n"
+}
+
+func listNames(t *testing.T, opts FetchOptions) string {
+	t.Helper()
+	skills, err := FetchRemoteList(testRepo, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range skills {
+		names = append(names, s.DirName+"="+s.Frontmatter.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestFetchRemoteList_OneAPIRequestAndRevalidation(t *testing.T) {
+	gh := &fakeGitHub{commit: "abc1234", files: map[string]string{
+		"README.md":                  "repo readme",
+		".github/SKILL.md":           skillMD("hidden"),
+		"docs/notes.md":              "not a skill",
+		"broken/SKILL.md":            "no frontmatter",
+		"alpha/SKILL.md":             skillMD("alpha"),
+		"alpha/reference/x.md":       "ref",
+		"beta/SKILL.md":              skillMD("beta"),
+		"beta/nested/gamma/SKILL.md": skillMD("nested"),
+	}}
+	srv := gh.start(t)
+	opts := FetchOptions{CacheDir: t.TempDir(), Hosts: testHosts(t, srv.URL)}
+
+	if got := listNames(t, opts); got != "alpha=alpha,beta=beta" {
+		t.Fatalf("unexpected skills %v", got)
+	}
+	if n := gh.apiCalls.Load(); n != 1 {
+		t.Fatalf("expected 1 API request, got %d", n)
+	}
+
+	// Unchanged data is revalidated, not downloaded again.
+	served := gh.served.Load()
+	if got := listNames(t, opts); got != "alpha=alpha,beta=beta" {
+		t.Fatalf("unexpected skills from revalidated cache %v", got)
+	}
+	if gh.served.Load() != served {
+		t.Fatalf("expected only 304 responses on the second listing, got %d new downloads", gh.served.Load()-served)
+	}
+
+	// Offline listing works without the server.
+	srv.Close()
+	opts.Offline = true
+	if got := listNames(t, opts); got != "alpha=alpha,beta=beta" {
+		t.Fatalf("unexpected offline skills %v", got)
+	}
+	if _, err := FetchRemoteList(testRepo, FetchOptions{CacheDir: t.TempDir(), Offline: true}); !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected ErrOffline for an empty cache, got %v", err)
+	}
+}
+
+func TestFetchRemoteSkill_ReusesEntryForSameCommit(t *testing.T) {
+	gh := &fakeGitHub{commit: "abc1234", files: map[string]string{
+		"SKILL.md":           skillMD("remote"),
+		"reference/old.md":   "old",
+		"reference/guide.md": "guide",
+		"examples/demo.md":   "demo",
+	}}
+	srv := gh.start(t)
+	opts := FetchOptions{CacheDir: t.TempDir(), Hosts: testHosts(t, srv.URL)}
+
+	s, err := FetchRemoteSkill(testRepo, "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Frontmatter.Name != "remote" || s.Origin != "github.com/o/r@main" || len(s.ReferenceFiles) != 2 || len(s.ExampleFiles) != 1 {
+		t.Fatalf("unexpected skill %+v", s)
+	}
+	if gh.apiCalls.Load() != 2 || gh.tarballs.Load() != 1 {
+		t.Fatalf("expected a commit lookup and one tarball, got %d API requests", gh.apiCalls.Load())
+	}
+
+	// Same commit: the cache entry is reused without a download.
+	if _, err := FetchRemoteSkill(testRepo, "main", opts); err != nil {
+		t.Fatal(err)
+	}
+	if gh.tarballs.Load() != 1 {
+		t.Fatal("unchanged commit should not download the tarball again")
+	}
+
+	// A file deleted upstream must not survive the next download.
+	gh.set("def5678", func(files map[string]string) { delete(files, "reference/old.md") })
+	if _, err := FetchRemoteSkill(testRepo, "main", opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Path, "reference", "old.md")); !os.IsNotExist(err) {
+		t.Fatalf("stale file kept after re-download: %v", err)
+	}
+
+	entries, err := ListCache(opts.CacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Meta.Commit != "def5678" {
+		t.Fatalf("expected one entry at the new commit, got %+v", entries)
+	}
+	if err := VerifyCacheEntry(entries[0]); err != nil {
+		t.Fatalf("fresh download should verify: %v", err)
+	}
+
+	srv.Close()
+	opts.Offline = true
+	if s, err := FetchRemoteSkill(testRepo, "main", opts); err != nil || s.Frontmatter.Name != "remote" {
+		t.Fatalf("offline fetch should use the cache entry, got %v", err)
+	}
+	if _, err := FetchRemoteSkill(testRepo, "v2", opts); !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected ErrOffline for an uncached ref, got %v", err)
+	}
+}
+
+func TestFetcher_RateLimit(t *testing.T) {
+	var calls atomic.Int32
+	var resetIn time.Duration
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 || resetIn > time.Minute {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(resetIn).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	isolateCredentials(t)
+
+	var slept []time.Duration
+	origSleep := sleep
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	t.Cleanup(func() { sleep = origSleep })
+
+	f := newFetcher(DefaultHost, FetchOptions{})
+	resetIn = 2 * time.Second
+	body, err := f.get(srv.URL, "")
+	if err != nil || string(body) != "ok" || len(slept) != 1 || slept[0] > 2*time.Second {
+		t.Fatalf("expected one short backoff then success, got %q, %v, slept %v", body, err, slept)
+	}
+
+	resetIn = time.Hour
+	_, err = f.get(srv.URL, "")
+	var rl *RateLimitError
+	if !errors.As(err, &rl) || !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+		t.Fatalf("expected a RateLimitError suggesting GITHUB_TOKEN, got %v", err)
+	}
+	if len(slept) != 1 {
+		t.Fatalf("should not wait for a limit that resets in an hour, slept %v", slept)
+	}
+}
+
+func TestDownloadTarball_RejectsEscapingPaths(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		_ = tw.WriteHeader(&tar.Header{Name: "o-r-1/../../evil", Typeflag: tar.TypeReg, Mode: 0o644, Size: 1})
+		_, _ = tw.Write([]byte("x"))
+		_ = tw.Close()
+		_ = gz.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	dest := filepath.Join(t.TempDir(), "skill")
+	f := newFetcher(DefaultHost, FetchOptions{Hosts: testHosts(t, srv.URL)})
+	if err := downloadTarball(f, testRepo, "main", dest); err == nil {
+		t.Fatal("expected an error for an entry escaping the destination")
+	}
+}
+
+func TestCompareCommits(t *testing.T) {
+	fake := &fakeGitHub{commit: "c2", compare: map[string]string{
+		"c1...c2": "ahead",
+		"c1...c3": "diverged",
+		"c2...c1": "behind",
+	}}
+	srv := fake.start(t)
+	opts := FetchOptions{Hosts: testHosts(t, srv.URL)}
+
+	tests := []struct {
+		base, head string
+		want       CommitRelation
+	}{
+		{"c1", "c1", CommitSame},
+		{"c1", "c2", CommitDescendant},
+		{"c1", "c3", CommitRewritten},
+		{"c2", "c1", CommitRewritten},
+		{"gone", "c2", CommitRewritten},
+	}
+	for _, tt := range tests {
+		got, err := CompareCommits(testRepo, tt.base, tt.head, opts)
+		if err != nil || got != tt.want {
+			t.Errorf("CompareCommits(%s, %s) = %v, %v; want %v", tt.base, tt.head, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseRemoteSource(t *testing.T) {
+	r, ref, ok := ParseRemoteSource("ghe.example.com/team/skills@v1.2")
+	if !ok || r != (RepoRef{"ghe.example.com", "team", "skills"}) || ref != "v1.2" {
+		t.Fatalf("ParseRemoteSource = %+v, %q, %v", r, ref, ok)
+	}
+	if got, _, _ := ParseRemoteSource(RemoteSource(testRepo, "")); got.String() != "github.com/o/r" {
+		t.Fatalf("round trip = %s", got)
+	}
+	for _, source := range []string{"local", "archive:/tmp/x-1.0.0.tar.gz", "https://skills.acme.com/a/index.json#review@1.0.0", "github.com/o/r"} {
+		if _, _, ok := ParseRemoteSource(source); ok {
+			t.Errorf("ParseRemoteSource(%q) should fail", source)
 		}
 	}
 }
@@ -0,0 +1,315 @@
+package skill
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yorch/aisk/internal/secret"
+)
+
+// Scan rules.
+const (
+	RuleSecret          = "secret"
+	RulePromptInjection = "prompt-injection"
+	RuleHiddenUnicode   = "hidden-unicode"
+	RulePipeToShell     = "pipe-to-shell"
+	RuleExecutableAsset = "executable-asset"
+	RuleUnscannable     = "unscannable"
+)
+
+// ScanIgnoreMarker on a line suppresses its warnings in lint, for documented
+// examples that would otherwise be flagged. The marker is part of the
+// content being scanned, so it never suppresses errors, and the install-time
+// scan shows suppressed warnings too.
+const ScanIgnoreMarker = "aisk-scan:ignore"
+
+// maxScanSize is the largest file whose contents are scanned.
+const maxScanSize = 4 << 20
+
+// ScanFinding is one problem the content scanner found in a skill file.
+type ScanFinding struct {
+	Path     string // slash-separated, relative to the skill directory
+	Line     int    // 1-based; 0 when the finding is about the whole file
+	Severity Severity
+	Rule     string
+	Message  string
+
+	// Suppressed is set on warnings of lines carrying ScanIgnoreMarker.
+	Suppressed bool
+}
+
+// Location returns "path" or "path:line".
+func (f ScanFinding) Location() string {
+	if f.Line == 0 {
+		return f.Path
+	}
+	return fmt.Sprintf("%s:%d", f.Path, f.Line)
+}
+
+// LintResult returns the finding as a lint result for its location.
+func (f ScanFinding) LintResult() LintResult {
+	return LintResult{Severity: f.Severity, Field: f.Location(), Message: f.Message}
+}
+
+var promptInjectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bignore (?:all |any )?(?:of )?(?:the |your )?(?:previous|prior|above|earlier) (?:instructions|prompts|messages|rules)`),
+	regexp.MustCompile(`(?i)\bdisregard (?:all |any )?(?:of )?(?:the |your )?(?:previous|prior|above|earlier|system) (?:instructions|prompts|rules|guidelines)`),
+	regexp.MustCompile(`(?i)\bforget (?:all |everything )?(?:of )?(?:the |your )?(?:previous |prior )?(?:instructions|rules|guidelines)`),
+	regexp.MustCompile(`(?i)\b(?:reveal|print|output|repeat) (?:your|the) system prompt`),
+	regexp.MustCompile(`(?i)\b(?:enter|enable|activate) (?:developer|DAN|jailbreak|god) mode`),
+	regexp.MustCompile(`(?i)\byou are no longer bound by`),
+	regexp.MustCompile(`(?i)\b(?:do not|don't|never) (?:tell|inform|alert|notify) the user`),
+	regexp.MustCompile(`(?i)\bwithout (?:telling|informing|notifying|asking) the user`),
+	regexp.MustCompile(`<\|(?:im_start|im_end|endoftext|system)\|>`),
+	regexp.MustCompile(`\[/?INST\]`),
+}
+
+var pipeToShellPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(?:curl|wget)\b[^|\n]*\|\s*(?:sudo\s+)?(?:ba|z|da)?sh\b`),
+	regexp.MustCompile(`(?i)\b(?:iex|invoke-expression)\b.*\b(?:iwr|invoke-webrequest|downloadstring)\b`),
+}
+
+// hiddenRune describes an invisible character and whether it can reorder
+// or hide text (an error) or is merely suspicious (a warning).
+func hiddenRune(r rune) (name string, severe bool, ok bool) {
+	switch {
+	case r >= 0x202A && r <= 0x202E:
+		return [...]string{"left-to-right embedding", "right-to-left embedding", "pop directional formatting", "left-to-right override", "right-to-left override"}[r-0x202A], true, true
+	case r >= 0x2066 && r <= 0x2069:
+		return [...]string{"left-to-right isolate", "right-to-left isolate", "first strong isolate", "pop directional isolate"}[r-0x2066], true, true
+	case r >= 0xE0000 && r <= 0xE007F:
+		return "tag character", true, true
+	case r == 0x200B:
+		return "zero width space", false, true
+	case r == 0x200C:
+		return "zero width non-joiner", false, true
+	case r == 0x200D:
+		return "zero width joiner", false, true
+	case r == 0x200E:
+		return "left-to-right mark", false, true
+	case r == 0x200F:
+		return "right-to-left mark", false, true
+	case r == 0x2060:
+		return "word joiner", false, true
+	case r == 0xFEFF:
+		return "zero width no-break space", false, true
+	}
+	return "", false, false
+}
+
+// scriptExtensions are file types that run as programs.
+var scriptExtensions = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".fish": true, ".command": true,
+	".ps1": true, ".bat": true, ".cmd": true, ".vbs": true,
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".app": true,
+}
+
+// nativeMagic are the leading bytes of native executables: ELF, PE and
+// Mach-O (both byte orders, and universal binaries).
+var nativeMagic = [][]byte{
+	[]byte("\x7fELF"),
+	[]byte("MZ"),
+	{0xFE, 0xED, 0xFA, 0xCE}, {0xFE, 0xED, 0xFA, 0xCF},
+	{0xCE, 0xFA, 0xED, 0xFE}, {0xCF, 0xFA, 0xED, 0xFE},
+	{0xCA, 0xFE, 0xBA, 0xBE},
+}
+
+// ScanDir scans every file of a skill directory, hidden ones included, for
+// credentials, prompt-injection markers, hidden Unicode characters,
+// piped remote scripts and executables under assets/. Symlinks to files are
+// scanned as the file they point at, since adapters copy or link that
+// content; any other entry that is not a regular file is an error.
+func ScanDir(dir string) ([]ScanFinding, error) {
+	// A symlinked skill directory is walked as its target.
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	var findings []ScanFinding
+	unscannable := func(rel, why string) {
+		findings = append(findings, ScanFinding{Path: rel, Severity: SeverityError, Rule: RuleUnscannable,
+			Message: why + "; its contents cannot be scanned"})
+	}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Stat(p)
+			if err != nil {
+				unscannable(rel, "dangling symlink")
+				return nil
+			}
+			if !target.Mode().IsRegular() {
+				unscannable(rel, "symlink to something other than a file")
+				return nil
+			}
+			info = target
+		case !d.Type().IsRegular():
+			unscannable(rel, "not a regular file")
+			return nil
+		}
+		data, err := readHead(p, info.Size())
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(rel, "assets/") {
+			if f, ok := scanAsset(rel, info.Mode(), data); ok {
+				findings = append(findings, f)
+			}
+		}
+		if isBinary(data) || info.Size() > maxScanSize {
+			return nil
+		}
+		findings = append(findings, ScanContent(rel, data)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", dir, err)
+	}
+	return findings, nil
+}
+
+// readHead reads a file, or only its start when it is too large to scan.
+func readHead(p string, size int64) ([]byte, error) {
+	if size <= maxScanSize {
+		return os.ReadFile(p)
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, err := f.Read(buf)
+	if n == 0 && err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// isBinary reports whether data looks like something other than text.
+func isBinary(data []byte) bool {
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0
+}
+
+func scanAsset(rel string, mode fs.FileMode, data []byte) (ScanFinding, bool) {
+	for _, magic := range nativeMagic {
+		if bytes.HasPrefix(data, magic) {
+			return ScanFinding{Path: rel, Severity: SeverityError, Rule: RuleExecutableAsset,
+				Message: "native executable under assets/; skills should not ship binaries"}, true
+		}
+	}
+	var why string
+	switch {
+	case bytes.HasPrefix(data, []byte("#!")):
+		why = "starts with #!"
+	case scriptExtensions[strings.ToLower(path.Ext(rel))]:
+		why = "has a " + path.Ext(rel) + " extension"
+	case runtime.GOOS != "windows" && mode&0o111 != 0:
+		why = "is marked executable"
+	default:
+		return ScanFinding{}, false
+	}
+	return ScanFinding{Path: rel, Severity: SeverityWarning, Rule: RuleExecutableAsset,
+		Message: "executable script under assets/ (" + why + "); review what it runs"}, true
+}
+
+// ScanContent scans the text of one file, reporting at most one finding per
+// rule and line. Warnings on lines containing ScanIgnoreMarker are marked
+// Suppressed.
+func ScanContent(rel string, data []byte) []ScanFinding {
+	var findings []ScanFinding
+	add := func(line int, sev Severity, rule, format string, args ...any) {
+		findings = append(findings, ScanFinding{Path: rel, Line: line, Severity: sev, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		n := i + 1
+		first := len(findings)
+		if m := secret.Find(line); m != nil {
+			if m.Format.Certain {
+				add(n, SeverityError, RuleSecret, "%s %s; remove it and rotate the credential", m.Format.Name, secret.Mask(m.Value))
+			} else {
+				add(n, SeverityWarning, RuleSecret, "possible %s %s", m.Format.Name, secret.Mask(m.Value))
+			}
+		}
+		for _, p := range promptInjectionPatterns {
+			if match := p.FindString(line); match != "" {
+				add(n, SeverityWarning, RulePromptInjection, "prompt-injection marker %q", match)
+				break
+			}
+		}
+		for _, p := range pipeToShellPatterns {
+			if match := p.FindString(line); match != "" {
+				add(n, SeverityWarning, RulePipeToShell, "pipes a downloaded script into a shell: %q", match)
+				break
+			}
+		}
+		if f, ok := scanHidden(rel, n, line); ok {
+			findings = append(findings, f)
+		}
+		if strings.Contains(line, ScanIgnoreMarker) {
+			for j := first; j < len(findings); j++ {
+				findings[j].Suppressed = findings[j].Severity == SeverityWarning
+			}
+		}
+	}
+	return findings
+}
+
+// scanHidden reports the invisible characters of one line. A byte order
+// mark at the very start of a file is allowed.
+func scanHidden(rel string, n int, line string) (ScanFinding, bool) {
+	var found []string
+	severe := false
+	seen := make(map[rune]bool)
+	for col, r := range line {
+		if r == utf8.RuneError || (r == 0xFEFF && n == 1 && col == 0) {
+			continue
+		}
+		name, bad, ok := hiddenRune(r)
+		if !ok || seen[r] {
+			continue
+		}
+		seen[r] = true
+		severe = severe || bad
+		found = append(found, fmt.Sprintf("U+%04X %s", r, name))
+	}
+	if len(found) == 0 {
+		return ScanFinding{}, false
+	}
+	f := ScanFinding{Path: rel, Line: n, Severity: SeverityWarning, Rule: RuleHiddenUnicode,
+		Message: "invisible character(s) " + strings.Join(found, ", ")}
+	if severe {
+		f.Severity = SeverityError
+		f.Message += "; they can hide or reorder text"
+	}
+	return f, true
+}
@@ -13,6 +13,8 @@ type SkillSource int
 const (
 	SourceLocal SkillSource = iota
 	SourceRemote
+	SourceRegistry
+	SourceArchive
 )
 
 func (s SkillSource) String() string {
@@ -21,6 +23,10 @@ func (s SkillSource) String() string {
 		return "local"
 	case SourceRemote:
 		return "remote"
+	case SourceRegistry:
+		return "registry"
+	case SourceArchive:
+		return "archive"
 	default:
 		return "unknown"
 	}
@@ -31,7 +37,10 @@ type Frontmatter struct {
 	Name         string   `yaml:"name"`
 	Description  string   `yaml:"description"`
 	Version      string   `yaml:"version"`
-	AllowedTools []string `yaml:"allowed-tools"`
+	AllowedTools ToolList `yaml:"allowed-tools"`
+	Dependencies []string `yaml:"dependencies"`
+	Globs        []string `yaml:"globs"`        // file patterns the skill applies to (Cursor)
+	AlwaysApply  bool     `yaml:"always-apply"` // attach to every request (Cursor)
 }
 
 // Skill represents a discovered skill with its metadata and content.
@@ -40,6 +49,8 @@ type Skill struct {
 	DirName        string      // directory name, e.g. "5-whys-skill"
 	Path           string      // absolute path to skill directory
 	Source         SkillSource // Local or Remote
+	Origin         string      // source key of a remote, registry or archive skill, "" for local ones
+	Commit         string      // commit a remote skill was fetched at
 	MarkdownBody   string      // SKILL.md content after frontmatter
 	ReferenceFiles []string    // relative paths under reference/ or references/
 	ExampleFiles   []string    // relative paths under examples/
@@ -54,6 +65,15 @@ func (s *Skill) DisplayVersion() string {
 	return s.Version
 }
 
+// SourceName returns the source recorded in the manifest for s: the remote
+// it was fetched from, or "local".
+func (s *Skill) SourceName() string {
+	if s.Source != SourceLocal && s.Origin != "" {
+		return s.Origin
+	}
+	return s.Source.String()
+}
+
 // ParseFrontmatter splits a SKILL.md file into YAML frontmatter and markdown body.
 func ParseFrontmatter(content string) (Frontmatter, string, error) {
 	// Normalize line endings
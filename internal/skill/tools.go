@@ -0,0 +1,238 @@
+package skill
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToolList is the allowed-tools frontmatter field. Claude Code writes it as
+// a comma-separated string ("Read, Grep, Bash(git status:*)"); a YAML list
+// is accepted too.
+type ToolList []string
+
+// UnmarshalYAML accepts a list or a comma-separated string.
+func (t *ToolList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*t = splitTools(n.Value)
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// splitTools splits a comma-separated tool list, leaving commas inside
+// parentheses alone.
+func splitTools(s string) []string {
+	var tools []string
+	depth, start := 0, 0
+	flush := func(end int) {
+		if tool := strings.TrimSpace(s[start:end]); tool != "" {
+			tools = append(tools, tool)
+		}
+	}
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				flush(i)
+				start = i + 1
+			}
+		}
+	}
+	flush(len(s))
+	return tools
+}
+
+// specifierKind is what goes between the parentheses of a tool grant.
+type specifierKind int
+
+const (
+	specNone    specifierKind = iota // the tool takes no specifier
+	specCommand                      // Bash: a command, with a trailing :* for a prefix
+	specPath                         // file tools: a gitignore-style path pattern
+	specDomain                       // WebFetch: domain:<host>
+)
+
+// claudeTools are the tool names Claude Code accepts in allowed-tools.
+var claudeTools = map[string]specifierKind{
+	"Bash":         specCommand,
+	"BashOutput":   specNone,
+	"Edit":         specPath,
+	"ExitPlanMode": specNone,
+	"Glob":         specNone,
+	"Grep":         specNone,
+	"KillShell":    specNone,
+	"LS":           specNone,
+	"MultiEdit":    specPath,
+	"NotebookEdit": specPath,
+	"NotebookRead": specPath,
+	"Read":         specPath,
+	"SlashCommand": specNone,
+	"Skill":        specNone,
+	"Task":         specNone,
+	"TodoWrite":    specNone,
+	"WebFetch":     specDomain,
+	"WebSearch":    specNone,
+	"Write":        specPath,
+}
+
+// riskyCommands are Bash command prefixes worth a second look in a grant.
+var riskyCommands = []string{"sudo", "rm", "curl", "wget", "ssh", "scp", "chmod", "chown", "dd", "eval", "sh", "bash", "zsh"}
+
+var toolNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// ToolGrant is one parsed allowed-tools entry, e.g. Bash(git status:*).
+type ToolGrant struct {
+	Tool      string
+	Specifier string // "" for a bare grant
+}
+
+// String returns the grant in allowed-tools syntax.
+func (g ToolGrant) String() string {
+	if g.Specifier == "" {
+		return g.Tool
+	}
+	return g.Tool + "(" + g.Specifier + ")"
+}
+
+// ParseToolGrant parses "Tool" or "Tool(specifier)".
+func ParseToolGrant(s string) (ToolGrant, error) {
+	s = strings.TrimSpace(s)
+	name, spec, hasSpec := strings.Cut(s, "(")
+	if hasSpec {
+		if !strings.HasSuffix(spec, ")") {
+			return ToolGrant{}, fmt.Errorf("%q is missing a closing parenthesis", s)
+		}
+		spec = strings.TrimSpace(strings.TrimSuffix(spec, ")"))
+		if spec == "" {
+			return ToolGrant{}, fmt.Errorf("%q has an empty specifier; drop the parentheses or name what it allows", s)
+		}
+	}
+	name = strings.TrimSpace(name)
+	if !toolNameRegex.MatchString(name) {
+		return ToolGrant{}, fmt.Errorf("%q is not a valid tool name", s)
+	}
+	return ToolGrant{Tool: name, Specifier: spec}, nil
+}
+
+// IsMCP reports whether g names an MCP server tool (mcp__server__tool).
+func (g ToolGrant) IsMCP() bool {
+	return strings.HasPrefix(g.Tool, "mcp__")
+}
+
+// Broad returns why g grants more than a skill usually needs, or "" when it
+// is narrow enough.
+func (g ToolGrant) Broad() string {
+	wildcard := g.Specifier == "" || g.Specifier == "*" || g.Specifier == ":*" || g.Specifier == "**" || g.Specifier == "/**"
+	switch g.Tool {
+	case "Bash":
+		if wildcard {
+			return "allows any shell command; narrow it, e.g. Bash(git status:*)"
+		}
+		cmd := strings.Fields(strings.TrimSuffix(g.Specifier, ":*"))
+		for _, risky := range riskyCommands {
+			if len(cmd) > 0 && cmd[0] == risky {
+				return fmt.Sprintf("allows running %s", risky)
+			}
+		}
+	case "Write", "Edit", "MultiEdit", "NotebookEdit":
+		if wildcard {
+			return "allows changing any file; narrow it to a path, e.g. " + g.Tool + "(docs/**)"
+		}
+	case "WebFetch":
+		if wildcard {
+			return "allows fetching any URL; narrow it, e.g. WebFetch(domain:example.com)"
+		}
+	default:
+		if g.IsMCP() && strings.Count(g.Tool, "__") == 1 {
+			return "allows every tool of the MCP server"
+		}
+	}
+	return ""
+}
+
+// LintAllowedTools checks allowed-tools entries against Claude Code's tool
+// names and grant syntax: malformed entries are errors, unknown tools and
+// broad grants warnings.
+func LintAllowedTools(tools []string) []LintResult {
+	var results []LintResult
+	add := func(sev Severity, format string, args ...any) {
+		results = append(results, LintResult{Severity: sev, Field: "allowed-tools", Message: fmt.Sprintf(format, args...)})
+	}
+	for _, raw := range tools {
+		g, err := ParseToolGrant(raw)
+		if err != nil {
+			add(SeverityError, "%v", err)
+			continue
+		}
+		kind, known := claudeTools[g.Tool]
+		switch {
+		case g.IsMCP():
+		case !known:
+			add(SeverityWarning, "unknown tool %q", g.Tool)
+			continue
+		case g.Specifier != "" && kind == specNone:
+			add(SeverityWarning, "%s does not take a specifier; %q grants all of %s", g.Tool, raw, g.Tool)
+			continue
+		case kind == specDomain && g.Specifier != "" && !strings.HasPrefix(g.Specifier, "domain:"):
+			add(SeverityError, "%q: WebFetch specifiers have the form domain:<host>", raw)
+			continue
+		}
+		if reason := g.Broad(); reason != "" {
+			add(SeverityWarning, "%s %s", g, reason)
+		}
+	}
+	return results
+}
+
+// ExpandedTools returns the entries of requested that installed does not
+// already grant: not listed, and not covered by a bare grant of the same
+// tool or a prefix grant ending in * that matches.
+func ExpandedTools(installed, requested []string) []string {
+	var grants []ToolGrant
+	for _, raw := range installed {
+		if g, err := ParseToolGrant(raw); err == nil {
+			grants = append(grants, g)
+		}
+	}
+	var expanded []string
+	for _, raw := range requested {
+		r, err := ParseToolGrant(raw)
+		if err != nil || !coveredBy(r, grants) {
+			expanded = append(expanded, raw)
+		}
+	}
+	return expanded
+}
+
+func coveredBy(r ToolGrant, grants []ToolGrant) bool {
+	for _, g := range grants {
+		if g.Tool != r.Tool {
+			continue
+		}
+		if g.Specifier == "" || g.Specifier == r.Specifier {
+			return true
+		}
+		if r.Specifier == "" {
+			continue
+		}
+		prefix, ok := strings.CutSuffix(g.Specifier, "*")
+		if ok && strings.HasPrefix(r.Specifier, strings.TrimSuffix(prefix, ":")) {
+			return true
+		}
+	}
+	return false
+}
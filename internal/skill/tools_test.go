@@ -0,0 +1,75 @@
+package skill
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAllowedTools_CommaSeparatedString(t *testing.T) {
+	fm, _, err := ParseFrontmatter("---\nname: x\nallowed-tools: Read, Grep, Bash(git diff:*, git log:*)\n---\nbody\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ToolList{"Read", "Grep", "Bash(git diff:*, git log:*)"}
+	if !reflect.DeepEqual(fm.AllowedTools, want) {
+		t.Fatalf("AllowedTools = %q, want %q", fm.AllowedTools, want)
+	}
+
+	fm, _, err = ParseFrontmatter("---\nname: x\nallowed-tools:\n  - Read\n  - Write(docs/**)\n---\nbody\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fm.AllowedTools, ToolList{"Read", "Write(docs/**)"}) {
+		t.Fatalf("list form: %q", fm.AllowedTools)
+	}
+}
+
+func TestLintAllowedTools(t *testing.T) {
+	tests := []struct {
+		tool     string
+		severity Severity
+		contains string // "" means no finding
+	}{
+		{"Read", 0, ""},
+		{"Bash(git status:*)", 0, ""},
+		{"WebFetch(domain:docs.example.com)", 0, ""},
+		{"mcp__github__create_issue", 0, ""},
+		{"Bash", SeverityWarning, "any shell command"},
+		{"Bash(*)", SeverityWarning, "any shell command"},
+		{"Bash(sudo apt-get:*)", SeverityWarning, "sudo"},
+		{"Write", SeverityWarning, "any file"},
+		{"WebFetch", SeverityWarning, "any URL"},
+		{"mcp__github", SeverityWarning, "MCP server"},
+		{"Browse", SeverityWarning, "unknown tool"},
+		{"Grep(*.go)", SeverityWarning, "does not take a specifier"},
+		{"Bash(git:*", SeverityError, "closing parenthesis"},
+		{"Bash()", SeverityError, "empty specifier"},
+		{"WebFetch(example.com)", SeverityError, "domain:"},
+	}
+	for _, tt := range tests {
+		results := LintAllowedTools([]string{tt.tool})
+		if tt.contains == "" {
+			if len(results) != 0 {
+				t.Errorf("%s: unexpected findings %+v", tt.tool, results)
+			}
+			continue
+		}
+		if len(results) != 1 || results[0].Severity != tt.severity || !strings.Contains(results[0].Message, tt.contains) {
+			t.Errorf("%s: got %+v, want %s containing %q", tt.tool, results, tt.severity, tt.contains)
+		}
+	}
+}
+
+func TestExpandedTools(t *testing.T) {
+	installed := []string{"Read", "Bash(git:*)", "Write(docs/**)"}
+	requested := []string{"Read(src/**)", "Bash(git status:*)", "Write(docs/**)", "Write", "Bash(npm test:*)", "WebFetch"}
+	got := ExpandedTools(installed, requested)
+	want := []string{"Write", "Bash(npm test:*)", "WebFetch"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ExpandedTools = %q, want %q", got, want)
+	}
+	if got := ExpandedTools(nil, nil); len(got) != 0 {
+		t.Fatalf("no tools: %q", got)
+	}
+}
@@ -137,6 +137,9 @@ func LintSkillMD(content string) *LintReport {
 		})
 	}
 
+	// Validate allowed-tools grants
+	r.Results = append(r.Results, LintAllowedTools(fm.AllowedTools)...)
+
 	// Warn if no "Use when:" trigger section
 	if !strings.Contains(body, "Use when:") && !strings.Contains(body, "use when:") {
 		r.Results = append(r.Results, LintResult{
@@ -193,6 +196,16 @@ func LintSkillDir(dirPath string) (*LintReport, error) {
 		})
 	}
 
+	findings, err := ScanDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range findings {
+		if !f.Suppressed {
+			report.Results = append(report.Results, f.LintResult())
+		}
+	}
+
 	return report, nil
 }
 
@@ -0,0 +1,61 @@
+package skill
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions orders two "X.Y.Z[-pre]" versions, returning -1, 0 or 1.
+// Missing components count as 0, a pre-release sorts before its release,
+// and non-numeric components compare as strings.
+func CompareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+
+	as, bs := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if c := compareComponent(x, y); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareComponent(aPre, bPre)
+}
+
+func compareComponent(x, y string) int {
+	xn, xErr := strconv.Atoi(orZero(x))
+	yn, yErr := strconv.Atoi(orZero(y))
+	if xErr == nil && yErr == nil {
+		switch {
+		case xn < yn:
+			return -1
+		case xn > yn:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(x, y)
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
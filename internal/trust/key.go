@@ -0,0 +1,133 @@
+// Package trust signs skills with ed25519 keys and verifies those signatures
+// against a store of trusted public keys.
+//
+// A signature covers the file-hash manifest of a skill directory (every
+// non-hidden file's path and SHA-256, see Payload) and is stored next to
+// SKILL.md as SKILL.sig, so it travels with the skill through git, registry
+// archives and aisk pack packages. Keys and signatures are one-line text
+// files in the style of minisign and SSH keys:
+//
+//	aisk-ed25519 <base64 public key> <name>           (name.pub)
+//	aisk-ed25519-secret <key id> <base64 seed>        (name.key)
+//	aisk-ed25519 <key id> <base64 signature>          (SKILL.sig)
+package trust
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	keyAlgorithm    = "aisk-ed25519"
+	secretAlgorithm = "aisk-ed25519-secret"
+)
+
+// Key is a public key that can verify signatures.
+type Key struct {
+	Name   string
+	ID     string // KeyID of Public
+	Public ed25519.PublicKey
+}
+
+// PrivateKey signs skills.
+type PrivateKey struct {
+	Name    string
+	Private ed25519.PrivateKey
+}
+
+// KeyID returns the identifier signatures name their key by: the first 8
+// bytes of the key's SHA-256, in hex.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// GenerateKey creates a new key pair called name.
+func GenerateKey(name string) (*PrivateKey, error) {
+	if strings.ContainsAny(name, " \t\r\n") || name == "" {
+		return nil, fmt.Errorf("key name %q must be a single word", name)
+	}
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{Name: name, Private: priv}, nil
+}
+
+// Public returns the public half of k.
+func (k *PrivateKey) Public() Key {
+	pub := k.Private.Public().(ed25519.PublicKey)
+	return Key{Name: k.Name, ID: KeyID(pub), Public: pub}
+}
+
+// Marshal returns k in the name.pub format.
+func (k Key) Marshal() []byte {
+	return []byte(fmt.Sprintf("%s %s %s\n", keyAlgorithm, base64.StdEncoding.EncodeToString(k.Public), k.Name))
+}
+
+// Marshal returns k in the name.key format.
+func (k *PrivateKey) Marshal() []byte {
+	return []byte(fmt.Sprintf("untrusted comment: aisk secret key %s\n%s %s %s\n",
+		k.Name, secretAlgorithm, k.Public().ID, base64.StdEncoding.EncodeToString(k.Private.Seed())))
+}
+
+// ParsePublicKey reads a public key in the name.pub format. A key without a
+// name comment is called fallbackName.
+func ParsePublicKey(data []byte, fallbackName string) (Key, error) {
+	fields := strings.Fields(firstLine(data))
+	if len(fields) < 2 || fields[0] != keyAlgorithm {
+		return Key{}, fmt.Errorf("not an %s public key", keyAlgorithm)
+	}
+	raw, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return Key{}, fmt.Errorf("malformed %s public key", keyAlgorithm)
+	}
+	name := fallbackName
+	if len(fields) > 2 {
+		name = fields[2]
+	}
+	pub := ed25519.PublicKey(raw)
+	return Key{Name: name, ID: KeyID(pub), Public: pub}, nil
+}
+
+// ParsePrivateKey reads a private key in the name.key format.
+func ParsePrivateKey(data []byte, fallbackName string) (*PrivateKey, error) {
+	name := fallbackName
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "untrusted comment: aisk secret key "); ok {
+			name = strings.TrimSpace(rest)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != secretAlgorithm {
+			continue
+		}
+		seed, err := base64.StdEncoding.DecodeString(fields[2])
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("malformed %s key", secretAlgorithm)
+		}
+		k := &PrivateKey{Name: name, Private: ed25519.NewKeyFromSeed(seed)}
+		if k.Public().ID != fields[1] {
+			return nil, fmt.Errorf("key id %s does not match the key", fields[1])
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("not an %s key", secretAlgorithm)
+}
+
+// firstLine returns the first line of data that is not blank or a comment.
+func firstLine(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "untrusted comment:") {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package trust
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yorch/aisk/internal/fsutil"
+	"github.com/yorch/aisk/internal/skill"
+)
+
+// SignatureFile is the detached signature stored in a signed skill directory.
+const SignatureFile = "SKILL.sig"
+
+const payloadHeader = "aisk-signature-v1\n"
+
+// Signature is a parsed SignatureFile.
+type Signature struct {
+	KeyID string
+	Sig   []byte
+}
+
+// Marshal returns sig in the SignatureFile format, with signer named in the
+// leading comment.
+func (sig Signature) Marshal(signer string) []byte {
+	return []byte(fmt.Sprintf("untrusted comment: signed by %s with aisk sign\n%s %s %s\n",
+		signer, keyAlgorithm, sig.KeyID, base64.StdEncoding.EncodeToString(sig.Sig)))
+}
+
+// ParseSignature reads a SignatureFile.
+func ParseSignature(data []byte) (Signature, error) {
+	fields := strings.Fields(firstLine(data))
+	if len(fields) != 3 || fields[0] != keyAlgorithm {
+		return Signature{}, fmt.Errorf("not an %s signature", keyAlgorithm)
+	}
+	raw, err := base64.StdEncoding.DecodeString(fields[2])
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return Signature{}, fmt.Errorf("malformed %s signature", keyAlgorithm)
+	}
+	return Signature{KeyID: fields[1], Sig: raw}, nil
+}
+
+// Payload returns the bytes a signature of the skill directory dir covers: a
+// header line, then "<sha256>  <path>" for every file skill.FileDigests
+// lists, except the SignatureFile itself. That is every file an install
+// copies or links, hidden ones included; a skill containing a symlink
+// cannot be signed.
+func Payload(dir string) ([]byte, error) {
+	files, err := skill.FileDigests(dir)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString(payloadHeader)
+	for _, f := range files {
+		if f.Path == SignatureFile {
+			continue
+		}
+		fmt.Fprintf(&b, "%s  %s\n", f.SHA256, f.Path)
+	}
+	return []byte(b.String()), nil
+}
+
+// Sign signs the skill directory dir with k and writes dir/SKILL.sig.
+func Sign(dir string, k *PrivateKey) (Signature, error) {
+	payload, err := Payload(dir)
+	if err != nil {
+		return Signature{}, err
+	}
+	sig := Signature{KeyID: k.Public().ID, Sig: ed25519.Sign(k.Private, payload)}
+	if err := fsutil.WriteFile(filepath.Join(dir, SignatureFile), sig.Marshal(k.Name), 0o644); err != nil {
+		return Signature{}, err
+	}
+	return sig, nil
+}
+
+// Status is the outcome of verifying a skill.
+type Status int
+
+const (
+	Unsigned   Status = iota // no SignatureFile
+	Verified                 // signed by a trusted key, contents unchanged
+	UnknownKey               // well-formed signature by a key not in the store
+	Invalid                  // malformed, or does not match the contents
+)
+
+func (s Status) String() string {
+	switch s {
+	case Verified:
+		return "verified"
+	case UnknownKey:
+		return "unknown-key"
+	case Invalid:
+		return "invalid"
+	default:
+		return "unsigned"
+	}
+}
+
+// Result reports how a skill's signature checked out.
+type Result struct {
+	Status Status
+	KeyID  string // signing key, when the signature could be parsed
+	Signer string // trusted name of the key, when Verified
+	Err    error  // why the signature is Invalid
+}
+
+// Verify checks the signature of the skill directory dir against the keys in
+// store. Only I/O errors reading the skill are returned as an error; a bad
+// signature is reported in the Result.
+func Verify(dir string, store *Store) (Result, error) {
+	data, err := os.ReadFile(filepath.Join(dir, SignatureFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return Result{Status: Unsigned}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	sig, err := ParseSignature(data)
+	if err != nil {
+		return Result{Status: Invalid, Err: err}, nil
+	}
+	k, ok := store.Lookup(sig.KeyID)
+	if !ok {
+		return Result{Status: UnknownKey, KeyID: sig.KeyID}, nil
+	}
+	payload, err := Payload(dir)
+	if errors.Is(err, skill.ErrNotRegular) {
+		return Result{Status: Invalid, KeyID: sig.KeyID, Err: err}, nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if !ed25519.Verify(k.Public, payload, sig.Sig) {
+		return Result{Status: Invalid, KeyID: sig.KeyID, Err: errors.New("signature does not match the skill's files")}, nil
+	}
+	return Result{Status: Verified, KeyID: sig.KeyID, Signer: k.Name}, nil
+}
@@ -0,0 +1,107 @@
+package trust
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yorch/aisk/internal/fsutil"
+)
+
+// Store is the set of trusted public keys, one name.pub file each in a
+// directory (~/.aisk/trust/).
+type Store struct {
+	Dir  string
+	keys []Key
+}
+
+// LoadStore reads the trusted keys in dir. A missing directory is an empty
+// store.
+func LoadStore(dir string) (*Store, error) {
+	s := &Store{Dir: dir}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".pub" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		k, err := ParsePublicKey(data, strings.TrimSuffix(e.Name(), ".pub"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, e.Name()), err)
+		}
+		// The file name, not the comment inside, is what add and remove use.
+		k.Name = strings.TrimSuffix(e.Name(), ".pub")
+		s.keys = append(s.keys, k)
+	}
+	sort.Slice(s.keys, func(i, j int) bool { return s.keys[i].Name < s.keys[j].Name })
+	return s, nil
+}
+
+// Keys returns the trusted keys sorted by name.
+func (s *Store) Keys() []Key {
+	return s.keys
+}
+
+// Lookup returns the trusted key with the given key ID.
+func (s *Store) Lookup(id string) (Key, bool) {
+	for _, k := range s.keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Add trusts k under k.Name. Adding a key that is already trusted under
+// another name fails, as does reusing a name for a different key.
+func (s *Store) Add(k Key) error {
+	if k.Name == "" || strings.ContainsAny(k.Name, `/\ `) || strings.HasPrefix(k.Name, ".") {
+		return fmt.Errorf("invalid key name %q", k.Name)
+	}
+	for _, existing := range s.keys {
+		if existing.ID == k.ID {
+			if existing.Name == k.Name {
+				return nil
+			}
+			return fmt.Errorf("key %s is already trusted as %q", k.ID, existing.Name)
+		}
+		if existing.Name == k.Name {
+			return fmt.Errorf("a different key is already trusted as %q", k.Name)
+		}
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	if err := fsutil.WriteFile(filepath.Join(s.Dir, k.Name+".pub"), k.Marshal(), 0o644); err != nil {
+		return err
+	}
+	s.keys = append(s.keys, k)
+	sort.Slice(s.keys, func(i, j int) bool { return s.keys[i].Name < s.keys[j].Name })
+	return nil
+}
+
+// Remove stops trusting the key with the given name or key ID.
+func (s *Store) Remove(nameOrID string) (Key, error) {
+	for i, k := range s.keys {
+		if k.Name != nameOrID && k.ID != nameOrID {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.Dir, k.Name+".pub")); err != nil {
+			return Key{}, err
+		}
+		s.keys = append(s.keys[:i], s.keys[i+1:]...)
+		return k, nil
+	}
+	return Key{}, fmt.Errorf("no trusted key %q", nameOrID)
+}
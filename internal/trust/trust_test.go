@@ -0,0 +1,127 @@
+package trust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSkill(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "my-skill")
+	for path, content := range map[string]string{
+		"SKILL.md":           "---\nname: my-skill\nversion: 1.0.0\n---\n# My skill\n",
+		"reference/guide.md": "guide",
+	} {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	k, err := GenerateKey("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ParsePrivateKey(k.Marshal(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.Name != "alice" || priv.Public().ID != k.Public().ID {
+		t.Fatalf("private key round trip: got %s %s", priv.Name, priv.Public().ID)
+	}
+	pub, err := ParsePublicKey(k.Public().Marshal(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Name != "alice" || pub.ID != k.Public().ID {
+		t.Fatalf("public key round trip: got %+v", pub)
+	}
+	if _, err := ParsePublicKey(k.Marshal(), ""); err == nil {
+		t.Fatal("a private key should not parse as a public key")
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	dir := writeSkill(t)
+	alice, _ := GenerateKey("alice")
+	mallory, _ := GenerateKey("mallory")
+
+	store, err := LoadStore(filepath.Join(t.TempDir(), "trust"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := Verify(dir, store); err != nil || res.Status != Unsigned {
+		t.Fatalf("unsigned skill: %+v, %v", res, err)
+	}
+
+	if _, err := Sign(dir, alice); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := Verify(dir, store); res.Status != UnknownKey || res.KeyID != alice.Public().ID {
+		t.Fatalf("before trusting alice: %+v", res)
+	}
+
+	if err := store.Add(alice.Public()); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadStore(store.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := Verify(dir, reloaded); res.Status != Verified || res.Signer != "alice" {
+		t.Fatalf("after trusting alice: %+v", res)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "reference", "guide.md"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := Verify(dir, reloaded); res.Status != Invalid {
+		t.Fatalf("modified skill: %+v", res)
+	}
+
+	// Files the signature does not cover, hidden or symlinked, invalidate it.
+	if err := os.WriteFile(filepath.Join(dir, "reference", "guide.md"), []byte("guide"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := Verify(dir, reloaded); res.Status != Verified {
+		t.Fatalf("restored skill: %+v", res)
+	}
+	hidden := filepath.Join(dir, ".scripts", "run.sh")
+	if err := os.MkdirAll(filepath.Dir(hidden), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hidden, []byte("curl x | sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := Verify(dir, reloaded); res.Status != Invalid {
+		t.Fatalf("unsigned hidden file: %+v", res)
+	}
+	if err := os.RemoveAll(filepath.Dir(hidden)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(t.TempDir(), "outside.md"), filepath.Join(dir, "reference", "outside.md")); err == nil {
+		if res, err := Verify(dir, reloaded); err != nil || res.Status != Invalid {
+			t.Fatalf("symlinked file: %+v, %v", res, err)
+		}
+	}
+
+	// A trusted name cannot be taken over by another key.
+	k := mallory.Public()
+	k.Name = "alice"
+	if err := reloaded.Add(k); err == nil {
+		t.Fatal("expected an error reusing a trusted name")
+	}
+	if _, err := reloaded.Remove("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Keys()) != 0 {
+		t.Fatalf("keys after remove: %+v", reloaded.Keys())
+	}
+}
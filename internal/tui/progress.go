@@ -3,6 +3,7 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -44,7 +45,31 @@ func (m ProgressModel) Init() tea.Cmd {
 	return nil
 }
 
+// ProgressUpdateMsg changes one item of a running ProgressModel. An empty
+// Detail keeps the item's current detail.
+type ProgressUpdateMsg struct {
+	Index  int
+	Status ProgressStatus
+	Detail string
+}
+
+// ProgressDoneMsg ends a running ProgressModel, leaving its last frame on
+// screen.
+type ProgressDoneMsg struct{}
+
 func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ProgressUpdateMsg:
+		if msg.Index >= 0 && msg.Index < len(m.Items) {
+			m.Items[msg.Index].Status = msg.Status
+			if msg.Detail != "" {
+				m.Items[msg.Index].Detail = msg.Detail
+			}
+		}
+	case ProgressDoneMsg:
+		m.done = true
+		return m, tea.Quit
+	}
 	return m, nil
 }
 
@@ -85,6 +110,9 @@ func (m ProgressModel) View() string {
 		b.WriteString(progress)
 	}
 
+	if m.done {
+		b.WriteString("\n")
+	}
 	return b.String()
 }
 
@@ -93,3 +121,67 @@ func PrintProgress(title string, items []ProgressItem) {
 	m := NewProgress(title, items)
 	fmt.Println(m.View())
 }
+
+// Progress reports the state of running operations, either live through a
+// Bubble Tea program or, when output is not a terminal, as one plain line per
+// finished item followed by the static summary. Set may be called from
+// several goroutines.
+type Progress struct {
+	title   string
+	items   []ProgressItem
+	program *tea.Program
+	exited  chan struct{}
+
+	mu       sync.Mutex
+	finished int
+}
+
+// StartProgress starts reporting progress for items. With live set the
+// Bubble Tea view is redrawn as items change; call Stop when all work is done.
+func StartProgress(title string, items []ProgressItem, live bool) *Progress {
+	p := &Progress{title: title, items: items}
+	if !live {
+		return p
+	}
+	model := NewProgress(title, append([]ProgressItem(nil), items...))
+	p.program = tea.NewProgram(model, tea.WithInput(nil), tea.WithoutSignalHandler())
+	p.exited = make(chan struct{})
+	go func() {
+		defer close(p.exited)
+		_, _ = p.program.Run()
+	}()
+	return p
+}
+
+// Set changes the status (and, if not empty, the detail) of item i.
+func (p *Progress) Set(i int, status ProgressStatus, detail string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.items[i].Status = status
+	if detail != "" {
+		p.items[i].Detail = detail
+	}
+	if p.program != nil {
+		p.program.Send(ProgressUpdateMsg{Index: i, Status: status, Detail: detail})
+		return
+	}
+	if status == StatusDone || status == StatusError {
+		p.finished++
+		word := "done"
+		if status == StatusError {
+			word = "error"
+		}
+		fmt.Printf("  [%d/%d] %s: %s  %s\n", p.finished, len(p.items), p.items[i].Label, word, p.items[i].Detail)
+	}
+}
+
+// Stop ends the live view, or prints the static summary in plain mode.
+func (p *Progress) Stop() {
+	if p.program != nil {
+		p.program.Send(ProgressDoneMsg{})
+		<-p.exited
+		return
+	}
+	fmt.Println()
+	PrintProgress(p.title, p.items)
+}
@@ -18,13 +18,13 @@ type StatusEntry struct {
 	Installations map[client.ClientID]string // clientID -> installed version
 }
 
-// BuildStatusEntries creates status entries from manifest data.
-func BuildStatusEntries(m *manifest.Manifest) []StatusEntry {
+// BuildStatusEntries creates status entries from manifest installations.
+func BuildStatusEntries(installations []manifest.Installation) []StatusEntry {
 	// Group by skill name
 	skillMap := make(map[string]*StatusEntry)
 	var order []string
 
-	for _, inst := range m.Installations {
+	for _, inst := range installations {
 		entry, ok := skillMap[inst.SkillName]
 		if !ok {
 			entry = &StatusEntry{
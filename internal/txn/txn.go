@@ -0,0 +1,258 @@
+// Package txn journals filesystem changes so a multi-step operation can be
+// rolled back as a unit, including after the process dies mid-way.
+//
+// Before a path is modified it is tracked: its current state (missing, file,
+// directory tree or symlink) is copied into the transaction directory and
+// recorded in journal.json. Rollback restores every tracked path; Commit
+// discards the backups. A journal left behind by a crashed process is found
+// by Recover on the next run.
+package txn
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yorch/aisk/internal/fsutil"
+)
+
+// Journal states.
+const (
+	StateApplying  = "applying"  // changes may be partially applied; revert on recovery
+	StateCommitted = "committed" // all changes applied; only cleanup remains
+)
+
+// Entry kinds describe what existed at a path before the transaction.
+const (
+	KindMissing = "missing"
+	KindFile    = "file"
+	KindDir     = "dir"
+	KindSymlink = "symlink"
+)
+
+const journalFile = "journal.json"
+
+// Journal is the on-disk record of a transaction.
+type Journal struct {
+	ID        string    `json:"id"`
+	Command   string    `json:"command"`
+	PID       int       `json:"pid"`
+	Host      string    `json:"host,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	State     string    `json:"state"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Entry is the saved prior state of one path.
+type Entry struct {
+	Path   string      `json:"path"`
+	Kind   string      `json:"kind"`
+	Backup string      `json:"backup,omitempty"` // relative to the transaction dir
+	Link   string      `json:"link,omitempty"`   // symlink target
+	Mode   os.FileMode `json:"mode,omitempty"`
+}
+
+// Tx is an open transaction.
+type Tx struct {
+	dir     string
+	journal Journal
+}
+
+// Begin starts a transaction journaled under root.
+func Begin(root, command string) (*Tx, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(root, time.Now().UTC().Format("20060102T150405")+"-")
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	t := &Tx{
+		dir: dir,
+		journal: Journal{
+			ID:        filepath.Base(dir),
+			Command:   command,
+			PID:       os.Getpid(),
+			Host:      host,
+			StartedAt: time.Now(),
+			State:     StateApplying,
+		},
+	}
+	if err := t.save(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return t, nil
+}
+
+// ID returns the transaction identifier.
+func (t *Tx) ID() string { return t.journal.ID }
+
+// Track saves the current state of path so Rollback can restore it. It must
+// be called before the path is modified; tracking a path twice is a no-op.
+func (t *Tx) Track(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, e := range t.journal.Entries {
+		if e.Path == path {
+			return nil
+		}
+	}
+
+	entry := Entry{Path: path}
+	info, err := os.Lstat(path)
+	switch {
+	case os.IsNotExist(err):
+		entry.Kind = KindMissing
+	case err != nil:
+		return err
+	case info.Mode()&os.ModeSymlink != 0:
+		entry.Kind = KindSymlink
+		if entry.Link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	default:
+		entry.Backup = strconv.Itoa(len(t.journal.Entries))
+		entry.Mode = info.Mode().Perm()
+		entry.Kind = KindFile
+		if info.IsDir() {
+			entry.Kind = KindDir
+		}
+		if err := fsutil.CopyTree(path, filepath.Join(t.dir, entry.Backup)); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+
+	t.journal.Entries = append(t.journal.Entries, entry)
+	return t.save()
+}
+
+// Commit marks the transaction complete and removes its journal.
+func (t *Tx) Commit() error {
+	t.journal.State = StateCommitted
+	if err := t.save(); err != nil {
+		return err
+	}
+	return os.RemoveAll(t.dir)
+}
+
+// Rollback restores every tracked path, newest first, and removes the journal.
+func (t *Tx) Rollback() error {
+	if err := restore(t.dir, t.journal); err != nil {
+		return err
+	}
+	return os.RemoveAll(t.dir)
+}
+
+func (t *Tx) save() error {
+	data, err := json.MarshalIndent(t.journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFile(filepath.Join(t.dir, journalFile), data, 0o644)
+}
+
+// Recovered describes a transaction completed or reverted by Recover.
+type Recovered struct {
+	Journal
+	Action string // "reverted" or "finished"
+}
+
+// Recover resolves transactions left under root by processes that exited
+// before committing or rolling back: applying transactions are reverted and
+// committed ones have their leftovers removed. Callers must hold the lock
+// that serializes transactional commands so live transactions are not touched.
+func Recover(root string) ([]Recovered, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var recovered []Recovered
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		data, err := os.ReadFile(filepath.Join(dir, journalFile))
+		if err != nil {
+			// Begin died before writing the journal: nothing was changed.
+			if os.IsNotExist(err) {
+				os.RemoveAll(dir)
+				continue
+			}
+			return recovered, err
+		}
+		var j Journal
+		if err := json.Unmarshal(data, &j); err != nil {
+			return recovered, fmt.Errorf("reading journal %s: %w", e.Name(), err)
+		}
+
+		action := "finished"
+		if j.State != StateCommitted {
+			if err := restore(dir, j); err != nil {
+				return recovered, fmt.Errorf("reverting transaction %s: %w", j.ID, err)
+			}
+			action = "reverted"
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return recovered, err
+		}
+		recovered = append(recovered, Recovered{Journal: j, Action: action})
+	}
+	return recovered, nil
+}
+
+func restore(dir string, j Journal) error {
+	for i := len(j.Entries) - 1; i >= 0; i-- {
+		if err := restoreEntry(dir, j.Entries[i]); err != nil {
+			return fmt.Errorf("restoring %s: %w", j.Entries[i].Path, err)
+		}
+	}
+	return nil
+}
+
+func restoreEntry(dir string, e Entry) error {
+	if e.Kind == KindMissing {
+		return os.RemoveAll(e.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Path), 0o755); err != nil {
+		return err
+	}
+
+	staged, err := fsutil.TempSibling(e.Path)
+	if err != nil {
+		return err
+	}
+	switch e.Kind {
+	case KindSymlink:
+		err = os.Symlink(e.Link, staged)
+	case KindFile, KindDir:
+		err = fsutil.CopyTree(filepath.Join(dir, e.Backup), staged)
+		if err == nil {
+			err = os.Chmod(staged, e.Mode)
+		}
+	default:
+		err = fmt.Errorf("unknown entry kind %q", e.Kind)
+	}
+	if err != nil {
+		os.RemoveAll(staged)
+		return err
+	}
+	if err := fsutil.Swap(staged, e.Path); err != nil {
+		os.RemoveAll(staged)
+		return err
+	}
+	return nil
+}
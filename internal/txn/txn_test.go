@@ -0,0 +1,141 @@
+package txn
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRollback_RestoresPriorState(t *testing.T) {
+	work := t.TempDir()
+	file := filepath.Join(work, "AGENTS.md")
+	os.WriteFile(file, []byte("original"), 0o600)
+	dir := filepath.Join(work, "skills", "code-review")
+	os.MkdirAll(filepath.Join(dir, "reference"), 0o755)
+	os.WriteFile(filepath.Join(dir, "reference", "a.md"), []byte("ref"), 0o644)
+	created := filepath.Join(work, "rules", "new.mdc")
+
+	tx, err := Begin(filepath.Join(work, "txn"), "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{file, dir, created} {
+		if err := tx.Track(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.WriteFile(file, []byte("changed"), 0o644)
+	os.RemoveAll(dir)
+	os.MkdirAll(filepath.Dir(created), 0o755)
+	os.WriteFile(created, []byte("new"), 0o644)
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := os.ReadFile(file); string(data) != "original" {
+		t.Errorf("file content = %q", data)
+	}
+	if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode not restored: %v %v", info.Mode(), err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "reference", "a.md")); string(data) != "ref" {
+		t.Errorf("directory not restored, got %q", data)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("created file should be removed, stat err = %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(work, "txn")); len(entries) != 0 {
+		t.Errorf("journal should be removed after rollback")
+	}
+}
+
+func TestRollback_RestoresSymlink(t *testing.T) {
+	work := t.TempDir()
+	link := filepath.Join(work, "skill")
+	if err := os.Symlink(work, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	tx, err := Begin(filepath.Join(work, "txn"), "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Track(link); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(link)
+	os.MkdirAll(link, 0o755)
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(link); err != nil || target != work {
+		t.Fatalf("symlink not restored: %q %v", target, err)
+	}
+}
+
+func TestRecover_RevertsUncommitted(t *testing.T) {
+	work := t.TempDir()
+	root := filepath.Join(work, "txn")
+	file := filepath.Join(work, "manifest.json")
+	os.WriteFile(file, []byte("{}"), 0o644)
+
+	tx, err := Begin(root, "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Track(file); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(file, []byte(`{"broken":`), 0o644)
+	// Simulate process death: neither Commit nor Rollback runs.
+
+	recovered, err := Recover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != 1 || recovered[0].Action != "reverted" || recovered[0].Command != "install" {
+		t.Fatalf("unexpected recovery result: %+v", recovered)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "{}" {
+		t.Errorf("file not reverted: %q", data)
+	}
+
+	recovered, err = Recover(root)
+	if err != nil || len(recovered) != 0 {
+		t.Fatalf("second recovery should be a no-op, got %+v %v", recovered, err)
+	}
+}
+
+func TestRecover_FinishesCommitted(t *testing.T) {
+	work := t.TempDir()
+	root := filepath.Join(work, "txn")
+	file := filepath.Join(work, "f")
+
+	tx, err := Begin(root, "install")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Track(file); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(file, []byte("applied"), 0o644)
+	// Mark committed but leave the journal, as if cleanup was interrupted.
+	tx.journal.State = StateCommitted
+	if err := tx.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, err := Recover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != 1 || recovered[0].Action != "finished" {
+		t.Fatalf("unexpected recovery result: %+v", recovered)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "applied" {
+		t.Errorf("committed change should be kept, got %q", data)
+	}
+}
@@ -0,0 +1,102 @@
+// Package watch reports file changes under directory trees.
+package watch
+
+import (
+	"time"
+)
+
+// Watcher delivers changed file paths under its root directories.
+// Platform-specific backends live in watch_linux.go (inotify) and
+// watch_poll.go (mtime polling).
+type Watcher struct {
+	events chan string
+	errors chan error
+	done   chan struct{}
+	closer func() error
+}
+
+// New starts watching the given roots recursively. Roots that do not exist
+// are ignored until they are created by a change in a watched parent.
+func New(roots ...string) (*Watcher, error) {
+	w := &Watcher{
+		events: make(chan string, 64),
+		errors: make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+	if err := w.start(roots); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Events returns the channel of changed paths.
+func (w *Watcher) Events() <-chan string { return w.events }
+
+// Errors returns the channel of backend errors.
+func (w *Watcher) Errors() <-chan error { return w.errors }
+
+// Close stops the watcher.
+func (w *Watcher) Close() error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+	}
+	close(w.done)
+	if w.closer != nil {
+		return w.closer()
+	}
+	return nil
+}
+
+func (w *Watcher) emit(path string) {
+	select {
+	case w.events <- path:
+	case <-w.done:
+	}
+}
+
+func (w *Watcher) fail(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+// Debounce groups paths that arrive within quiet of each other and emits each
+// group once no new path has arrived for that long. The output channel is
+// closed when in is closed.
+func Debounce(in <-chan string, quiet time.Duration) <-chan []string {
+	out := make(chan []string)
+	go func() {
+		defer close(out)
+		var pending []string
+		seen := make(map[string]bool)
+		timer := time.NewTimer(quiet)
+		timer.Stop()
+		for {
+			select {
+			case p, ok := <-in:
+				if !ok {
+					if len(pending) > 0 {
+						out <- pending
+					}
+					return
+				}
+				if !seen[p] {
+					seen[p] = true
+					pending = append(pending, p)
+				}
+				timer.Reset(quiet)
+			case <-timer.C:
+				if len(pending) == 0 {
+					continue
+				}
+				out <- pending
+				pending = nil
+				seen = make(map[string]bool)
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,116 @@
+//go:build linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ATTRIB
+
+type inotify struct {
+	mu   sync.Mutex
+	fd   int
+	file *os.File
+	dirs map[int32]string
+}
+
+func (w *Watcher) start(roots []string) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return os.NewSyscallError("inotify_init1", err)
+	}
+	in := &inotify{
+		fd:   fd,
+		file: os.NewFile(uintptr(fd), "inotify"),
+		dirs: make(map[int32]string),
+	}
+	for _, root := range roots {
+		if err := in.addTree(root); err != nil {
+			in.file.Close()
+			return err
+		}
+	}
+	w.closer = in.file.Close
+	go w.readInotify(in)
+	return nil
+}
+
+// addTree registers root and every directory beneath it.
+func (in *inotify) addTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		wd, err := syscall.InotifyAddWatch(in.fd, path, inotifyMask)
+		if err != nil {
+			return os.NewSyscallError("inotify_add_watch", err)
+		}
+		in.mu.Lock()
+		in.dirs[int32(wd)] = path
+		in.mu.Unlock()
+		return nil
+	})
+}
+
+func (w *Watcher) readInotify(in *inotify) {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := in.file.Read(buf)
+		if err != nil {
+			select {
+			case <-w.done:
+			default:
+				w.fail(err)
+			}
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(ev.Len)
+			offset = nameEnd
+
+			in.mu.Lock()
+			dir, ok := in.dirs[ev.Wd]
+			if ev.Mask&syscall.IN_IGNORED != 0 {
+				delete(in.dirs, ev.Wd)
+			}
+			in.mu.Unlock()
+			if !ok {
+				continue
+			}
+
+			path := dir
+			if ev.Len > 0 {
+				name := string(buf[nameStart:nameEnd])
+				for i := 0; i < len(name); i++ {
+					if name[i] == 0 {
+						name = name[:i]
+						break
+					}
+				}
+				path = filepath.Join(dir, name)
+			}
+
+			if ev.Mask&syscall.IN_ISDIR != 0 && ev.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+				if err := in.addTree(path); err != nil {
+					w.fail(err)
+				}
+			}
+			w.emit(path)
+		}
+	}
+}
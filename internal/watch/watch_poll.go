@@ -0,0 +1,59 @@
+//go:build !linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is how often the polling backend rescans watched trees.
+var pollInterval = 300 * time.Millisecond
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (w *Watcher) start(roots []string) error {
+	prev := snapshot(roots)
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+			}
+			cur := snapshot(roots)
+			for path, st := range cur {
+				if old, ok := prev[path]; !ok || !old.modTime.Equal(st.modTime) || old.size != st.size {
+					w.emit(path)
+				}
+			}
+			for path := range prev {
+				if _, ok := cur[path]; !ok {
+					w.emit(path)
+				}
+			}
+			prev = cur
+		}
+	}()
+	return nil
+}
+
+func snapshot(roots []string) map[string]fileState {
+	files := make(map[string]fileState)
+	for _, root := range roots {
+		_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+	}
+	return files
+}
@@ -0,0 +1,101 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitForPath(t *testing.T, w *Watcher, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case p := <-w.Events():
+			if p == want {
+				return
+			}
+		case err := <-w.Errors():
+			t.Fatalf("watcher error: %v", err)
+		case <-timeout:
+			t.Fatalf("timed out waiting for event on %s", want)
+		}
+	}
+}
+
+func TestWatcher_FileWrite(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	target := filepath.Join(dir, "SKILL.md")
+	if err := os.WriteFile(target, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForPath(t, w, target)
+}
+
+func TestWatcher_NewSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	sub := filepath.Join(dir, "reference")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Give the backend a moment to register the new directory.
+	time.Sleep(50 * time.Millisecond)
+
+	target := filepath.Join(sub, "guide.md")
+	if err := os.WriteFile(target, []byte("ref"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitForPath(t, w, target)
+}
+
+func TestWatcher_CloseIsIdempotent(t *testing.T) {
+	w, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("first close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+func TestDebounce_GroupsBursts(t *testing.T) {
+	in := make(chan string)
+	out := Debounce(in, 30*time.Millisecond)
+
+	go func() {
+		in <- "a"
+		in <- "b"
+		in <- "a"
+		time.Sleep(100 * time.Millisecond)
+		in <- "c"
+		close(in)
+	}()
+
+	first := <-out
+	if len(first) != 2 || first[0] != "a" || first[1] != "b" {
+		t.Fatalf("first batch = %v, want [a b]", first)
+	}
+	second := <-out
+	if len(second) != 1 || second[0] != "c" {
+		t.Fatalf("second batch = %v, want [c]", second)
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("expected output channel to close")
+	}
+}